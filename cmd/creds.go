@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/samsarahq/go/oops"
+	"github.com/santiago-labs/telophasecli/cmd/runner"
+	"github.com/santiago-labs/telophasecli/lib/awsorgs"
+	"github.com/santiago-labs/telophasecli/lib/awssess"
+	"github.com/santiago-labs/telophasecli/resource"
+	"github.com/santiago-labs/telophasecli/resourceoperation"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	credsFormatEnv               = "env"
+	credsFormatCredentialProcess = "credential-process"
+)
+
+var (
+	credsFormat string
+	credsStack  string
+)
+
+func init() {
+	rootCmd.AddCommand(credsCmd)
+	credsCmd.Flags().StringVar(&orgFile, "org", "organization.yml", "Path to the organization.yml file")
+	credsCmd.Flags().StringVar(&credsFormat, "format", credsFormatEnv, "Output format. Options: env, credential-process")
+	credsCmd.Flags().StringVar(&credsStack, "stack", "", "Assume the role of the account's stack with this name instead of the account's role")
+}
+
+var credsCmd = &cobra.Command{
+	Use:   "creds <account name, ID or tag>",
+	Short: "creds - Print credentials for the role telophasecli assumes in an account.",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if credsFormat != credsFormatEnv && credsFormat != credsFormatCredentialProcess {
+			fmt.Fprintf(os.Stderr, "invalid format: %s\n", credsFormat)
+			os.Exit(1)
+		}
+
+		// Logs go to stderr so that stdout can be evaled or read by the AWS
+		// CLI.
+		creds, err := vendCreds(context.Background(), runner.NewSTDErr(), args[0])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, oops.Cause(err))
+			os.Exit(1)
+		}
+
+		if credsFormat == credsFormatCredentialProcess {
+			if err := printCredentialProcess(creds); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		fmt.Printf("export AWS_ACCESS_KEY_ID=%s\n", aws.StringValue(creds.AccessKeyId))
+		fmt.Printf("export AWS_SECRET_ACCESS_KEY=%s\n", aws.StringValue(creds.SecretAccessKey))
+		// Long-lived credentials have no session token.
+		if token := aws.StringValue(creds.SessionToken); token != "" {
+			fmt.Printf("export AWS_SESSION_TOKEN=%s\n", token)
+		}
+	},
+}
+
+func vendCreds(ctx context.Context, consoleUI runner.ConsoleUI, query string) (*sts.Credentials, error) {
+	orgClient := awsorgs.New(nil)
+	rootAWSOU, _, err := loadOrganization(ctx, consoleUI, orgClient)
+	if err != nil {
+		return nil, err
+	}
+
+	acct, err := resolveAccount(rootAWSOU, query)
+	if err != nil {
+		return nil, err
+	}
+
+	roleARN := acct.AssumeRoleARN()
+	if credsStack != "" {
+		stack, err := findStack(*acct, credsStack)
+		if err != nil {
+			return nil, err
+		}
+		roleARN = *stack.RoleARN(*acct)
+	}
+
+	creds, _, err := resourceoperation.AuthAWS(*acct, roleARN, consoleUI)
+	if err != nil {
+		return nil, err
+	}
+	if creds != nil {
+		return creds, nil
+	}
+
+	// AuthAWS uses the current credentials in the management account when the
+	// role can't be assumed. Printing them would hand out the caller's own,
+	// possibly long-lived, keys instead of a role session.
+	if os.Getenv("TELOPHASE_BYPASS_ASSUME_ROLE") == "" {
+		return nil, fmt.Errorf("can't assume role %s in the management account %s, use --stack with a stack whose AssumeRoleName exists in the management account", roleARN, acct.AccountName)
+	}
+
+	consoleUI.Print("TELOPHASE_BYPASS_ASSUME_ROLE is set, printing the current credentials", *acct)
+	sess, err := awssess.DefaultSession()
+	if err != nil {
+		return nil, err
+	}
+	value, err := sess.Config.Credentials.Get()
+	if err != nil {
+		return nil, oops.Wrapf(err, "current credentials")
+	}
+	return &sts.Credentials{
+		AccessKeyId:     aws.String(value.AccessKeyID),
+		SecretAccessKey: aws.String(value.SecretAccessKey),
+		SessionToken:    aws.String(value.SessionToken),
+	}, nil
+}
+
+// resolveAccount returns the account whose name or ID is query or, failing
+// that, the only account with the tag query.
+func resolveAccount(rootAWSOU *resource.OrganizationUnit, query string) (*resource.Account, error) {
+	var tagged []*resource.Account
+	for _, acct := range rootAWSOU.AllDescendentAccounts() {
+		if acct.AccountName == query || (acct.AccountID != "" && acct.AccountID == query) {
+			if !acct.IsProvisioned() {
+				return nil, fmt.Errorf("account %s has not been created yet", acct.AccountName)
+			}
+			return acct, nil
+		}
+		if acct.IsProvisioned() && contains(query, acct.AllTags()) {
+			tagged = append(tagged, acct)
+		}
+	}
+
+	switch len(tagged) {
+	case 0:
+		return nil, fmt.Errorf("no account named %s or with ID or tag %s in %s", query, query, orgFile)
+	case 1:
+		return tagged[0], nil
+	}
+
+	var names []string
+	for _, acct := range tagged {
+		names = append(names, accountName(*acct))
+	}
+	return nil, fmt.Errorf("tag %s matches %d accounts: %s", query, len(tagged), strings.Join(names, ", "))
+}
+
+func findStack(acct resource.Account, name string) (*resource.Stack, error) {
+	stacks, err := acct.AllBaselineStacks()
+	if err != nil {
+		return nil, err
+	}
+	for _, stack := range stacks {
+		if stack.HasName(name) {
+			return &stack, nil
+		}
+	}
+	return nil, fmt.Errorf("account %s has no stack named %s", acct.AccountName, name)
+}
+
+// credentialProcess is the output format of an AWS CLI credential_process.
+// https://docs.aws.amazon.com/cli/latest/userguide/cli-configure-sourcing-external.html
+type credentialProcess struct {
+	Version         int    `json:"Version"`
+	AccessKeyID     string `json:"AccessKeyId"`
+	SecretAccessKey string `json:"SecretAccessKey"`
+	SessionToken    string `json:"SessionToken,omitempty"`
+	Expiration      string `json:"Expiration,omitempty"`
+}
+
+func printCredentialProcess(creds *sts.Credentials) error {
+	output := credentialProcess{
+		Version:         1,
+		AccessKeyID:     aws.StringValue(creds.AccessKeyId),
+		SecretAccessKey: aws.StringValue(creds.SecretAccessKey),
+		SessionToken:    aws.StringValue(creds.SessionToken),
+	}
+	if creds.Expiration != nil {
+		output.Expiration = creds.Expiration.UTC().Format(time.RFC3339)
+	}
+
+	data, err := json.Marshal(output)
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	return nil
+}
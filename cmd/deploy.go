@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/santiago-labs/telophasecli/cmd/runner"
+	"github.com/santiago-labs/telophasecli/lib/lock"
 	"github.com/santiago-labs/telophasecli/resourceoperation"
 	"golang.org/x/sync/errgroup"
 
@@ -17,7 +18,16 @@ var (
 	tag                string
 	targets            string
 	stacks             string
+	accountFilter      string
 	allowDeleteAccount bool
+	pruneOUs           bool
+
+	// Saved plans
+	planOut  string
+	planFile string
+
+	// Run journal
+	resumeRunID string
 
 	// TUI
 	useTUI bool
@@ -30,7 +40,18 @@ func init() {
 	deployCmd.Flags().StringVar(&targets, "targets", "", "Filter resource types to deploy. Options: organization, scp, stacks")
 	deployCmd.Flags().StringVar(&orgFile, "org", "organization.yml", "Path to the organization.yml file")
 	deployCmd.Flags().BoolVar(&useTUI, "tui", false, "use the TUI for deploy")
+	deployCmd.Flags().IntVar(&parallelism, "parallelism", 10, "Maximum number of stack and Service Control Policy operations to run at the same time. 0 is unlimited")
+	deployCmd.Flags().IntVar(&regionParallelism, "region-parallelism", 0, "Maximum number of stack operations to run at the same time in each region. 0 is unlimited")
+	deployCmd.Flags().BoolVar(&failFast, "fail-fast", false, "Stop starting operations after the first operation fails")
+	deployCmd.Flags().BoolVar(&keepGoing, "keep-going", false, "Keep running the operations of other resources after an operation fails. This is the default")
+	deployCmd.Flags().DurationVar(&stackTimeout, "timeout", 0, "Maximum time each stack operation can run, e.g. 30m. A stack's Timeout overrides it. 0 is unlimited")
+	deployCmd.Flags().StringVar(&outputFormat, "output", outputText, "Output format. Options: text, json")
 	deployCmd.Flags().BoolVar(&allowDeleteAccount, "allow-account-delete", false, "Allow closing an AWS account")
+	deployCmd.Flags().BoolVar(&pruneOUs, "prune-ous", false, "Delete empty Organization Units that are not in organization.yml")
+	deployCmd.Flags().StringVar(&planFile, "plan", "", "Apply a plan saved with diff --out")
+	deployCmd.Flags().StringVar(&resumeRunID, "resume", "", "Resume the deploy with this run ID, skipping stacks that already succeeded with unchanged input")
+	deployCmd.Flags().StringVar(&lockTable, "lock-table", defaultLockTable(), "DynamoDB table to lock the AWS Organization in so that only one deploy or destroy runs at a time. Defaults to $TELOPHASE_LOCK_TABLE, no lock is taken if empty")
+	deployCmd.Flags().DurationVar(&lockTTL, "lock-ttl", lock.DefaultTTL, "How long the lock is kept if telophasecli stops without releasing it")
 }
 
 var deployCmd = &cobra.Command{
@@ -41,6 +62,31 @@ var deployCmd = &cobra.Command{
 		if err := validateTargets(); err != nil {
 			log.Fatal("error validating targets err:", err)
 		}
+		if err := validateOutputFormat(); err != nil {
+			log.Fatal("error validating output err:", err)
+		}
+		if err := validateParallelism(); err != nil {
+			log.Fatal("error validating parallelism err:", err)
+		}
+		if err := validateTimeout(); err != nil {
+			log.Fatal("error validating timeout err:", err)
+		}
+		if err := validateFailureMode(); err != nil {
+			log.Fatal("error validating failure mode err:", err)
+		}
+		if planFile != "" && resumeRunID != "" {
+			log.Fatal("--resume cannot be used with --plan")
+		}
+		if planFile != "" {
+			for _, flag := range []string{"stacks", "tag", "targets", "allow-account-delete", "prune-ous"} {
+				if cmd.Flags().Changed(flag) {
+					log.Fatalf("--%s cannot be used with --plan, the plan's filters are used", flag)
+				}
+			}
+		}
+		ctx, cancel := signalContext()
+		defer cancel()
+
 		var consoleUI runner.ConsoleUI
 		parsedTargets := filterEmptyStrings(strings.Split(targets, ","))
 		var g errgroup.Group
@@ -48,12 +94,12 @@ var deployCmd = &cobra.Command{
 		if useTUI {
 			consoleUI = runner.NewTUI()
 			g.Go(func() error {
-				return ProcessOrgEndToEnd(consoleUI, resourceoperation.Deploy, parsedTargets)
+				return ProcessOrgEndToEnd(ctx, consoleUI, resourceoperation.Deploy, parsedTargets)
 			})
 		} else {
-			consoleUI = runner.NewSTDOut()
-			if err := ProcessOrgEndToEnd(consoleUI, resourceoperation.Deploy, parsedTargets); err != nil {
-				fmt.Println(err)
+			consoleUI = newConsoleUI()
+			if err := ProcessOrgEndToEnd(ctx, consoleUI, resourceoperation.Deploy, parsedTargets); err != nil {
+				fmt.Fprintln(os.Stderr, err)
 				os.Exit(1)
 			}
 		}
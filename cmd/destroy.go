@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/samsarahq/go/oops"
+	"github.com/santiago-labs/telophasecli/cmd/runner"
+	"github.com/santiago-labs/telophasecli/lib/awsorgs"
+	"github.com/santiago-labs/telophasecli/lib/lock"
+	"github.com/santiago-labs/telophasecli/resource"
+	"github.com/santiago-labs/telophasecli/resourceoperation"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/spf13/cobra"
+)
+
+var autoApprove bool
+
+func init() {
+	rootCmd.AddCommand(destroyCmd)
+	destroyCmd.Flags().StringVar(&stacks, "stacks", "", "Filter stacks to destroy")
+	destroyCmd.Flags().StringVar(&tag, "tag", "", "Filter accounts and organization units to destroy with a comma separated list")
+	destroyCmd.Flags().StringVar(&accountFilter, "accounts", "", "Filter accounts to destroy with a comma separated list of account IDs or names")
+	destroyCmd.Flags().StringVar(&orgFile, "org", "organization.yml", "Path to the organization.yml file")
+	destroyCmd.Flags().BoolVar(&useTUI, "tui", false, "use the TUI for destroy")
+	destroyCmd.Flags().IntVar(&parallelism, "parallelism", 10, "Maximum number of stack and Service Control Policy operations to run at the same time. 0 is unlimited")
+	destroyCmd.Flags().IntVar(&regionParallelism, "region-parallelism", 0, "Maximum number of stack operations to run at the same time in each region. 0 is unlimited")
+	destroyCmd.Flags().BoolVar(&failFast, "fail-fast", false, "Stop starting operations after the first operation fails")
+	destroyCmd.Flags().BoolVar(&keepGoing, "keep-going", false, "Keep running the operations of other resources after an operation fails. This is the default")
+	destroyCmd.Flags().DurationVar(&stackTimeout, "timeout", 0, "Maximum time each stack operation can run, e.g. 30m. A stack's Timeout overrides it. 0 is unlimited")
+	destroyCmd.Flags().BoolVar(&autoApprove, "auto-approve", false, "Destroy stacks without asking for confirmation")
+	destroyCmd.Flags().StringVar(&lockTable, "lock-table", defaultLockTable(), "DynamoDB table to lock the AWS Organization in so that only one deploy or destroy runs at a time. Defaults to $TELOPHASE_LOCK_TABLE, no lock is taken if empty")
+	destroyCmd.Flags().DurationVar(&lockTTL, "lock-ttl", lock.DefaultTTL, "How long the lock is kept if telophasecli stops without releasing it")
+}
+
+var destroyCmd = &cobra.Command{
+	Use:   "destroy",
+	Short: "destroy - Destroy CDK, Terraform and Cloudformation stacks in your AWS account(s). Accounts and Organization Units are not changed.",
+	Run: func(cmd *cobra.Command, args []string) {
+		if useTUI && !autoApprove {
+			log.Fatal("--tui requires --auto-approve because the TUI can't ask for confirmation")
+		}
+		if err := validateParallelism(); err != nil {
+			log.Fatal("error validating parallelism err:", err)
+		}
+		if err := validateTimeout(); err != nil {
+			log.Fatal("error validating timeout err:", err)
+		}
+		if err := validateFailureMode(); err != nil {
+			log.Fatal("error validating failure mode err:", err)
+		}
+
+		ctx, cancel := signalContext()
+		defer cancel()
+
+		var consoleUI runner.ConsoleUI
+		var g errgroup.Group
+
+		if useTUI {
+			consoleUI = runner.NewTUI()
+			g.Go(func() error {
+				return ProcessDestroy(ctx, consoleUI, resourceoperation.Destroy)
+			})
+		} else {
+			consoleUI = runner.NewSTDOut()
+			if err := ProcessDestroy(ctx, consoleUI, resourceoperation.Destroy); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+		}
+
+		consoleUI.Start()
+		if err := g.Wait(); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	},
+}
+
+// ProcessDestroy destroys, or with DiffDestroy previews destroying, the stacks
+// of the accounts matching the filters. Stacks are destroyed in the reverse of
+// the order they are deployed in, after the stacks that depend on them.
+func ProcessDestroy(ctx context.Context, consoleUI runner.ConsoleUI, cmd int) error {
+	orgClient := awsorgs.New(nil)
+	if cmd == resourceoperation.Destroy {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithCancel(ctx)
+		defer cancel()
+		orgLock, lockAcct, err := lockOrganization(ctx, consoleUI, orgClient, "destroy", cancel)
+		if err != nil {
+			return err
+		}
+		defer releaseOrgLock(consoleUI, lockAcct, orgLock)
+	}
+
+	rootAWSOU, mgmtAcct, err := loadOrganization(ctx, consoleUI, orgClient)
+	if err != nil {
+		return err
+	}
+
+	accts := accountsToApply(rootAWSOU)
+	if len(accts) == 0 {
+		consoleUI.Print("No accounts to destroy.", *mgmtAcct)
+		return nil
+	}
+
+	errs := &runErrors{}
+	iacOps := collectIACOps(ctx, consoleUI, cmd, accts, errs)
+	if errs.stop() {
+		return errs.err()
+	}
+
+	if cmd == resourceoperation.Destroy && !autoApprove {
+		if !confirmDestroy(ctx, iacOps) {
+			consoleUI.Print("Destroy cancelled.", *mgmtAcct)
+			return nil
+		}
+	}
+
+	runIAC(ctx, consoleUI, cmd, iacOps, nil, errs)
+
+	if ctx.Err() != nil {
+		consoleUI.Print("Interrupted. Stacks that were not destroyed were skipped, run the command again to finish them.\n", *mgmtAcct)
+		if err := errs.err(); err != nil {
+			return err
+		}
+		return oops.Wrapf(ctx.Err(), "interrupted")
+	}
+	if errs.failed() {
+		consoleUI.Print("Error destroying stacks.", *mgmtAcct)
+		return errs.err()
+	}
+
+	consoleUI.Print("Done.\n", *mgmtAcct)
+	return nil
+}
+
+// confirmDestroy lists the stacks that will be destroyed and asks the user to
+// confirm. It returns false if ctx is canceled while waiting for the answer.
+func confirmDestroy(ctx context.Context, iacOps []accountOps) bool {
+	var total int
+	for _, acctOps := range iacOps {
+		for _, op := range acctOps.ops {
+			desc := op.Describe()
+			fmt.Printf("- %s %s in account %s\n", desc.ResourceType, stackLabel(desc.Stack), accountName(acctOps.acct))
+			total++
+		}
+	}
+	if total == 0 {
+		return true
+	}
+
+	fmt.Printf("\n%d stack(s) will be destroyed. Type 'destroy' to confirm: ", total)
+	answers := make(chan string, 1)
+	go func() {
+		answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil {
+			answer = ""
+		}
+		answers <- answer
+	}()
+
+	select {
+	case answer := <-answers:
+		return strings.TrimSpace(answer) == "destroy"
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func stackLabel(stack *resource.Stack) string {
+	if stack.Name != "" {
+		return fmt.Sprintf("%s (%s)", stack.Name, stack.Path)
+	}
+	return stack.Path
+}
+
+func accountName(acct resource.Account) string {
+	return fmt.Sprintf("%s (%s)", acct.AccountName, acct.AccountID)
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
@@ -13,6 +14,8 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var diffDestroy bool
+
 func init() {
 	rootCmd.AddCommand(diffCmd)
 	diffCmd.Flags().StringVar(&stacks, "stacks", "", "Filter stacks to deploy")
@@ -20,6 +23,16 @@ func init() {
 	diffCmd.Flags().StringVar(&targets, "targets", "", "Filter resource types to deploy. Options: organization, scp, stacks")
 	diffCmd.Flags().StringVar(&orgFile, "org", "organization.yml", "Path to the organization.yml file")
 	diffCmd.Flags().BoolVar(&useTUI, "tui", false, "use the TUI for diff")
+	diffCmd.Flags().IntVar(&parallelism, "parallelism", 10, "Maximum number of stack and Service Control Policy operations to run at the same time. 0 is unlimited")
+	diffCmd.Flags().IntVar(&regionParallelism, "region-parallelism", 0, "Maximum number of stack operations to run at the same time in each region. 0 is unlimited")
+	diffCmd.Flags().BoolVar(&failFast, "fail-fast", false, "Stop starting operations after the first operation fails")
+	diffCmd.Flags().BoolVar(&keepGoing, "keep-going", false, "Keep running the operations of other resources after an operation fails. This is the default")
+	diffCmd.Flags().DurationVar(&stackTimeout, "timeout", 0, "Maximum time each stack operation can run, e.g. 30m. A stack's Timeout overrides it. 0 is unlimited")
+	diffCmd.Flags().StringVar(&outputFormat, "output", outputText, "Output format. Options: text, json")
+	diffCmd.Flags().StringVar(&planOut, "out", "", "Save the diff as a plan that can be applied with deploy --plan")
+	diffCmd.Flags().BoolVar(&pruneOUs, "prune-ous", false, "Show the empty Organization Units that are not in organization.yml that deploy --prune-ous would delete")
+	diffCmd.Flags().StringVar(&accountFilter, "accounts", "", "Filter accounts to diff with a comma separated list of account IDs or names")
+	diffCmd.Flags().BoolVar(&diffDestroy, "destroy", false, "Show the stacks that telophasecli destroy would destroy")
 }
 
 var diffCmd = &cobra.Command{
@@ -30,6 +43,24 @@ var diffCmd = &cobra.Command{
 		if err := validateTargets(); err != nil {
 			log.Fatal("error validating targets err:", err)
 		}
+		if err := validateOutputFormat(); err != nil {
+			log.Fatal("error validating output err:", err)
+		}
+		if err := validateParallelism(); err != nil {
+			log.Fatal("error validating parallelism err:", err)
+		}
+		if err := validateTimeout(); err != nil {
+			log.Fatal("error validating timeout err:", err)
+		}
+		if err := validateFailureMode(); err != nil {
+			log.Fatal("error validating failure mode err:", err)
+		}
+		if diffDestroy && (targets != "" || planOut != "") {
+			log.Fatal("--destroy cannot be used with --targets or --out")
+		}
+		ctx, cancel := signalContext()
+		defer cancel()
+
 		var consoleUI runner.ConsoleUI
 		parsedTargets := filterEmptyStrings(strings.Split(targets, ","))
 
@@ -38,19 +69,27 @@ var diffCmd = &cobra.Command{
 		if useTUI {
 			consoleUI = runner.NewTUI()
 			g.Go(func() error {
-				return ProcessOrgEndToEnd(consoleUI, resourceoperation.Diff, parsedTargets)
+				return processDiff(ctx, consoleUI, parsedTargets)
 			})
 		} else {
-			consoleUI = runner.NewSTDOut()
-			if err := ProcessOrgEndToEnd(consoleUI, resourceoperation.Diff, parsedTargets); err != nil {
-				fmt.Println(err)
+			consoleUI = newConsoleUI()
+			if err := processDiff(ctx, consoleUI, parsedTargets); err != nil {
+				fmt.Fprintln(os.Stderr, err)
 				os.Exit(1)
 			}
 		}
 
 		consoleUI.Start()
 		if err := g.Wait(); err != nil {
+			fmt.Println(err)
 			os.Exit(1)
 		}
 	},
 }
+
+func processDiff(ctx context.Context, consoleUI runner.ConsoleUI, targets []string) error {
+	if diffDestroy {
+		return ProcessDestroy(ctx, consoleUI, resourceoperation.DiffDestroy)
+	}
+	return ProcessOrgEndToEnd(ctx, consoleUI, resourceoperation.Diff, targets)
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/samsarahq/go/oops"
+	"github.com/santiago-labs/telophasecli/cmd/runner"
+	"github.com/santiago-labs/telophasecli/lib/awsorgs"
+	"github.com/santiago-labs/telophasecli/resourceoperation"
+	"github.com/spf13/cobra"
+)
+
+const (
+	driftExitError = 1
+	driftExitDrift = 2
+)
+
+func init() {
+	rootCmd.AddCommand(driftCmd)
+	driftCmd.Flags().StringVar(&orgFile, "org", "organization.yml", "Path to the organization.yml file")
+	driftCmd.Flags().StringVar(&outputFormat, "output", outputText, "Output format. Options: text, json")
+}
+
+var driftCmd = &cobra.Command{
+	Use:   "drift",
+	Short: "drift - Compare organization.yml with the AWS Organization. Exits 0 with no drift, 2 with drift and 1 on error.",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := validateOutputFormat(); err != nil {
+			log.Fatal("error validating output err:", err)
+		}
+
+		drifts, err := detectDrift(context.Background(), runner.NewSTDErr())
+		if err != nil {
+			fmt.Fprintln(os.Stderr, oops.Cause(err))
+			os.Exit(driftExitError)
+		}
+
+		for _, drift := range drifts {
+			if outputFormat == outputJSON {
+				data, err := json.Marshal(drift)
+				if err != nil {
+					fmt.Fprintln(os.Stderr, err)
+					os.Exit(driftExitError)
+				}
+				fmt.Println(string(data))
+			} else {
+				fmt.Println(drift.String())
+			}
+		}
+
+		if len(drifts) > 0 {
+			fmt.Fprintf(os.Stderr, "Found %d difference(s) between %s and the AWS Organization\n", len(drifts), orgFile)
+			os.Exit(driftExitDrift)
+		}
+		fmt.Fprintf(os.Stderr, "No drift between %s and the AWS Organization\n", orgFile)
+	},
+}
+
+func detectDrift(ctx context.Context, consoleUI runner.ConsoleUI) ([]resourceoperation.Drift, error) {
+	orgClient := awsorgs.New(nil)
+	rootAWSOU, mgmtAcct, err := loadOrganization(ctx, consoleUI, orgClient)
+	if err != nil {
+		return nil, err
+	}
+
+	liveRootOU, err := orgClient.FetchOUAndDescendents(ctx, *rootAWSOU.OUID, mgmtAcct.AccountID)
+	if err != nil {
+		return nil, oops.Wrapf(err, "FetchOUAndDescendents")
+	}
+
+	delegatedAdmins, err := orgClient.FetchDelegatedAdminPrincipals(ctx)
+	if err != nil {
+		return nil, oops.Wrapf(err, "FetchDelegatedAdminPrincipals")
+	}
+
+	return resourceoperation.DetectDrift(rootAWSOU, &liveRootOU, delegatedAdmins), nil
+}
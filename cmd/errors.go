@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/samsarahq/go/oops"
+)
+
+var (
+	failFast  bool
+	keepGoing bool
+)
+
+// errFailFast is returned for operations that are skipped because of
+// --fail-fast.
+var errFailFast = errors.New("skipped because an operation failed and --fail-fast is set")
+
+func validateFailureMode() error {
+	if failFast && keepGoing {
+		return fmt.Errorf("--fail-fast and --keep-going can't be used together")
+	}
+	return nil
+}
+
+// resourceError is an error on a resource of the run, e.g. a stack in an
+// account.
+type resourceError struct {
+	Resource string
+	Err      error
+}
+
+func (e resourceError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Resource, oops.Cause(e.Err))
+}
+
+func (e resourceError) Unwrap() error {
+	return e.Err
+}
+
+// runErrors collects the errors of a run so that operations on other resources
+// keep going and the errors are reported together at the end of the run, when
+// the command prints the returned error. It is safe to use concurrently.
+type runErrors struct {
+	mu   sync.Mutex
+	errs []resourceError
+}
+
+func (e *runErrors) add(resource string, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.errs = append(e.errs, resourceError{Resource: resource, Err: err})
+}
+
+func (e *runErrors) failed() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return len(e.errs) > 0
+}
+
+// stop returns whether operations that have not started should be skipped
+// because of --fail-fast.
+func (e *runErrors) stop() bool {
+	return failFast && e.failed()
+}
+
+// err returns e, or nil if there are no errors.
+func (e *runErrors) err() error {
+	if !e.failed() {
+		return nil
+	}
+	return e
+}
+
+func (e *runErrors) Error() string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	lines := []string{fmt.Sprintf("%d error(s) while running operations:", len(e.errs))}
+	for _, err := range e.errs {
+		lines = append(lines, "- "+err.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (e *runErrors) Unwrap() []error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var errs []error
+	for _, err := range e.errs {
+		errs = append(errs, err)
+	}
+	return errs
+}
@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/samsarahq/go/oops"
+	"github.com/santiago-labs/telophasecli/cmd/runner"
+	"github.com/santiago-labs/telophasecli/lib/awsorgs"
+	"github.com/santiago-labs/telophasecli/lib/awssts"
+	"github.com/santiago-labs/telophasecli/resource"
+	"github.com/santiago-labs/telophasecli/resourceoperation"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	execRegions     string
+	execParallelism int
+)
+
+func init() {
+	rootCmd.AddCommand(execCmd)
+	execCmd.Flags().StringVar(&tag, "tag", "", "Filter accounts and organization units to run the command in with a comma separated list")
+	execCmd.Flags().StringVar(&accountFilter, "accounts", "", "Filter accounts to run the command in with a comma separated list of account IDs or names")
+	execCmd.Flags().StringVar(&orgFile, "org", "organization.yml", "Path to the organization.yml file")
+	execCmd.Flags().StringVar(&execRegions, "regions", "", "Run the command once per region with a comma separated list of regions. AWS_REGION is set for each run")
+	execCmd.Flags().IntVar(&execParallelism, "parallelism", 4, "Number of accounts to run the command in at the same time")
+}
+
+var execCmd = &cobra.Command{
+	Use:   "exec [flags] -- command [args...]",
+	Short: "exec - Run a command in each account with credentials for the account's role.",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if execParallelism < 1 {
+			fmt.Fprintln(os.Stderr, "--parallelism must be at least 1")
+			os.Exit(1)
+		}
+
+		ctx, cancel := signalContext()
+		defer cancel()
+
+		results, err := runExec(ctx, runner.NewSTDOut(), args)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, oops.Cause(err))
+			os.Exit(1)
+		}
+
+		printExecSummary(results)
+		for _, result := range results {
+			if result.ExitCode != 0 {
+				os.Exit(1)
+			}
+		}
+	},
+}
+
+// execResult is the result of running the command in one account and region.
+type execResult struct {
+	Account  resource.Account
+	Region   string
+	ExitCode int
+	Err      error
+}
+
+// runExec runs command in every selected account. Accounts run concurrently up
+// to --parallelism and the regions of an account run one after another.
+func runExec(ctx context.Context, consoleUI runner.ConsoleUI, command []string) ([]execResult, error) {
+	orgClient := awsorgs.New(nil)
+	rootAWSOU, mgmtAcct, err := loadOrganization(ctx, consoleUI, orgClient)
+	if err != nil {
+		return nil, err
+	}
+
+	var accts []resource.Account
+	for _, acct := range accountsToApply(rootAWSOU) {
+		if !acct.IsProvisioned() {
+			consoleUI.Print("Skipping account that has not been created yet.", acct)
+			continue
+		}
+		accts = append(accts, acct)
+	}
+	if len(accts) == 0 {
+		consoleUI.Print("No accounts to run the command in.", *mgmtAcct)
+		return nil, nil
+	}
+
+	regions := filterEmptyStrings(strings.Split(execRegions, ","))
+	if len(regions) == 0 {
+		// Keep the region from the environment.
+		regions = []string{""}
+	}
+
+	results := make([][]execResult, len(accts))
+	var g errgroup.Group
+	g.SetLimit(execParallelism)
+	for i, acct := range accts {
+		g.Go(func() error {
+			results[i] = execInAccount(ctx, consoleUI, acct, regions, command)
+			return nil
+		})
+	}
+	g.Wait()
+
+	var flattened []execResult
+	for _, acctResults := range results {
+		flattened = append(flattened, acctResults...)
+	}
+	return flattened, nil
+}
+
+func execInAccount(ctx context.Context, consoleUI runner.ConsoleUI, acct resource.Account, regions []string, command []string) []execResult {
+	var results []execResult
+
+	creds, _, err := resourceoperation.AuthAWS(acct, acct.AssumeRoleARN(), consoleUI)
+	if err != nil {
+		consoleUI.Print(fmt.Sprintf("Error: %v", oops.Cause(err)), acct)
+		for _, region := range regions {
+			results = append(results, execResult{Account: acct, Region: region, ExitCode: -1, Err: err})
+		}
+		return results
+	}
+
+	for _, region := range regions {
+		if ctx.Err() != nil {
+			results = append(results, execResult{Account: acct, Region: region, ExitCode: -1, Err: ctx.Err()})
+			continue
+		}
+
+		var regionEnv *string
+		if region != "" {
+			consoleUI.Print(fmt.Sprintf("Running %s in %s", strings.Join(command, " "), region), acct)
+			regionEnv = aws.String("AWS_REGION=" + region)
+		}
+
+		cmd := runner.Command(ctx, command[0], command[1:]...)
+		cmd.Env = awssts.SetEnvironCreds(os.Environ(), creds, regionEnv)
+
+		result := execResult{Account: acct, Region: region}
+		if err := consoleUI.RunCmd(cmd, acct); err != nil {
+			result.Err = err
+			result.ExitCode = -1
+			if cmd.ProcessState != nil {
+				result.ExitCode = cmd.ProcessState.ExitCode()
+			}
+			fmt.Fprintln(os.Stderr, err)
+		}
+		results = append(results, result)
+	}
+
+	return results
+}
+
+func printExecSummary(results []execResult) {
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ACCOUNT\tREGION\tEXIT CODE")
+	for _, result := range results {
+		region := result.Region
+		if region == "" {
+			region = "-"
+		}
+		exitCode := fmt.Sprint(result.ExitCode)
+		if result.ExitCode == -1 {
+			exitCode = "error"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", accountName(result.Account), region, exitCode)
+	}
+	w.Flush()
+}
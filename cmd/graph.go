@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/samsarahq/go/oops"
+	"github.com/santiago-labs/telophasecli/cmd/runner"
+	"github.com/santiago-labs/telophasecli/lib/awsorgs"
+	"github.com/santiago-labs/telophasecli/resourceoperation"
+	"github.com/spf13/cobra"
+)
+
+var graphFormat string
+
+func init() {
+	rootCmd.AddCommand(graphCmd)
+	graphCmd.Flags().StringVar(&stacks, "stacks", "", "Filter stacks to graph")
+	graphCmd.Flags().StringVar(&tag, "tag", "", "Filter accounts and organization units to graph with a comma separated list")
+	graphCmd.Flags().StringVar(&targets, "targets", "", "Filter resource types to graph. Options: organization, scp, stacks")
+	graphCmd.Flags().StringVar(&orgFile, "org", "organization.yml", "Path to the organization.yml file")
+	graphCmd.Flags().StringVar(&graphFormat, "format", "dot", "Graph format. Options: dot, mermaid")
+}
+
+var graphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "graph - Print the operations a deploy will perform and the order they run in as a DOT or Mermaid graph.",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := validateTargets(); err != nil {
+			log.Fatal("error validating targets err:", err)
+		}
+		if graphFormat != "dot" && graphFormat != "mermaid" {
+			log.Fatalf("invalid format: %s, options: dot, mermaid", graphFormat)
+		}
+
+		// The graph is printed to stdout so everything else goes to stderr.
+		consoleUI := runner.NewSTDErr()
+		graph, err := buildGraph(context.Background(), consoleUI, filterEmptyStrings(strings.Split(targets, ",")))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, oops.Cause(err))
+			os.Exit(1)
+		}
+
+		if graphFormat == "mermaid" {
+			fmt.Print(graph.Mermaid())
+		} else {
+			fmt.Print(graph.DOT())
+		}
+	},
+}
+
+func buildGraph(ctx context.Context, consoleUI runner.ConsoleUI, targets []string) (*resourceoperation.Graph, error) {
+	orgClient := awsorgs.New(nil)
+	rootAWSOU, mgmtAcct, err := loadOrganization(ctx, consoleUI, orgClient)
+	if err != nil {
+		return nil, err
+	}
+
+	var orgOps []resourceoperation.ResourceOperation
+	if includesTarget(targets, "organization") {
+		orgOps = resourceoperation.CollectOrganizationUnitOps(
+			ctx, consoleUI, orgClient, mgmtAcct, rootAWSOU, resourceoperation.Deploy, false, false,
+		)
+	}
+
+	// Stacks of accounts that don't exist yet are included because a deploy
+	// creates the accounts before deploying stacks. Their Region: all stacks
+	// are a single node since the enabled regions aren't known.
+	var accountStacks []resourceoperation.AccountStacks
+	if includesTarget(targets, "stacks") {
+		for _, acct := range accountsToApply(rootAWSOU) {
+			acctStacks, err := resourceoperation.FilterAccountStacks(&acct, stacks)
+			if err != nil {
+				return nil, oops.Wrapf(err, "FilterAccountStacks for account: %s", acct.AccountName)
+			}
+			accountStacks = append(accountStacks, resourceoperation.AccountStacks{
+				Account: acct,
+				Stacks:  acctStacks,
+			})
+		}
+	}
+
+	var scpOps []resourceoperation.ResourceOperation
+	if includesTarget(targets, "scp") {
+		var err error
+		scpOps, err = resourceoperation.CollectSCPOps(ctx, orgClient, consoleUI, resourceoperation.Deploy, rootAWSOU, scpAdministrator(rootAWSOU, mgmtAcct))
+		if err != nil {
+			return nil, oops.Wrapf(err, "CollectSCPOps")
+		}
+	}
+
+	return resourceoperation.NewGraph(orgOps, accountStacks, scpOps)
+}
@@ -2,61 +2,475 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/samsarahq/go/oops"
 	"github.com/santiago-labs/telophasecli/cmd/runner"
+	"github.com/santiago-labs/telophasecli/lib/awsorgs"
+	"github.com/santiago-labs/telophasecli/lib/awssess"
+	"github.com/santiago-labs/telophasecli/lib/outputs"
+	"github.com/santiago-labs/telophasecli/lib/scheduler"
 	"github.com/santiago-labs/telophasecli/resource"
 	"github.com/santiago-labs/telophasecli/resourceoperation"
 )
 
-func runIAC(
+var (
+	parallelism       int
+	regionParallelism int
+	stackTimeout      time.Duration
+)
+
+func validateParallelism() error {
+	if parallelism < 0 || regionParallelism < 0 {
+		return fmt.Errorf("--parallelism and --region-parallelism can't be negative")
+	}
+	return nil
+}
+
+func validateTimeout() error {
+	if stackTimeout < 0 {
+		return fmt.Errorf("--timeout can't be negative")
+	}
+	return nil
+}
+
+// timeoutError is returned when a stack operation runs longer than its
+// timeout.
+type timeoutError struct {
+	timeout time.Duration
+	err     error
+}
+
+func (e *timeoutError) Error() string {
+	return fmt.Sprintf("timed out after %s: %v", e.timeout, e.err)
+}
+
+func (e *timeoutError) Unwrap() error {
+	return e.err
+}
+
+// opTimeout returns how long a stack operation can run. The stack's Timeout
+// overrides --timeout. 0 is unlimited.
+func opTimeout(stack resource.Stack) time.Duration {
+	// Stacks are validated when organization.yml is parsed.
+	if timeout, err := stack.TimeoutDuration(); err == nil && timeout > 0 {
+		return timeout
+	}
+	return stackTimeout
+}
+
+func newScheduler() *scheduler.Scheduler {
+	return scheduler.New(parallelism, regionParallelism)
+}
+
+// accountOps are the stack operations for a single account.
+type accountOps struct {
+	acct resource.Account
+	ops  []resourceoperation.ResourceOperation
+	// err is set if the operations could not be collected.
+	err error
+}
+
+// collectIACOps collects the stack operations for every provisioned account.
+// The result is in the same order as accts so that plans are deterministic.
+// Accounts whose operations can't be collected are added to errs and have no
+// operations.
+func collectIACOps(
 	ctx context.Context,
 	consoleUI runner.ConsoleUI,
 	cmd int,
 	accts []resource.Account,
-) error {
+	errs *runErrors,
+) []accountOps {
+	result := make([]accountOps, len(accts))
 	var wg sync.WaitGroup
-
-	var once sync.Once
-	var retError error
+	// Collecting Cloudformation operations assumes a role in the account so
+	// it is limited like running operations.
+	sched := newScheduler()
 
 	for i := range accts {
+		result[i].acct = accts[i]
 		wg.Add(1)
-		go func(acct resource.Account) {
+		go func(acctOps *accountOps) {
 			defer wg.Done()
-			if !acct.IsProvisioned() {
-				consoleUI.Print(fmt.Sprintf("skipping account: %s because it hasn't been provisioned yet", acct.AccountName), acct)
+			if !acctOps.acct.IsProvisioned() {
+				consoleUI.Print(fmt.Sprintf("skipping account: %s because it hasn't been provisioned yet", acctOps.acct.AccountName), acctOps.acct)
 				return
 			}
 
-			ops, err := resourceoperation.CollectAccountOps(ctx, consoleUI, cmd, &acct, stacks)
-			if err != nil {
-				panic(oops.Wrapf(err, "error collecting account ops for acct: %s", acct.AccountID))
+			sched.Run(ctx, "", func() error {
+				ops, err := resourceoperation.CollectAccountOps(ctx, consoleUI, cmd, &acctOps.acct, stacks)
+				if err != nil {
+					consoleUI.Print(fmt.Sprintf("Error collecting stacks: %v", oops.Cause(err)), acctOps.acct)
+					acctOps.err = err
+					errs.add(fmt.Sprintf("account %s", accountName(acctOps.acct)), oops.Wrapf(err, "collecting stacks"))
+					return nil
+				}
+				acctOps.ops = ops
+				return nil
+			})
+		}(&result[i])
+	}
+
+	wg.Wait()
+
+	return result
+}
+
+// runIAC runs the operations of every account. An operation waits for the
+// operations of the stacks it depends on, see resource.StackDependencies, and
+// for a slot in the scheduler. Operations whose dependencies failed are
+// skipped. When destroying, an operation instead waits for the operations of
+// the stacks that depend on it.
+//
+// When deploying, stacks with a Rollout also wait for the earlier waves of the
+// rollout, see resource.RolloutSteps. With HaltOnFailure the later waves are
+// not attempted if a stack in a wave fails.
+//
+// Results are recorded in journal, which may be nil. Operations that succeeded
+// in the journal with the same input are skipped unless a stack they depend on
+// runs again.
+//
+// Errors are added to errs. With --fail-fast, operations that have not started
+// are skipped once errs has an error.
+func runIAC(
+	ctx context.Context,
+	consoleUI runner.ConsoleUI,
+	cmd int,
+	acctOps []accountOps,
+	journal *resourceoperation.Journal,
+	errs *runErrors,
+) {
+	var nodes []resource.AccountStack
+	var ops []resourceoperation.ResourceOperation
+	for i := range acctOps {
+		if !acctOps[i].acct.IsProvisioned() || acctOps[i].err != nil {
+			continue
+		}
+		if len(acctOps[i].ops) == 0 {
+			consoleUI.Print("No stacks to deploy\n", acctOps[i].acct)
+			continue
+		}
+		for _, op := range acctOps[i].ops {
+			nodes = append(nodes, resource.AccountStack{
+				Account: &acctOps[i].acct,
+				Stack:   *op.Describe().Stack,
+			})
+			ops = append(ops, op)
+		}
+	}
+
+	deps, err := resource.StackDependencies(nodes)
+	if err != nil {
+		// A cycle has at least one stack.
+		consoleUI.Print(err.Error(), *nodes[0].Account)
+		errs.add("stacks", err)
+		return
+	}
+	if cmd == resourceoperation.Destroy || cmd == resourceoperation.DiffDestroy {
+		deps = reverseDependencies(deps)
+	}
+	steps := make([]resource.RolloutStep, len(nodes))
+	if cmd == resourceoperation.Deploy {
+		steps = resource.RolloutSteps(nodes)
+	}
+	// Dependencies include the stacks whose outputs a stack references so the
+	// outputs are stored before the stack runs.
+	store := outputs.NewStore()
+	if cmd == resourceoperation.Diff {
+		store = outputs.NewDiffStore()
+	}
+	resourceoperation.SetOutputs(ops, store)
+
+	var wg sync.WaitGroup
+
+	var haltedLock sync.Mutex
+	var halted []int
+
+	sched := newScheduler()
+	region := defaultRegion()
+
+	done := make([]chan struct{}, len(ops))
+	succeeded := make([]bool, len(ops))
+	ran := make([]bool, len(ops))
+	for i := range done {
+		done[i] = make(chan struct{})
+	}
+
+	for i := range ops {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer close(done[i])
+
+			op, acct := ops[i], *nodes[i].Account
+			for _, dep := range deps[i] {
+				<-done[dep]
+				if !succeeded[dep] {
+					if ctx.Err() == nil {
+						consoleUI.Print(fmt.Sprintf("Skipping %s because %s did not succeed", nodes[i], nodes[dep]), acct)
+					}
+					printResult(op, statusSkipped, nil)
+					return
+				}
+			}
+
+			for _, wait := range steps[i].Waits {
+				<-done[wait]
+				if !succeeded[wait] && steps[i].HaltOnFailure {
+					if ctx.Err() == nil {
+						consoleUI.Print(fmt.Sprintf("Not attempting %s because wave %d of its Rollout did not succeed", nodes[i], steps[i].Wave-1), acct)
+					}
+					haltedLock.Lock()
+					halted = append(halted, i)
+					haltedLock.Unlock()
+					printResult(op, statusNotAttempted, nil)
+					return
+				}
 			}
 
-			if len(ops) == 0 {
-				consoleUI.Print("No stacks to deploy\n", acct)
+			if journaled(ctx, consoleUI, journal, store, op, nodes[i], deps[i], ran) {
+				succeeded[i] = true
+				printResult(op, statusAlreadySucceeded, nil)
 				return
 			}
+			ran[i] = true
 
-			for _, op := range ops {
-				if err := op.Call(ctx); err != nil {
-					once.Do(func() {
-						retError = err
-					})
-					consoleUI.Print(fmt.Sprintf("%v", err), acct)
+			started, err := runOp(ctx, sched, opRegion(op, region), op, opTimeout(nodes[i].Stack), errs)
+			if errors.Is(err, errFailFast) {
+				consoleUI.Print(fmt.Sprintf("Skipping %s because an operation failed and --fail-fast is set", nodes[i]), acct)
+				printResult(op, statusSkipped, nil)
+				return
+			}
+			if started {
+				if err := journal.Record(op, err, store); err != nil {
+					consoleUI.Print(fmt.Sprintf("Could not record %s in the journal: %v", nodes[i], oops.Cause(err)), acct)
+				}
+			}
+			var timeoutErr *timeoutError
+			if errors.As(err, &timeoutErr) {
+				errs.add(nodes[i].String(), err)
+				consoleUI.Print(fmt.Sprintf("Timed out %s after %s", nodes[i], timeoutErr.timeout), acct)
+				printResult(op, statusTimedOut, err)
+				return
+			}
+			if err != nil && ctx.Err() != nil {
+				if !started {
+					printResult(op, statusSkipped, nil)
 					return
 				}
+				errs.add(nodes[i].String(), oops.Wrapf(err, "interrupted"))
+				consoleUI.Print(fmt.Sprintf("Interrupted %s", nodes[i]), acct)
+				printResult(op, statusInterrupted, err)
+				return
 			}
-		}(accts[i])
+			if err != nil {
+				errs.add(nodes[i].String(), err)
+				consoleUI.Print(fmt.Sprintf("%v", err), acct)
+				printResult(op, statusFailed, err)
+				return
+			}
+			succeeded[i] = true
+			printResult(op, statusSucceeded, nil)
+		}(i)
+	}
+
+	wg.Wait()
+
+	if len(halted) > 0 && ctx.Err() == nil {
+		sort.Ints(halted)
+		var names []string
+		for _, i := range halted {
+			names = append(names, nodes[i].String())
+		}
+		consoleUI.Print(fmt.Sprintf("Rollout halted, these stacks were never attempted:\n%s", strings.Join(names, "\n")), *nodes[halted[0]].Account)
+	}
+}
+
+// journaled returns whether op already succeeded in journal with the same
+// input. It is run again if any of the operations it depends on ran.
+func journaled(
+	ctx context.Context,
+	consoleUI runner.ConsoleUI,
+	journal *resourceoperation.Journal,
+	store *outputs.Store,
+	op resourceoperation.ResourceOperation,
+	node resource.AccountStack,
+	deps []int,
+	ran []bool,
+) bool {
+	if journal == nil || ctx.Err() != nil {
+		return false
+	}
+	for _, dep := range deps {
+		if ran[dep] {
+			return false
+		}
+	}
+
+	ok, err := journal.Succeeded(op, store)
+	if err != nil {
+		consoleUI.Print(fmt.Sprintf("Could not check the journal for %s, running it: %v", node, oops.Cause(err)), *node.Account)
+		return false
+	}
+	if ok {
+		consoleUI.Print(fmt.Sprintf("Skipping %s because it already succeeded in run %s", node, journal.RunID), *node.Account)
+	}
+	return ok
+}
+
+// reverseDependencies returns the dependencies with every edge reversed.
+func reverseDependencies(deps [][]int) [][]int {
+	reversed := make([][]int, len(deps))
+	for i, iDeps := range deps {
+		for _, dep := range iDeps {
+			reversed[dep] = append(reversed[dep], i)
+		}
+	}
+	return reversed
+}
+
+// collectSCPOps collects the Service Control Policy operations. A collection
+// error is added to errs.
+func collectSCPOps(
+	ctx context.Context,
+	consoleUI runner.ConsoleUI,
+	orgClient awsorgs.Client,
+	cmd int,
+	rootAWSOU *resource.OrganizationUnit,
+	scpAdmin *resource.Account,
+	errs *runErrors,
+) []resourceoperation.ResourceOperation {
+	ops, err := resourceoperation.CollectSCPOps(ctx, orgClient, consoleUI, cmd, rootAWSOU, scpAdmin)
+	if err != nil {
+		consoleUI.Print(fmt.Sprintf("Error collecting Service Control Policies: %v", oops.Cause(err)), *scpAdmin)
+		errs.add("Service Control Policies", oops.Wrapf(err, "collecting Service Control Policies"))
+	}
+	return ops
+}
+
+// runSCPOps runs the Service Control Policy operations in parallel. They are
+// not limited by region because Organizations is a global service.
+func runSCPOps(
+	ctx context.Context,
+	consoleUI runner.ConsoleUI,
+	scpAdmin *resource.Account,
+	scpOps []resourceoperation.ResourceOperation,
+	errs *runErrors,
+) {
+	var wg sync.WaitGroup
+
+	sched := newScheduler()
+	for _, op := range scpOps {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			started, err := runOp(ctx, sched, "", op, 0, errs)
+			if errors.Is(err, errFailFast) {
+				consoleUI.Print(fmt.Sprintf("Skipping %s because an operation failed and --fail-fast is set", operationLabel(op)), *scpAdmin)
+				printResult(op, statusSkipped, nil)
+				return
+			}
+			if err != nil && ctx.Err() != nil {
+				if !started {
+					printResult(op, statusSkipped, nil)
+					return
+				}
+				errs.add(operationLabel(op), oops.Wrapf(err, "interrupted"))
+				consoleUI.Print(fmt.Sprintf("Interrupted %s", operationLabel(op)), *scpAdmin)
+				printResult(op, statusInterrupted, err)
+				return
+			}
+			if err != nil {
+				errs.add(operationLabel(op), err)
+				consoleUI.Print(fmt.Sprintf("Error on SCP Operation: %v", err), *scpAdmin)
+				printResult(op, statusFailed, err)
+				return
+			}
+			printResult(op, statusSucceeded, nil)
+		}()
 	}
 
 	wg.Wait()
+}
 
-	return retError
+// operationLabel names the resource of op in messages and the error report.
+func operationLabel(op resourceoperation.ResourceOperation) string {
+	desc := op.Describe()
+	label := strings.TrimSpace(fmt.Sprintf("%s %s %s", desc.Operation, desc.ResourceType, desc.ResourceName))
+	if desc.Stack != nil {
+		label = fmt.Sprintf("%s (%s)", label, desc.Stack.Path)
+	}
+	return label
 }
+
+// runOp calls op once there is a free slot in sched. op is canceled with a
+// *timeoutError if it runs longer than timeout, not counting the time spent
+// waiting for a slot. A timeout of 0 is unlimited. started is false if ctx was
+// canceled before op was called. With --fail-fast, op is not called and
+// errFailFast is returned if errs has an error by the time there is a slot.
+func runOp(
+	ctx context.Context,
+	sched *scheduler.Scheduler,
+	region string,
+	op resourceoperation.ResourceOperation,
+	timeout time.Duration,
+	errs *runErrors,
+) (started bool, err error) {
+	err = sched.Run(ctx, region, func() error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if errs.stop() {
+			return errFailFast
+		}
+		started = true
+
+		if timeout == 0 {
+			return op.Call(ctx)
+		}
+		opCtx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		err := op.Call(opCtx)
+		if err != nil && ctx.Err() == nil && errors.Is(opCtx.Err(), context.DeadlineExceeded) {
+			return &timeoutError{timeout: timeout, err: err}
+		}
+		return err
+	})
+	return started, err
+}
+
+// opRegion returns the region a stack operation runs in.
+func opRegion(op resourceoperation.ResourceOperation, defaultRegion string) string {
+	if stack := op.Describe().Stack; stack != nil && stack.Region != "" {
+		return stack.Region
+	}
+	return defaultRegion
+}
+
+// defaultRegion is the region of stacks without a Region.
+func defaultRegion() string {
+	sess, err := awssess.DefaultSession()
+	if err != nil {
+		return ""
+	}
+	return aws.StringValue(sess.Config.Region)
+}
+
+func flattenAccountOps(acctOps []accountOps) []resourceoperation.ResourceOperation {
+	var ops []resourceoperation.ResourceOperation
+	for _, a := range acctOps {
+		ops = append(ops, a.ops...)
+	}
+	return ops
+}
+
 func contains(e string, s []string) bool {
 	for _, a := range s {
 		if a == e {
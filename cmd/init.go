@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/samsarahq/go/oops"
+	"github.com/santiago-labs/telophasecli/lib/awsorgs"
+	"github.com/santiago-labs/telophasecli/lib/localstack"
+	"github.com/santiago-labs/telophasecli/lib/scaffold"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	initDir    string
+	initRegion string
+)
+
+func init() {
+	rootCmd.AddCommand(initCmd)
+	initCmd.Flags().StringVar(&initDir, "dir", ".", "Directory to generate organization.yml and the example stacks in")
+	initCmd.Flags().StringVar(&initRegion, "region", "", "Region to deploy the example stacks to. Defaults to AWS_REGION or us-east-1")
+}
+
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "init - Generate an organization.yml and example stacks from your AWS Organization.",
+	Run: func(cmd *cobra.Command, args []string) {
+		paths, err := runInit(context.Background())
+		if err != nil {
+			fmt.Fprintln(os.Stderr, oops.Cause(err))
+			os.Exit(1)
+		}
+
+		for _, path := range paths {
+			fmt.Printf("Created %s\n", path)
+		}
+		fmt.Println("\nRun `telophasecli validate` and `telophasecli diff` in the directory to get started.")
+	},
+}
+
+func runInit(ctx context.Context) ([]string, error) {
+	orgClient := awsorgs.New(nil)
+
+	rootID, err := orgClient.GetRootId()
+	if err != nil {
+		return nil, oops.Wrapf(err, "GetRootId")
+	}
+	if rootID == "" {
+		return nil, fmt.Errorf("no root ID found, is AWS Organizations enabled?")
+	}
+
+	mgmtAcct, err := orgClient.FetchManagementAccount(ctx)
+	if err != nil {
+		return nil, oops.Wrapf(err, "FetchManagementAccount")
+	}
+
+	rootOU, err := orgClient.FetchOUAndDescendents(ctx, rootID, mgmtAcct.AccountID)
+	if err != nil {
+		return nil, oops.Wrapf(err, "FetchOUAndDescendents")
+	}
+
+	region := initRegion
+	if region == "" {
+		region = os.Getenv("AWS_REGION")
+	}
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	return scaffold.Write(initDir, scaffold.Organization{
+		RootID:            rootID,
+		ManagementAccount: *mgmtAcct,
+		OUs:               rootOU.ChildOUs,
+		Region:            region,
+		LocalStack:        localstack.UsingLocalStack(),
+	})
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/samsarahq/go/oops"
+	"github.com/santiago-labs/telophasecli/cmd/runner"
+	"github.com/santiago-labs/telophasecli/lib/awsorgs"
+	"github.com/santiago-labs/telophasecli/lib/lock"
+	"github.com/santiago-labs/telophasecli/resource"
+)
+
+var (
+	lockTable string
+	lockTTL   time.Duration
+)
+
+// orgLockID is the ID of the lock for the organization mgmtAcct manages.
+func orgLockID(mgmtAcct *resource.Account) (string, error) {
+	if mgmtAcct.AccountID == "" {
+		return "", fmt.Errorf("management account %s has no account ID to lock the organization with", mgmtAcct.AccountName)
+	}
+	return "org-" + mgmtAcct.AccountID, nil
+}
+
+// acquireOrgLock locks the organization so that only one command changes it at
+// a time. It returns a nil lock if --lock-table is not set. The lock must be
+// released when the command is done. If the lock is lost while the command
+// runs, cancel is called to stop the command.
+func acquireOrgLock(ctx context.Context, consoleUI runner.ConsoleUI, mgmtAcct *resource.Account, command string, cancel context.CancelFunc) (*lock.Lock, error) {
+	if lockTable == "" {
+		return nil, nil
+	}
+
+	id, err := orgLockID(mgmtAcct)
+	if err != nil {
+		return nil, err
+	}
+	locker, err := lock.NewDefault(lockTable, lockTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	l, err := locker.Acquire(ctx, id, lock.Owner(), command)
+	var lockedErr *lock.LockedError
+	if errors.As(err, &lockedErr) {
+		consoleUI.Print(fmt.Sprintf("The AWS Organization is locked by %s. If that command is no longer running, remove the lock with: telophasecli unlock --force --lock-table %s", lockedErr.Info, lockTable), *mgmtAcct)
+		return nil, err
+	}
+	if err != nil {
+		consoleUI.Print(fmt.Sprintf("Error locking AWS Organization: %s", oops.Cause(err)), *mgmtAcct)
+		return nil, err
+	}
+
+	consoleUI.Print(fmt.Sprintf("Locked AWS Organization in table %s until the %s is done.", lockTable, command), *mgmtAcct)
+	go func() {
+		select {
+		case <-l.Lost():
+			consoleUI.Print(fmt.Sprintf("Lost the lock on the AWS Organization, stopping the %s: %s", command, oops.Cause(l.Err())), *mgmtAcct)
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return l, nil
+}
+
+// lockOrganization locks the organization before organization.yml is parsed,
+// so that the command plans against the organization as it is once no other
+// command can change it. The management account is fetched from AWS Organizations
+// for the lock ID and to print to. It returns a nil lock if --lock-table is
+// not set. cancel is called if the lock is lost.
+func lockOrganization(ctx context.Context, consoleUI runner.ConsoleUI, orgClient awsorgs.Client, command string, cancel context.CancelFunc) (*lock.Lock, *resource.Account, error) {
+	if lockTable == "" {
+		return nil, nil, nil
+	}
+
+	mgmtAcct, err := orgClient.FetchManagementAccount(ctx)
+	if err != nil {
+		consoleUI.Print(fmt.Sprintf("Could not fetch AWS Management Account: %s", err), resource.Account{AccountID: "error", AccountName: "error"})
+		return nil, nil, oops.Wrapf(err, "FetchManagementAccount")
+	}
+	l, err := acquireOrgLock(ctx, consoleUI, mgmtAcct, command, cancel)
+	if err != nil {
+		return nil, nil, oops.Wrapf(err, "acquireOrgLock")
+	}
+	return l, mgmtAcct, nil
+}
+
+// releaseOrgLock releases l. Errors are printed because the lock expires
+// after --lock-ttl anyway.
+func releaseOrgLock(consoleUI runner.ConsoleUI, mgmtAcct *resource.Account, l *lock.Lock) {
+	if err := l.Release(); err != nil {
+		consoleUI.Print(fmt.Sprintf("Error releasing lock, it expires after %s: %s", lockTTL, oops.Cause(err)), *mgmtAcct)
+	}
+}
+
+func defaultLockTable() string {
+	return os.Getenv(lock.TableEnv)
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/samsarahq/go/oops"
+	"github.com/santiago-labs/telophasecli/cmd/runner"
+	"github.com/santiago-labs/telophasecli/resourceoperation"
+)
+
+const (
+	outputText = "text"
+	outputJSON = "json"
+
+	// Operation statuses printed with --output json.
+	statusPlanned     = "planned"
+	statusSucceeded   = "succeeded"
+	statusFailed      = "failed"
+	statusSkipped     = "skipped"
+	statusInterrupted = "interrupted"
+	statusTimedOut    = "timed_out"
+	// statusAlreadySucceeded is a stack skipped by deploy --resume.
+	statusAlreadySucceeded = "already_succeeded"
+	// statusNotAttempted is a stack in a Rollout wave after a failed wave.
+	statusNotAttempted = "not_attempted"
+	statusUnknown      = "unknown"
+)
+
+var (
+	outputFormat string
+
+	resultLock sync.Mutex
+)
+
+// operationResult is the document printed for every operation with --output
+// json.
+type operationResult struct {
+	resourceoperation.Description
+
+	Status     string            `json:"status"`
+	Error      string            `json:"error,omitempty"`
+	Dependents []operationResult `json:"dependents,omitempty"`
+}
+
+func validateOutputFormat() error {
+	if outputFormat != outputText && outputFormat != outputJSON {
+		return fmt.Errorf("invalid output: %s, options: %s, %s", outputFormat, outputText, outputJSON)
+	}
+	if outputFormat == outputJSON && useTUI {
+		return fmt.Errorf("--output %s cannot be used with --tui", outputJSON)
+	}
+	return nil
+}
+
+// newConsoleUI returns the ConsoleUI for non-TUI runs. Human readable output
+// goes to stderr when stdout is used for JSON.
+func newConsoleUI() runner.ConsoleUI {
+	if outputFormat == outputJSON {
+		return runner.NewSTDErr()
+	}
+	return runner.NewSTDOut()
+}
+
+// printResult prints an operation as a single line of JSON to stdout when
+// --output json is set.
+func printResult(op resourceoperation.ResourceOperation, status string, err error) {
+	if outputFormat != outputJSON {
+		return
+	}
+
+	data, marshalErr := json.Marshal(newOperationResult(op, status, err))
+	if marshalErr != nil {
+		fmt.Fprintf(os.Stderr, "error marshalling operation result: %v\n", marshalErr)
+		return
+	}
+
+	resultLock.Lock()
+	defer resultLock.Unlock()
+	fmt.Fprintln(os.Stdout, string(data))
+}
+
+func newOperationResult(op resourceoperation.ResourceOperation, status string, err error) operationResult {
+	result := operationResult{
+		Description: op.Describe(),
+		Status:      status,
+	}
+	if err != nil {
+		result.Error = oops.Cause(err).Error()
+	}
+
+	// Dependents are called by their parent operation. If the parent failed
+	// we can't tell which of them ran.
+	dependentStatus := status
+	if status == statusFailed {
+		dependentStatus = statusUnknown
+	}
+	for _, dependent := range op.ListDependents() {
+		result.Dependents = append(result.Dependents, newOperationResult(dependent, dependentStatus, nil))
+	}
+
+	return result
+}
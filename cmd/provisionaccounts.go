@@ -9,18 +9,26 @@ import (
 
 	"github.com/santiago-labs/telophasecli/cmd/runner"
 	"github.com/santiago-labs/telophasecli/lib/awsorgs"
+	"github.com/santiago-labs/telophasecli/lib/lock"
 	"github.com/santiago-labs/telophasecli/lib/ymlparser"
 	"github.com/santiago-labs/telophasecli/resource"
 	"github.com/santiago-labs/telophasecli/resourceoperation"
 )
 
-var orgFile string
+var (
+	orgFile     string
+	mergeImport bool
+)
 
 func init() {
 	rootCmd.AddCommand(accountProvision)
 	accountProvision.Flags().StringVar(&orgFile, "org", "organization.yml", "Path to the organization.yml file")
 	accountProvision.Flags().BoolVar(&useTUI, "tui", false, "use the TUI for diff")
 	accountProvision.Flags().BoolVar(&allowDeleteAccount, "allow-account-delete", false, "Allow closing an AWS account")
+	accountProvision.Flags().BoolVar(&pruneOUs, "prune-ous", false, "Delete empty Organization Units that are not in organization.yml")
+	accountProvision.Flags().BoolVar(&mergeImport, "merge", false, "Add OUs and accounts that are not in the existing organization.yml when importing")
+	accountProvision.Flags().StringVar(&lockTable, "lock-table", defaultLockTable(), "DynamoDB table to lock the AWS Organization in so that only one deploy or destroy runs at a time. Defaults to $TELOPHASE_LOCK_TABLE, no lock is taken if empty")
+	accountProvision.Flags().DurationVar(&lockTTL, "lock-ttl", lock.DefaultTTL, "How long the lock is kept if telophasecli stops without releasing it")
 }
 
 func isValidAccountArg(arg string) bool {
@@ -79,6 +87,17 @@ func processOrg(consoleUI runner.ConsoleUI, cmd string) {
 		}
 	}
 
+	if cmd == "deploy" {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithCancel(ctx)
+		defer cancel()
+		orgLock, lockAcct, err := lockOrganization(ctx, consoleUI, orgClient, "account deploy", cancel)
+		if err != nil {
+			return
+		}
+		defer releaseOrgLock(consoleUI, lockAcct, orgLock)
+	}
+
 	rootAWSOU, err := ymlparser.NewParser(orgClient).ParseOrganization(ctx, orgFile)
 	if err != nil {
 		consoleUI.Print(fmt.Sprintf("error parsing organization: %s", err), resource.Account{AccountID: "error", AccountName: "error"})
@@ -93,7 +112,7 @@ func processOrg(consoleUI runner.ConsoleUI, cmd string) {
 	if cmd == "diff" {
 		consoleUI.Print("Diffing AWS Organization", *mgmtAcct)
 		orgOps := resourceoperation.CollectOrganizationUnitOps(
-			ctx, consoleUI, orgClient, mgmtAcct, rootAWSOU, resourceoperation.Diff, allowDeleteAccount,
+			ctx, consoleUI, orgClient, mgmtAcct, rootAWSOU, resourceoperation.Diff, allowDeleteAccount, pruneOUs,
 		)
 		for _, op := range resourceoperation.FlattenOperations(orgOps) {
 			consoleUI.Print(op.ToString(), *mgmtAcct)
@@ -106,7 +125,7 @@ func processOrg(consoleUI runner.ConsoleUI, cmd string) {
 	if cmd == "deploy" {
 		consoleUI.Print("Diffing AWS Organization", *mgmtAcct)
 		orgOps := resourceoperation.CollectOrganizationUnitOps(
-			ctx, consoleUI, orgClient, mgmtAcct, rootAWSOU, resourceoperation.Deploy, allowDeleteAccount,
+			ctx, consoleUI, orgClient, mgmtAcct, rootAWSOU, resourceoperation.Deploy, allowDeleteAccount, pruneOUs,
 		)
 
 		for _, op := range resourceoperation.FlattenOperations(orgOps) {
@@ -141,16 +160,30 @@ func importOrg(ctx context.Context, consoleUI runner.ConsoleUI, orgClient awsorg
 	if err != nil {
 		return err
 	}
-	org := resource.OrganizationUnit{
-		OUName:   rootOU.OUName,
-		ChildOUs: rootOU.ChildOUs,
-		Accounts: rootOU.Accounts,
-	}
 
-	if err := ymlparser.WriteOrgFile(orgFile, &org); err != nil {
+	imported, err := ymlparser.NewImportedOrganization(ctx, orgClient, &rootOU)
+	if err != nil {
 		return err
 	}
 
+	if mergeImport {
+		added, err := ymlparser.MergeOrgFile(orgFile, imported)
+		if err != nil {
+			return err
+		}
+		for _, desc := range added {
+			consoleUI.Print(fmt.Sprintf("Added %s", desc), *mgmtAcct)
+		}
+		if len(added) == 0 {
+			consoleUI.Print(fmt.Sprintf("%s is up to date", orgFile), *mgmtAcct)
+		}
+		return nil
+	}
+
+	if err := ymlparser.WriteImportedOrgFile(orgFile, imported); err != nil {
+		return fmt.Errorf("%w, use --merge to add new OUs and accounts to it", err)
+	}
+
 	consoleUI.Print(fmt.Sprintf("Successfully wrote file to: %s", orgFile), *mgmtAcct)
 	return nil
 }
@@ -36,54 +36,96 @@ func Execute() {
 	}
 }
 
-func setOpsError() error {
-	return fmt.Errorf("error running operations")
-}
+func ProcessOrgEndToEnd(ctx context.Context, consoleUI runner.ConsoleUI, cmd int, targets []string) error {
+	// When deploying a saved plan the filters come from the plan so that the
+	// deploy collects the same operations the diff did.
+	var savedPlan *resourceoperation.Plan
+	if cmd == resourceoperation.Deploy && planFile != "" {
+		var err error
+		savedPlan, err = resourceoperation.ReadPlan(planFile)
+		if err != nil {
+			consoleUI.Print(fmt.Sprintf("error: %s", oops.Cause(err)), resource.Account{AccountID: "error", AccountName: "error"})
+			return oops.Wrapf(err, "ReadPlan")
+		}
+		targets = savedPlan.Targets
+		tag = savedPlan.Tag
+		stacks = savedPlan.Stacks
+		accountFilter = savedPlan.Accounts
+		allowDeleteAccount = savedPlan.AllowAccountDelete
+		pruneOUs = savedPlan.PruneOUs
+	}
 
-func ProcessOrgEndToEnd(consoleUI runner.ConsoleUI, cmd int, targets []string) error {
-	ctx := context.Background()
 	orgClient := awsorgs.New(nil)
-	rootAWSOU, err := ymlparser.NewParser(orgClient).ParseOrganization(ctx, orgFile)
-	if err != nil {
-		consoleUI.Print(fmt.Sprintf("error: %s", err), resource.Account{AccountID: "error", AccountName: "error"})
-		return oops.Wrapf(err, "ParseOrg")
-	}
 
-	if rootAWSOU == nil {
-		consoleUI.Print("Could not parse AWS Organization", resource.Account{AccountID: "error", AccountName: "error"})
-		return oops.Errorf("No root AWS OU")
+	// Diffs don't change anything so they run without the lock. A deploy
+	// is stopped if it loses the lock.
+	if cmd == resourceoperation.Deploy {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithCancel(ctx)
+		defer cancel()
+		orgLock, lockAcct, err := lockOrganization(ctx, consoleUI, orgClient, "deploy", cancel)
+		if err != nil {
+			return err
+		}
+		defer releaseOrgLock(consoleUI, lockAcct, orgLock)
 	}
 
-	mgmtAcct, err := resolveMgmtAcct(ctx, orgClient, rootAWSOU)
+	rootAWSOU, mgmtAcct, err := loadOrganization(ctx, consoleUI, orgClient)
 	if err != nil {
-		consoleUI.Print(fmt.Sprintf("Could not fetch AWS Management Account: %s", err), resource.Account{AccountID: "error", AccountName: "error"})
-		return oops.Wrapf(err, "resolveMgmtAcct")
+		return err
 	}
 
-	var deployStacks bool
-	var deploySCP bool
-	var deployOrganization bool
+	var newPlan *resourceoperation.Plan
+	if cmd == resourceoperation.Diff && planOut != "" {
+		newPlan = resourceoperation.NewPlan(planOut, targets, tag, stacks, accountFilter, allowDeleteAccount, pruneOUs)
+	}
 
-	for _, target := range targets {
-		if strings.ReplaceAll(target, " ", "") == "stacks" {
-			deployStacks = true
+	if savedPlan != nil || newPlan != nil {
+		fingerprint, err := resourceoperation.OrgFingerprint(ctx, orgClient, rootAWSOU, mgmtAcct)
+		if err != nil {
+			consoleUI.Print(fmt.Sprintf("Could not fingerprint AWS Organization: %s", err), *mgmtAcct)
+			return oops.Wrapf(err, "OrgFingerprint")
+		}
+		if newPlan != nil {
+			newPlan.OrgFingerprint = fingerprint
 		}
-		if strings.ReplaceAll(target, " ", "") == "scp" {
-			deploySCP = true
+		if savedPlan != nil && savedPlan.OrgFingerprint != fingerprint {
+			consoleUI.Print(fmt.Sprintf("The AWS Organization has changed since %s was created. Run diff again to create a new plan.", planFile), *mgmtAcct)
+			return oops.Errorf("organization changed since plan was created")
 		}
-		if strings.ReplaceAll(target, " ", "") == "organization" {
-			deployOrganization = true
+	}
+
+	deployStacks := includesTarget(targets, "stacks")
+	deploySCP := includesTarget(targets, "scp")
+	deployOrganization := includesTarget(targets, "organization")
+	scpAdmin := scpAdministrator(rootAWSOU, mgmtAcct)
+
+	// Deploys journal the result of every stack so that a failed deploy can
+	// be resumed.
+	var journal *resourceoperation.Journal
+	if cmd == resourceoperation.Deploy && deployStacks {
+		if resumeRunID != "" {
+			journal, err = resourceoperation.ResumeJournal(resumeRunID)
+		} else {
+			journal, err = resourceoperation.NewJournal()
+		}
+		if err != nil {
+			consoleUI.Print(fmt.Sprintf("Error opening run journal: %s", oops.Cause(err)), *mgmtAcct)
+			return oops.Wrapf(err, "Journal")
 		}
+		defer journal.Close()
+		consoleUI.Print(fmt.Sprintf("Run ID: %s", journal.RunID), *mgmtAcct)
 	}
 
-	// opsError is the error we return eventually. We want to allow partially
-	// applied operations across organizations, IaC, and SCPs so we only return
-	// this error in the end.
-	var opsError error
+	// errs are returned at the end of the run. We want to allow partially
+	// applied operations across organizations, IaC, and SCPs, unless
+	// --fail-fast is set.
+	errs := &runErrors{}
 
-	if len(targets) == 0 || deployOrganization {
-		orgOps := resourceoperation.CollectOrganizationUnitOps(
-			ctx, consoleUI, orgClient, mgmtAcct, rootAWSOU, cmd, allowDeleteAccount,
+	var orgOps []resourceoperation.ResourceOperation
+	if deployOrganization {
+		orgOps = resourceoperation.CollectOrganizationUnitOps(
+			ctx, consoleUI, orgClient, mgmtAcct, rootAWSOU, cmd, allowDeleteAccount, pruneOUs,
 		)
 		for _, op := range resourceoperation.FlattenOperations(orgOps) {
 			consoleUI.Print(op.ToString(), *mgmtAcct)
@@ -91,67 +133,193 @@ func ProcessOrgEndToEnd(consoleUI runner.ConsoleUI, cmd int, targets []string) e
 		if len(orgOps) == 0 {
 			consoleUI.Print("\033[32m No changes to AWS Organization. \033[0m", *mgmtAcct)
 		}
+	}
 
-		if cmd == resourceoperation.Deploy {
-			for _, op := range orgOps {
-				err := op.Call(ctx)
-				if err != nil {
-					consoleUI.Print(fmt.Sprintf("Error on AWS Organization Operation: %v", err), *mgmtAcct)
-					opsError = setOpsError()
-				}
-			}
+	// Without a plan, stacks and SCPs are collected after the organization
+	// is deployed so that they include newly created accounts. A plan only
+	// contains what existed when the diff ran, so everything is collected
+	// and matched against the plan before anything is applied.
+	var iacOps []accountOps
+	var scpOps []resourceoperation.ResourceOperation
+	if savedPlan != nil {
+		if deployStacks {
+			iacOps = collectIACOps(ctx, consoleUI, cmd, accountsToApply(rootAWSOU), errs)
+		}
+		if deploySCP {
+			scpOps = collectSCPOps(ctx, consoleUI, orgClient, cmd, rootAWSOU, scpAdmin, errs)
+		}
+		if errs.failed() {
+			return errs.err()
+		}
+		if err := savedPlan.Match(orgOps, flattenAccountOps(iacOps), scpOps); err != nil {
+			consoleUI.Print(fmt.Sprintf("Refusing to deploy %s: %s", planFile, oops.Cause(err)), *mgmtAcct)
+			return oops.Wrapf(err, "Match")
 		}
 	}
 
-	if len(targets) == 0 || deployStacks {
-		totalTags := strings.Split(tag, ",")
-		var accountsToApply []resource.Account
-		for _, acct := range rootAWSOU.AllDescendentAccounts() {
-			for _, tag := range totalTags {
-				if contains(tag, acct.AllTags()) || tag == "" {
-					accountsToApply = append(accountsToApply, *acct)
-				}
+	if cmd == resourceoperation.Deploy {
+		for _, op := range orgOps {
+			if ctx.Err() != nil || errs.stop() {
+				printResult(op, statusSkipped, nil)
+				continue
 			}
+			err := op.Call(ctx)
+			if err != nil && ctx.Err() != nil {
+				consoleUI.Print(fmt.Sprintf("Interrupted %s", operationLabel(op)), *mgmtAcct)
+				errs.add(operationLabel(op), oops.Wrapf(err, "interrupted"))
+				printResult(op, statusInterrupted, err)
+				continue
+			}
+			if err != nil {
+				consoleUI.Print(fmt.Sprintf("Error on AWS Organization Operation: %v", err), *mgmtAcct)
+				errs.add(operationLabel(op), err)
+				printResult(op, statusFailed, err)
+				continue
+			}
+			printResult(op, statusSucceeded, nil)
 		}
+	} else {
+		for _, op := range orgOps {
+			printResult(op, statusPlanned, nil)
+		}
+	}
 
-		if len(accountsToApply) == 0 {
+	if (deployStacks || deploySCP) && errs.stop() {
+		consoleUI.Print("Skipping stacks and Service Control Policies because an operation failed and --fail-fast is set.", *mgmtAcct)
+		deployStacks, deploySCP = false, false
+	}
+
+	if deployStacks {
+		accts := accountsToApply(rootAWSOU)
+		if len(accts) == 0 {
 			consoleUI.Print("No accounts to deploy.", *mgmtAcct)
 		}
 
-		err := runIAC(ctx, consoleUI, cmd, accountsToApply)
-		if err != nil {
-			consoleUI.Print("No accounts to deploy.", *mgmtAcct)
-			opsError = setOpsError()
+		if savedPlan == nil {
+			iacOps = collectIACOps(ctx, consoleUI, cmd, accts, errs)
+		}
+		if newPlan != nil {
+			resourceoperation.SetPlanDir(flattenAccountOps(iacOps), newPlan.ArtifactDir())
 		}
+
+		runIAC(ctx, consoleUI, cmd, iacOps, journal, errs)
 	}
 
-	if len(targets) == 0 || deploySCP {
-		// Telophasecli can be run from either the management account or
-		// the delegated administrator account.
-		var scpAdmin *resource.Account
-		delegatedAdmin := rootAWSOU.DelegatedAdministrator()
-		if delegatedAdmin != nil {
-			scpAdmin = delegatedAdmin
-		} else {
-			scpAdmin = mgmtAcct
-		}
+	if deploySCP && errs.stop() {
+		consoleUI.Print("Skipping Service Control Policies because an operation failed and --fail-fast is set.", *mgmtAcct)
+		deploySCP = false
+	}
 
-		scpOps := resourceoperation.CollectSCPOps(ctx, orgClient, consoleUI, cmd, rootAWSOU, scpAdmin)
-		for _, op := range scpOps {
-			err := op.Call(ctx)
-			if err != nil {
-				consoleUI.Print(fmt.Sprintf("Error on SCP Operation: %v", err), *scpAdmin)
-				opsError = setOpsError()
-			}
+	if deploySCP {
+		if savedPlan == nil {
+			scpOps = collectSCPOps(ctx, consoleUI, orgClient, cmd, rootAWSOU, scpAdmin, errs)
+		}
+		if newPlan != nil {
+			resourceoperation.SetPlanDir(scpOps, newPlan.ArtifactDir())
 		}
 
+		runSCPOps(ctx, consoleUI, scpAdmin, scpOps, errs)
+
 		if len(scpOps) == 0 {
 			consoleUI.Print("No Service Control Policies to deploy.", *scpAdmin)
 		}
 	}
 
+	if newPlan != nil {
+		if errs.failed() || ctx.Err() != nil {
+			consoleUI.Print(fmt.Sprintf("Not writing plan %s because the diff failed.", planOut), *mgmtAcct)
+		} else if err := newPlan.Write(orgOps, flattenAccountOps(iacOps), scpOps); err != nil {
+			consoleUI.Print(fmt.Sprintf("Error writing plan: %s", oops.Cause(err)), *mgmtAcct)
+			errs.add(fmt.Sprintf("plan %s", planOut), err)
+		} else {
+			consoleUI.Print(fmt.Sprintf("Saved plan to %s. Apply it with: telophasecli deploy --plan %s", planOut, planOut), *mgmtAcct)
+		}
+	}
+
+	if journal != nil && (errs.failed() || ctx.Err() != nil) {
+		consoleUI.Print(fmt.Sprintf("Resume the deploy with: telophasecli deploy --resume %s", journal.RunID), *mgmtAcct)
+	}
+
+	if ctx.Err() != nil {
+		consoleUI.Print("Interrupted. Operations that did not start were skipped, run the command again to finish them.\n", *mgmtAcct)
+		if err := errs.err(); err != nil {
+			return err
+		}
+		return oops.Wrapf(ctx.Err(), "interrupted")
+	}
+
 	consoleUI.Print("Done.\n", *mgmtAcct)
-	return opsError
+	return errs.err()
+}
+
+// accountsToApply returns the accounts matching the --tag and --accounts
+// filters.
+func accountsToApply(rootAWSOU *resource.OrganizationUnit) []resource.Account {
+	totalTags := strings.Split(tag, ",")
+	filteredAccounts := filterEmptyStrings(strings.Split(accountFilter, ","))
+	var accts []resource.Account
+	for _, acct := range rootAWSOU.AllDescendentAccounts() {
+		if len(filteredAccounts) > 0 && !contains(acct.AccountID, filteredAccounts) && !contains(acct.AccountName, filteredAccounts) {
+			continue
+		}
+		for _, tag := range totalTags {
+			if contains(tag, acct.AllTags()) || tag == "" {
+				accts = append(accts, *acct)
+			}
+		}
+	}
+	return accts
+}
+
+// loadOrganization parses organization.yml and resolves the management
+// account.
+func loadOrganization(
+	ctx context.Context,
+	consoleUI runner.ConsoleUI,
+	orgClient awsorgs.Client,
+) (*resource.OrganizationUnit, *resource.Account, error) {
+	rootAWSOU, err := ymlparser.NewParser(orgClient).ParseOrganization(ctx, orgFile)
+	if err != nil {
+		consoleUI.Print(fmt.Sprintf("error: %s", err), resource.Account{AccountID: "error", AccountName: "error"})
+		return nil, nil, oops.Wrapf(err, "ParseOrg")
+	}
+
+	if rootAWSOU == nil {
+		consoleUI.Print("Could not parse AWS Organization", resource.Account{AccountID: "error", AccountName: "error"})
+		return nil, nil, oops.Errorf("No root AWS OU")
+	}
+
+	mgmtAcct, err := resolveMgmtAcct(ctx, orgClient, rootAWSOU)
+	if err != nil {
+		consoleUI.Print(fmt.Sprintf("Could not fetch AWS Management Account: %s", err), resource.Account{AccountID: "error", AccountName: "error"})
+		return nil, nil, oops.Wrapf(err, "resolveMgmtAcct")
+	}
+
+	return rootAWSOU, mgmtAcct, nil
+}
+
+// scpAdministrator returns the account SCPs are managed from. Telophasecli can
+// be run from either the management account or the delegated administrator
+// account.
+func scpAdministrator(rootAWSOU *resource.OrganizationUnit, mgmtAcct *resource.Account) *resource.Account {
+	if delegatedAdmin := rootAWSOU.DelegatedAdministrator(); delegatedAdmin != nil {
+		return delegatedAdmin
+	}
+	return mgmtAcct
+}
+
+// includesTarget returns whether target is selected by --targets. No targets
+// selects everything.
+func includesTarget(targets []string, target string) bool {
+	if len(targets) == 0 {
+		return true
+	}
+	for _, t := range targets {
+		if strings.ReplaceAll(t, " ", "") == target {
+			return true
+		}
+	}
+	return false
 }
 
 func validateTargets() error {
@@ -0,0 +1,69 @@
+package runner
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// interruptWaitDelay is how long a command has to exit after it is
+// interrupted before it is killed.
+const interruptWaitDelay = 2 * time.Minute
+
+// Command returns a command that is sent SIGINT when ctx is canceled instead
+// of being killed, so that Terraform and CDK can release state locks and
+// clean up before they exit.
+//
+// The command runs in its own process group so that a Ctrl-C in the terminal
+// only reaches telophase, which forwards a single SIGINT by canceling ctx.
+// Terraform exits without cleaning up when it is interrupted twice.
+func Command(ctx context.Context, name string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, name, args...)
+	setProcessGroup(cmd)
+	cmd.Cancel = func() error {
+		return cmd.Process.Signal(os.Interrupt)
+	}
+	cmd.WaitDelay = interruptWaitDelay
+	return cmd
+}
+
+// running are the process IDs of the commands started by RunCmd that have not
+// exited, so that they can be killed if telophase exits without waiting for
+// them.
+var running = struct {
+	sync.Mutex
+	pids map[int]struct{}
+}{pids: map[int]struct{}{}}
+
+// startCmd starts cmd and tracks it until waitCmd returns.
+func startCmd(cmd *exec.Cmd) error {
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	running.Lock()
+	defer running.Unlock()
+	running.pids[cmd.Process.Pid] = struct{}{}
+	return nil
+}
+
+func waitCmd(cmd *exec.Cmd) error {
+	defer func() {
+		running.Lock()
+		defer running.Unlock()
+		delete(running.pids, cmd.Process.Pid)
+	}()
+	return cmd.Wait()
+}
+
+// KillRunning kills the running commands and the processes they started.
+// Commands run in their own process group, so they don't get the signals the
+// terminal sends to telophase and would keep running after it exits.
+func KillRunning() {
+	running.Lock()
+	defer running.Unlock()
+	for pid := range running.pids {
+		killProcessGroup(pid)
+	}
+}
@@ -0,0 +1,96 @@
+//go:build !windows
+
+package runner
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const interruptHelperEnv = "TELOPHASE_INTERRUPT_HELPER"
+
+// countInterrupts prints ready, waits for a SIGINT, gives a second SIGINT a
+// second to arrive and prints how many it received.
+const countInterrupts = `
+n=0
+trap 'n=$((n+1))' INT
+echo ready
+while [ $n -eq 0 ]; do sleep 0.05; done
+i=0
+while [ $i -lt 20 ]; do sleep 0.05; i=$((i+1)); done
+echo $n
+`
+
+func TestCommandInterruptedOnce(t *testing.T) {
+	if os.Getenv(interruptHelperEnv) != "" {
+		interruptHelper()
+		return
+	}
+
+	// The helper interrupts its own process group like a Ctrl-C in a
+	// terminal, so it runs in a new process group to not interrupt the test.
+	helper := exec.Command(os.Args[0], "-test.run=^TestCommandInterruptedOnce$")
+	helper.Env = append(os.Environ(), interruptHelperEnv+"=1")
+	helper.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	out, err := helper.CombinedOutput()
+	require.NoError(t, err, string(out))
+	assert.Contains(t, string(out), "interrupts=1\n")
+}
+
+// interruptHelper runs a command like telophase does: a SIGINT cancels the
+// context of the command.
+func interruptHelper() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cmd := Command(ctx, "sh", "-c", countInterrupts)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+	if err := cmd.Start(); err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+	reader := bufio.NewReader(stdout)
+	if _, err := reader.ReadString('\n'); err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+
+	syscall.Kill(0, syscall.SIGINT)
+	<-signals
+	// Signals that arrive together are counted once, so the context is
+	// canceled a bit later as it would be in telophase.
+	time.Sleep(200 * time.Millisecond)
+	cancel()
+
+	count, _ := reader.ReadString('\n')
+	cmd.Wait()
+	fmt.Printf("interrupts=%s\n", strings.TrimSpace(count))
+}
+
+func TestKillRunning(t *testing.T) {
+	cmd := Command(context.Background(), "sh", "-c", "sleep 30 & wait")
+	require.NoError(t, startCmd(cmd))
+
+	KillRunning()
+	err := waitCmd(cmd)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "killed")
+	assert.Empty(t, running.pids)
+}
@@ -0,0 +1,18 @@
+//go:build !windows
+
+package runner
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// killProcessGroup kills the process group of the command with pid, which is
+// its own process group.
+func killProcessGroup(pid int) {
+	syscall.Kill(-pid, syscall.SIGKILL)
+}
@@ -0,0 +1,16 @@
+package runner
+
+import (
+	"os"
+	"os/exec"
+)
+
+// setProcessGroup does nothing on Windows, where Ctrl-C is not delivered as a
+// signal to a process group.
+func setProcessGroup(cmd *exec.Cmd) {}
+
+func killProcessGroup(pid int) {
+	if process, err := os.FindProcess(pid); err == nil {
+		process.Kill()
+	}
+}
@@ -3,6 +3,8 @@ package runner
 import (
 	"bufio"
 	"fmt"
+	"io"
+	"os"
 	"os/exec"
 	"sync"
 
@@ -14,12 +16,24 @@ func NewSTDOut() ConsoleUI {
 	return &stdOut{
 		coloredId: make(map[string]string),
 		lock:      sync.Mutex{},
+		out:       os.Stdout,
+	}
+}
+
+// NewSTDErr prints to stderr so that stdout can be used for machine-readable
+// output.
+func NewSTDErr() ConsoleUI {
+	return &stdOut{
+		coloredId: make(map[string]string),
+		lock:      sync.Mutex{},
+		out:       os.Stderr,
 	}
 }
 
 type stdOut struct {
 	coloredId map[string]string
 	lock      sync.Mutex
+	out       io.Writer
 }
 
 func (s *stdOut) ColoredId(acct resource.Account) string {
@@ -54,7 +68,7 @@ func (s *stdOut) RunCmd(cmd *exec.Cmd, acct resource.Account) error {
 	}
 	stderrScanner := bufio.NewScanner(stderrPipe)
 
-	if err := cmd.Start(); err != nil {
+	if err := startCmd(cmd); err != nil {
 		return fmt.Errorf("[ERROR] %s %v", s.ColoredId(acct), err)
 	}
 
@@ -63,10 +77,10 @@ func (s *stdOut) RunCmd(cmd *exec.Cmd, acct resource.Account) error {
 	scanF := func(scanner *bufio.Scanner, _ string) {
 		defer scannerWg.Done()
 		for scanner.Scan() {
-			fmt.Printf("%s %s\n", s.ColoredId(acct), scanner.Text())
+			fmt.Fprintf(s.out, "%s %s\n", s.ColoredId(acct), scanner.Text())
 		}
 		if err := scanner.Err(); err != nil {
-			fmt.Printf("[ERROR] %s %v\n", s.ColoredId(acct), err)
+			fmt.Fprintf(s.out, "[ERROR] %s %v\n", s.ColoredId(acct), err)
 			return
 		}
 	}
@@ -75,7 +89,7 @@ func (s *stdOut) RunCmd(cmd *exec.Cmd, acct resource.Account) error {
 	go scanF(stderrScanner, "stderr")
 	scannerWg.Wait()
 
-	if err := cmd.Wait(); err != nil {
+	if err := waitCmd(cmd); err != nil {
 		return fmt.Errorf("[ERROR] %s %v", s.ColoredId(acct), err)
 	}
 
@@ -83,7 +97,7 @@ func (s *stdOut) RunCmd(cmd *exec.Cmd, acct resource.Account) error {
 }
 
 func (s *stdOut) Print(msg string, acct resource.Account) {
-	fmt.Printf("%s %v\n", s.ColoredId(acct), msg)
+	fmt.Fprintf(s.out, "%s %v\n", s.ColoredId(acct), msg)
 }
 
 func (s *stdOut) Start() {}
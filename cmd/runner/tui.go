@@ -106,11 +106,11 @@ func (t *tui) RunCmd(cmd *exec.Cmd, acct resource.Account) error {
 	cmd.Stderr = t.files[acctId]
 	cmd.Stdout = t.files[acctId]
 
-	if err := cmd.Start(); err != nil {
+	if err := startCmd(cmd); err != nil {
 		return err
 	}
 
-	if err := cmd.Wait(); err != nil {
+	if err := waitCmd(cmd); err != nil {
 		return err
 	}
 	return nil
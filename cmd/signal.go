@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/santiago-labs/telophasecli/cmd/runner"
+)
+
+// signalContext returns a context that is canceled on the first SIGINT or
+// SIGTERM so that running operations can stop cleanly. Terraform, CDK and the
+// other commands are interrupted and waited for, and Cloudformation change sets
+// created by the run are cleaned up. A second signal kills the running
+// commands and exits immediately.
+func signalContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case sig := <-signals:
+			fmt.Fprintf(os.Stderr, "\nReceived %s, waiting for running operations to stop. Interrupt again to exit immediately.\n", sig)
+			cancel()
+		case <-ctx.Done():
+			signal.Stop(signals)
+			return
+		}
+
+		// The commands run in their own process groups, so they are killed
+		// here instead of by the terminal.
+		sig := <-signals
+		fmt.Fprintf(os.Stderr, "\nReceived %s, killing running operations and exiting. Terraform state locks they held may have to be released with terraform force-unlock.\n", sig)
+		runner.KillRunning()
+		os.Exit(1)
+	}()
+
+	return ctx, cancel
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/samsarahq/go/oops"
+	"github.com/santiago-labs/telophasecli/cmd/runner"
+	"github.com/santiago-labs/telophasecli/lib/awsorgs"
+	"github.com/santiago-labs/telophasecli/lib/lock"
+	"github.com/santiago-labs/telophasecli/resource"
+	"github.com/spf13/cobra"
+)
+
+var forceUnlock bool
+
+func init() {
+	rootCmd.AddCommand(unlockCmd)
+	unlockCmd.Flags().StringVar(&lockTable, "lock-table", defaultLockTable(), "DynamoDB table the AWS Organization is locked in. Defaults to $TELOPHASE_LOCK_TABLE")
+	unlockCmd.Flags().BoolVar(&forceUnlock, "force", false, "Remove the lock even if another deploy holds it")
+}
+
+var unlockCmd = &cobra.Command{
+	Use:   "unlock",
+	Short: "unlock - Show or remove the lock on the AWS Organization taken by deploy and destroy.",
+	Run: func(cmd *cobra.Command, args []string) {
+		if lockTable == "" {
+			fmt.Fprintf(os.Stderr, "--lock-table or $%s is required\n", lock.TableEnv)
+			os.Exit(1)
+		}
+
+		if err := unlock(context.Background(), runner.NewSTDOut()); err != nil {
+			fmt.Fprintln(os.Stderr, oops.Cause(err))
+			os.Exit(1)
+		}
+	},
+}
+
+func unlock(ctx context.Context, consoleUI runner.ConsoleUI) error {
+	// organization.yml isn't parsed so that a lock can be released while it
+	// is broken.
+	mgmtAcct, err := awsorgs.New(nil).FetchManagementAccount(ctx)
+	if err != nil {
+		consoleUI.Print(fmt.Sprintf("Could not fetch AWS Management Account: %s", err), resource.Account{AccountID: "error", AccountName: "error"})
+		return oops.Wrapf(err, "FetchManagementAccount")
+	}
+	id, err := orgLockID(mgmtAcct)
+	if err != nil {
+		return err
+	}
+	locker, err := lock.NewDefault(lockTable, 0)
+	if err != nil {
+		return err
+	}
+
+	if !forceUnlock {
+		holder, err := locker.Get(ctx, id)
+		if err != nil {
+			return err
+		}
+		if holder == nil {
+			consoleUI.Print("The AWS Organization is not locked.", *mgmtAcct)
+			return nil
+		}
+		consoleUI.Print(fmt.Sprintf("The AWS Organization is locked by %s. Make sure that command is no longer running and remove the lock with --force.", holder), *mgmtAcct)
+		return nil
+	}
+
+	holder, err := locker.ForceUnlock(ctx, id)
+	if err != nil {
+		return err
+	}
+	if holder == nil {
+		consoleUI.Print("The AWS Organization is not locked.", *mgmtAcct)
+		return nil
+	}
+	consoleUI.Print(fmt.Sprintf("Removed the lock held by %s.", holder), *mgmtAcct)
+	return nil
+}
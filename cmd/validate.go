@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/santiago-labs/telophasecli/lib/ymlparser"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+	validateCmd.Flags().StringVar(&orgFile, "org", "organization.yml", "Path to the organization.yml file")
+}
+
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "validate - Validate organization.yml without calling AWS. Errors include the file and line of the problem.",
+	Run: func(cmd *cobra.Command, args []string) {
+		errs := ymlparser.ValidateOrganization(orgFile)
+		for _, err := range errs {
+			fmt.Fprintln(os.Stderr, err.Error())
+		}
+
+		if len(errs) > 0 {
+			fmt.Fprintf(os.Stderr, "\nFound %d error(s) in %s\n", len(errs), orgFile)
+			os.Exit(1)
+		}
+
+		fmt.Printf("%s is valid\n", orgFile)
+	},
+}
@@ -5,11 +5,15 @@
 // Account 1 - Child account
 // Account 2 - Another Child account
 // Account 3 - Orphan account within the organization.
+// Account 4 - Tagged delegated administrator account used to test imports.
 // Other methods are mocked, but don't perform any functions to avoid nil pointer exceptions.
+//
+// NewWithFailures injects errors, like throttling, into the mock.
 package awsorgsmock
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/request"
@@ -18,15 +22,45 @@ import (
 )
 
 func New() organizationsiface.OrganizationsAPI {
-	return &mockedOrganizations{}
+	return &mockedOrganizations{mu: &sync.Mutex{}}
+}
+
+// Failures are the errors methods return before the mock runs them, keyed by
+// method name. Each call removes the first error of the method so a method
+// succeeds once its errors are used up.
+type Failures map[string][]error
+
+// NewWithFailures returns the mock with failures injected. The errors left in
+// failures can be checked after the calls.
+func NewWithFailures(failures Failures) organizationsiface.OrganizationsAPI {
+	return &mockedOrganizations{mu: &sync.Mutex{}, failures: failures}
 }
 
 type mockedOrganizations struct {
 	organizationsiface.OrganizationsAPI
+
+	mu       *sync.Mutex
+	failures Failures
+}
+
+// fail returns the next injected error for method.
+func (m mockedOrganizations) fail(method string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	errs := m.failures[method]
+	if len(errs) == 0 {
+		return nil
+	}
+	m.failures[method] = errs[1:]
+	return errs[0]
 }
 
 // ListAccountsPagesWithContext mocks the ListAccountsPagesWithContext method
 func (m *mockedOrganizations) ListAccountsPagesWithContext(ctx aws.Context, input *organizations.ListAccountsInput, fn func(*organizations.ListAccountsOutput, bool) bool, opts ...request.Option) error {
+	if err := m.fail("ListAccounts"); err != nil {
+		return err
+	}
 	return m.ListAccountsPagesWithContextFunc(ctx, input, fn, opts...)
 }
 
@@ -60,6 +94,9 @@ func (m mockedOrganizations) DescribeOrganization(org *organizations.DescribeOrg
 }
 
 func (m mockedOrganizations) DescribeOrganizationWithContext(ctx aws.Context, org *organizations.DescribeOrganizationInput, opts ...request.Option) (*organizations.DescribeOrganizationOutput, error) {
+	if err := m.fail("DescribeOrganization"); err != nil {
+		return nil, err
+	}
 	return &organizations.DescribeOrganizationOutput{
 		Organization: &organizations.Organization{
 			MasterAccountId: mockAccount(0).Id,
@@ -68,6 +105,9 @@ func (m mockedOrganizations) DescribeOrganizationWithContext(ctx aws.Context, or
 }
 
 func (m mockedOrganizations) ListRoots(org *organizations.ListRootsInput) (*organizations.ListRootsOutput, error) {
+	if err := m.fail("ListRoots"); err != nil {
+		return nil, err
+	}
 	return &organizations.ListRootsOutput{
 		Roots: []*organizations.Root{
 			{
@@ -108,11 +148,99 @@ func (m *mockedOrganizations) ListOrganizationalUnitsForParentPagesWithContextFu
 }
 
 func (m *mockedOrganizations) ListTagsForResourcePagesWithContext(ctx aws.Context, input *organizations.ListTagsForResourceInput, fn func(*organizations.ListTagsForResourceOutput, bool) bool, opts ...request.Option) error {
+	if err := m.fail("ListTagsForResource"); err != nil {
+		return err
+	}
 	return m.ListTagsForResourcePagesWithContextFunc(ctx, input, fn, opts...)
 }
 
 func (m *mockedOrganizations) ListTagsForResourcePagesWithContextFunc(ctx aws.Context, input *organizations.ListTagsForResourceInput, fn func(*organizations.ListTagsForResourceOutput, bool) bool, opts ...request.Option) error {
-	fn(&organizations.ListTagsForResourceOutput{}, true)
+	var tags []*organizations.Tag
+	switch aws.StringValue(input.ResourceId) {
+	case "1ou":
+		tags = []*organizations.Tag{
+			{Key: aws.String("ou"), Value: aws.String("ExampleTenants")},
+		}
+	case *mockAccount(4).Id:
+		tags = []*organizations.Tag{
+			{Key: aws.String("AccountName"), Value: mockAccount(4).Name},
+			{Key: aws.String("TelophaseManaged"), Value: aws.String("true")},
+			{Key: aws.String("ou"), Value: aws.String("ExampleTenants")},
+			{Key: aws.String("team"), Value: aws.String("data")},
+		}
+	}
+	fn(&organizations.ListTagsForResourceOutput{Tags: tags}, true)
+
+	return nil
+}
+
+func (m *mockedOrganizations) ListDelegatedAdministratorsPagesWithContext(ctx aws.Context, input *organizations.ListDelegatedAdministratorsInput, fn func(*organizations.ListDelegatedAdministratorsOutput, bool) bool, opts ...request.Option) error {
+	fn(&organizations.ListDelegatedAdministratorsOutput{
+		DelegatedAdministrators: []*organizations.DelegatedAdministrator{
+			{Id: mockAccount(4).Id},
+		},
+	}, true)
+
+	return nil
+}
+
+func (m *mockedOrganizations) ListDelegatedServicesForAccountPagesWithContext(ctx aws.Context, input *organizations.ListDelegatedServicesForAccountInput, fn func(*organizations.ListDelegatedServicesForAccountOutput, bool) bool, opts ...request.Option) error {
+	var services []*organizations.DelegatedService
+	if aws.StringValue(input.AccountId) == *mockAccount(4).Id {
+		services = []*organizations.DelegatedService{
+			{ServicePrincipal: aws.String("guardduty.amazonaws.com")},
+		}
+	}
+	fn(&organizations.ListDelegatedServicesForAccountOutput{DelegatedServices: services}, true)
 
 	return nil
 }
+
+func (m *mockedOrganizations) ListPoliciesForTargetPagesWithContext(ctx aws.Context, input *organizations.ListPoliciesForTargetInput, fn func(*organizations.ListPoliciesForTargetOutput, bool) bool, opts ...request.Option) error {
+	policies := []*organizations.PolicySummary{
+		{Id: aws.String("p-FullAWSAccess"), Name: aws.String("FullAWSAccess"), AwsManaged: aws.Bool(true)},
+	}
+	if aws.StringValue(input.TargetId) == "1ou" {
+		policies = append(policies, &organizations.PolicySummary{
+			Id: aws.String("p-denyregions"), Name: aws.String("DenyRegions"), AwsManaged: aws.Bool(false),
+		})
+	}
+	fn(&organizations.ListPoliciesForTargetOutput{Policies: policies}, true)
+
+	return nil
+}
+
+func (m *mockedOrganizations) TagResourceWithContext(ctx aws.Context, input *organizations.TagResourceInput, opts ...request.Option) (*organizations.TagResourceOutput, error) {
+	if err := m.fail("TagResource"); err != nil {
+		return nil, err
+	}
+	return &organizations.TagResourceOutput{}, nil
+}
+
+func (m *mockedOrganizations) CreateAccountWithContext(ctx aws.Context, input *organizations.CreateAccountInput, opts ...request.Option) (*organizations.CreateAccountOutput, error) {
+	if err := m.fail("CreateAccount"); err != nil {
+		return nil, err
+	}
+	return &organizations.CreateAccountOutput{
+		CreateAccountStatus: &organizations.CreateAccountStatus{
+			Id:          aws.String("car-5"),
+			AccountName: input.AccountName,
+			State:       aws.String(organizations.CreateAccountStateInProgress),
+		},
+	}, nil
+}
+
+// DescribeCreateAccountStatusWithContext reports every account as created.
+func (m *mockedOrganizations) DescribeCreateAccountStatusWithContext(ctx aws.Context, input *organizations.DescribeCreateAccountStatusInput, opts ...request.Option) (*organizations.DescribeCreateAccountStatusOutput, error) {
+	if err := m.fail("DescribeCreateAccountStatus"); err != nil {
+		return nil, err
+	}
+	return &organizations.DescribeCreateAccountStatusOutput{
+		CreateAccountStatus: &organizations.CreateAccountStatus{
+			Id:          input.CreateAccountRequestId,
+			AccountName: aws.String("test5"),
+			AccountId:   aws.String("50000000000"),
+			State:       aws.String(organizations.CreateAccountStateSucceeded),
+		},
+	}, nil
+}
@@ -3,6 +3,7 @@ package awsorgs
 import (
 	"context"
 	"fmt"
+	"os"
 	"strings"
 	"time"
 
@@ -20,17 +21,26 @@ import (
 
 type Client struct {
 	organizationClient organizationsiface.OrganizationsAPI
+	retry              RetryPolicy
 }
 
 type Config struct {
 	OrganizationClient organizationsiface.OrganizationsAPI
+	// RetryPolicy defaults to DefaultRetryPolicy.
+	RetryPolicy *RetryPolicy
 }
 
 func New(cfg *Config) Client {
+	retry := DefaultRetryPolicy
+	if cfg != nil && cfg.RetryPolicy != nil {
+		retry = *cfg.RetryPolicy
+	}
+
 	if cfg != nil {
 		if cfg.OrganizationClient != nil {
 			return Client{
 				organizationClient: cfg.OrganizationClient,
+				retry:              retry,
 			}
 		}
 	}
@@ -44,13 +54,14 @@ func New(cfg *Config) Client {
 		if awsErr, ok := err.(awserr.Error); ok {
 			switch awsErr.Code() {
 			case "UnrecognizedClientException", "InvalidClientTokenId", "AccessDenied":
-				fmt.Println("Error fetching caller identity. Ensure your awscli credentials are valid.\nError:", awsErr.Message())
+				fmt.Fprintln(os.Stderr, "Error fetching caller identity. Ensure your awscli credentials are valid.\nError:", awsErr.Message())
 				panic(err)
 			}
 		}
 	}
 	return Client{
 		organizationClient: orgsClient,
+		retry:              retry,
 	}
 }
 
@@ -58,12 +69,15 @@ func New(cfg *Config) Client {
 func (c Client) CurrentAccounts(ctx context.Context) ([]*organizations.Account, error) {
 	var accounts []*organizations.Account
 
-	err := c.organizationClient.ListAccountsPagesWithContext(ctx, &organizations.ListAccountsInput{},
-		func(page *organizations.ListAccountsOutput, lastPage bool) bool {
-			accounts = append(accounts, page.Accounts...)
-			return !lastPage
-		},
-	)
+	err := c.retry.Do(ctx, func() error {
+		accounts = nil
+		return c.organizationClient.ListAccountsPagesWithContext(ctx, &organizations.ListAccountsInput{},
+			func(page *organizations.ListAccountsOutput, lastPage bool) bool {
+				accounts = append(accounts, page.Accounts...)
+				return !lastPage
+			},
+		)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("ListAccounts: are you using the right AWS role? err: %s", err)
 	}
@@ -72,7 +86,12 @@ func (c Client) CurrentAccounts(ctx context.Context) ([]*organizations.Account,
 }
 
 func (c Client) FetchManagementAccount(ctx context.Context) (*resource.Account, error) {
-	org, err := c.organizationClient.DescribeOrganization(&organizations.DescribeOrganizationInput{})
+	var org *organizations.DescribeOrganizationOutput
+	err := c.retry.Do(ctx, func() error {
+		var err error
+		org, err = c.organizationClient.DescribeOrganizationWithContext(ctx, &organizations.DescribeOrganizationInput{})
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("DescribeOrganization: %s", err)
 	}
@@ -109,14 +128,17 @@ func (c Client) FetchManagementAccount(ctx context.Context) (*resource.Account,
 func (c Client) CurrentAccountsForParent(ctx context.Context, parentID string) ([]*organizations.Account, error) {
 	var accounts []*organizations.Account
 
-	err := c.organizationClient.ListAccountsForParentPagesWithContext(ctx, &organizations.ListAccountsForParentInput{
-		ParentId: &parentID,
-	},
-		func(page *organizations.ListAccountsForParentOutput, lastPage bool) bool {
-			accounts = append(accounts, page.Accounts...)
-			return !lastPage
+	err := c.retry.Do(ctx, func() error {
+		accounts = nil
+		return c.organizationClient.ListAccountsForParentPagesWithContext(ctx, &organizations.ListAccountsForParentInput{
+			ParentId: &parentID,
 		},
-	)
+			func(page *organizations.ListAccountsForParentOutput, lastPage bool) bool {
+				accounts = append(accounts, page.Accounts...)
+				return !lastPage
+			},
+		)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("ListAccounts: are you using the right AWS role? err: %s", err)
 	}
@@ -127,14 +149,17 @@ func (c Client) CurrentAccountsForParent(ctx context.Context, parentID string) (
 func (c Client) CurrentOUsForParent(ctx context.Context, parentID string) ([]*organizations.OrganizationalUnit, error) {
 	var accounts []*organizations.OrganizationalUnit
 
-	err := c.organizationClient.ListOrganizationalUnitsForParentPagesWithContext(ctx, &organizations.ListOrganizationalUnitsForParentInput{
-		ParentId: &parentID,
-	},
-		func(page *organizations.ListOrganizationalUnitsForParentOutput, lastPage bool) bool {
-			accounts = append(accounts, page.OrganizationalUnits...)
-			return !lastPage
+	err := c.retry.Do(ctx, func() error {
+		accounts = nil
+		return c.organizationClient.ListOrganizationalUnitsForParentPagesWithContext(ctx, &organizations.ListOrganizationalUnitsForParentInput{
+			ParentId: &parentID,
 		},
-	)
+			func(page *organizations.ListOrganizationalUnitsForParentOutput, lastPage bool) bool {
+				accounts = append(accounts, page.OrganizationalUnits...)
+				return !lastPage
+			},
+		)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -143,8 +168,13 @@ func (c Client) CurrentOUsForParent(ctx context.Context, parentID string) ([]*or
 }
 
 func (c Client) GetOrganizationUnit(ctx context.Context, OUId string) (*organizations.OrganizationalUnit, error) {
-	out, err := c.organizationClient.DescribeOrganizationalUnitWithContext(ctx, &organizations.DescribeOrganizationalUnitInput{
-		OrganizationalUnitId: &OUId,
+	var out *organizations.DescribeOrganizationalUnitOutput
+	err := c.retry.Do(ctx, func() error {
+		var err error
+		out, err = c.organizationClient.DescribeOrganizationalUnitWithContext(ctx, &organizations.DescribeOrganizationalUnitInput{
+			OrganizationalUnitId: &OUId,
+		})
+		return err
 	})
 	if err != nil {
 		return nil, fmt.Errorf("GetOrganizationalUnit: %s", err)
@@ -155,20 +185,23 @@ func (c Client) GetOrganizationUnit(ctx context.Context, OUId string) (*organiza
 
 func (c Client) GetTags(ctx context.Context, id string) ([]string, error) {
 	var tags []string
-	err := c.organizationClient.ListTagsForResourcePagesWithContext(ctx, &organizations.ListTagsForResourceInput{
-		ResourceId: &id,
-	},
-		func(page *organizations.ListTagsForResourceOutput, lastPage bool) bool {
-			for _, tag := range page.Tags {
-				if aws.StringValue(tag.Value) != "" {
-					tags = append(tags, aws.StringValue(tag.Key)+"="+aws.StringValue(tag.Value))
-				} else {
-					tags = append(tags, aws.StringValue(tag.Key))
-				}
-			}
-			return !lastPage
+	err := c.retry.Do(ctx, func() error {
+		tags = nil
+		return c.organizationClient.ListTagsForResourcePagesWithContext(ctx, &organizations.ListTagsForResourceInput{
+			ResourceId: &id,
 		},
-	)
+			func(page *organizations.ListTagsForResourceOutput, lastPage bool) bool {
+				for _, tag := range page.Tags {
+					if aws.StringValue(tag.Value) != "" {
+						tags = append(tags, aws.StringValue(tag.Key)+"="+aws.StringValue(tag.Value))
+					} else {
+						tags = append(tags, aws.StringValue(tag.Key))
+					}
+				}
+				return !lastPage
+			},
+		)
+	})
 	if err != nil {
 		return nil, oops.Wrapf(err, "listing tags for id: %s", id)
 	}
@@ -178,14 +211,17 @@ func (c Client) GetTags(ctx context.Context, id string) ([]string, error) {
 func (c Client) GetOrganizationUnitChildren(ctx context.Context, OUId string) ([]*organizations.OrganizationalUnit, error) {
 	var childOUs []*organizations.OrganizationalUnit
 
-	err := c.organizationClient.ListOrganizationalUnitsForParentPagesWithContext(ctx, &organizations.ListOrganizationalUnitsForParentInput{
-		ParentId: &OUId,
-	},
-		func(page *organizations.ListOrganizationalUnitsForParentOutput, lastPage bool) bool {
-			childOUs = append(childOUs, page.OrganizationalUnits...)
-			return !lastPage
+	err := c.retry.Do(ctx, func() error {
+		childOUs = nil
+		return c.organizationClient.ListOrganizationalUnitsForParentPagesWithContext(ctx, &organizations.ListOrganizationalUnitsForParentInput{
+			ParentId: &OUId,
 		},
-	)
+			func(page *organizations.ListOrganizationalUnitsForParentOutput, lastPage bool) bool {
+				childOUs = append(childOUs, page.OrganizationalUnits...)
+				return !lastPage
+			},
+		)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("GetOrganizationUnitChildren: %s", err)
 	}
@@ -203,10 +239,13 @@ func (c Client) MoveAccount(
 		return nil
 	}
 	consoleUI.Print(fmt.Sprintf("Moving Account: %s Old Parent: %s New Parent: %s\n", acctId, oldParentId, newParentId), mgmtAcct)
-	_, err := c.organizationClient.MoveAccountWithContext(ctx, &organizations.MoveAccountInput{
-		AccountId:           &acctId,
-		DestinationParentId: &newParentId,
-		SourceParentId:      &oldParentId,
+	err := c.retry.Do(ctx, func() error {
+		_, err := c.organizationClient.MoveAccountWithContext(ctx, &organizations.MoveAccountInput{
+			AccountId:           &acctId,
+			DestinationParentId: &newParentId,
+			SourceParentId:      &oldParentId,
+		})
+		return err
 	})
 
 	if err == nil {
@@ -226,10 +265,15 @@ func (c Client) CreateOrganizationUnit(
 	tags []string,
 ) (*organizations.OrganizationalUnit, error) {
 	consoleUI.Print(fmt.Sprintf("Creating OU: Name=%s\n", ouName), mgmtAcct)
-	out, err := c.organizationClient.CreateOrganizationalUnitWithContext(ctx, &organizations.CreateOrganizationalUnitInput{
-		Name:     &ouName,
-		ParentId: &newParentId,
-		Tags:     buildTags(tags),
+	var out *organizations.CreateOrganizationalUnitOutput
+	err := c.retry.forCreate().Do(ctx, func() error {
+		var err error
+		out, err = c.organizationClient.CreateOrganizationalUnitWithContext(ctx, &organizations.CreateOrganizationalUnitInput{
+			Name:     &ouName,
+			ParentId: &newParentId,
+			Tags:     buildTags(tags),
+		})
+		return err
 	})
 	if err != nil {
 		return nil, err
@@ -238,51 +282,180 @@ func (c Client) CreateOrganizationUnit(
 	return out.OrganizationalUnit, nil
 }
 
+// RecreateOU moves the OU ouID under newParentId. AWS can't move an OU, so a
+// new OU is created under the new parent with the tags and policies of the old
+// OU, the accounts and child OUs are moved to it, and the emptied old OU is
+// deleted. It returns the IDs of the new OUs, including the recreated child
+// OUs, keyed by the IDs of the OUs they replace.
 func (c Client) RecreateOU(
 	ctx context.Context,
 	consoleUI runner.ConsoleUI,
 	mgmtAcct resource.Account,
 	ouID, ouName, newParentId string,
-	tags []string,
-) error {
+) (map[string]string, error) {
+	tags, err := c.GetTags(ctx, ouID)
+	if err != nil {
+		return nil, err
+	}
+
 	newOU, err := c.CreateOrganizationUnit(ctx, consoleUI, mgmtAcct, ouName, newParentId, tags)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	newIDs := map[string]string{ouID: *newOU.Id}
+
+	// Policies are copied before the accounts are moved so that the accounts
+	// are never without them.
+	if err := c.copyPolicies(ctx, consoleUI, mgmtAcct, ouID, *newOU.Id); err != nil {
+		return nil, err
 	}
 
-	childAccounts, err := c.ListAccountsForParent(ouID)
+	childAccounts, err := c.CurrentAccountsForParent(ctx, ouID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	for _, acct := range childAccounts {
 		err := c.MoveAccount(ctx, consoleUI, mgmtAcct, *acct.Id, ouID, *newOU.Id)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 
-	childOUs, err := c.ListOrganizationalUnits(ouID)
+	childOUs, err := c.GetOrganizationUnitChildren(ctx, ouID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	for _, childOU := range childOUs {
-		err := c.RecreateOU(ctx, consoleUI, mgmtAcct, *childOU.Id, *childOU.Name, *newOU.Id, tags)
+		childIDs, err := c.RecreateOU(ctx, consoleUI, mgmtAcct, *childOU.Id, *childOU.Name, *newOU.Id)
+		if err != nil {
+			return nil, err
+		}
+		for oldID, newID := range childIDs {
+			newIDs[oldID] = newID
+		}
+	}
+
+	if err := c.DeleteOrganizationUnit(ctx, consoleUI, mgmtAcct, ouID); err != nil {
+		return nil, err
+	}
+	consoleUI.Print(fmt.Sprintf("Moved OU: Name=%s Old ID=%s New ID=%s\n", ouName, ouID, *newOU.Id), mgmtAcct)
+
+	return newIDs, nil
+}
+
+// copyPolicies attaches the policies attached to fromID to toID, for every
+// policy type enabled in the organization. Policies AWS attached to toID that
+// fromID doesn't have, like FullAWSAccess, are detached.
+func (c Client) copyPolicies(
+	ctx context.Context,
+	consoleUI runner.ConsoleUI,
+	mgmtAcct resource.Account,
+	fromID, toID string,
+) error {
+	policyTypes, err := c.enabledPolicyTypes(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, policyType := range policyTypes {
+		from, err := c.listPolicies(ctx, fromID, policyType)
+		if err != nil {
+			return err
+		}
+		to, err := c.listPolicies(ctx, toID, policyType)
 		if err != nil {
 			return err
 		}
+
+		// Attaching first keeps at least one Service Control Policy attached
+		// as AWS requires.
+		for _, policy := range from {
+			if hasPolicy(to, *policy.Id) {
+				continue
+			}
+			consoleUI.Print(fmt.Sprintf("Attaching policy %s to OU %s\n", aws.StringValue(policy.Name), toID), mgmtAcct)
+			if err := c.AttachPolicy(ctx, *policy.Id, toID); err != nil {
+				return err
+			}
+		}
+
+		for _, policy := range to {
+			if hasPolicy(from, *policy.Id) {
+				continue
+			}
+			consoleUI.Print(fmt.Sprintf("Detaching policy %s from OU %s\n", aws.StringValue(policy.Name), toID), mgmtAcct)
+			if err := c.DetachPolicy(ctx, *policy.Id, toID); err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
 }
 
-func (c Client) UpdateOrganizationUnit(ctx context.Context, ouID, newName string) error {
-	_, err := c.organizationClient.UpdateOrganizationalUnitWithContext(ctx,
-		&organizations.UpdateOrganizationalUnitInput{
-			Name:                 aws.String(newName),
-			OrganizationalUnitId: aws.String(ouID),
+func hasPolicy(policies []*organizations.PolicySummary, policyID string) bool {
+	for _, policy := range policies {
+		if aws.StringValue(policy.Id) == policyID {
+			return true
+		}
+	}
+	return false
+}
+
+// enabledPolicyTypes returns the policy types enabled on the root of the
+// organization.
+func (c Client) enabledPolicyTypes(ctx context.Context) ([]string, error) {
+	var rootsOutput *organizations.ListRootsOutput
+	err := c.retry.Do(ctx, func() error {
+		var err error
+		rootsOutput, err = c.organizationClient.ListRoots(&organizations.ListRootsInput{})
+		return err
+	})
+	if err != nil {
+		return nil, oops.Wrapf(err, "organizations.ListRoots")
+	}
+
+	var policyTypes []string
+	for _, root := range rootsOutput.Roots {
+		for _, policyType := range root.PolicyTypes {
+			if aws.StringValue(policyType.Status) == organizations.PolicyTypeStatusEnabled {
+				policyTypes = append(policyTypes, aws.StringValue(policyType.Type))
+			}
+		}
+	}
+	return policyTypes, nil
+}
+
+// DeleteOrganizationUnit deletes the OU ouID. The OU must not have accounts or
+// child OUs.
+func (c Client) DeleteOrganizationUnit(
+	ctx context.Context,
+	consoleUI runner.ConsoleUI,
+	mgmtAcct resource.Account,
+	ouID string,
+) error {
+	consoleUI.Print(fmt.Sprintf("Deleting OU: %s\n", ouID), mgmtAcct)
+	err := c.retry.Do(ctx, func() error {
+		_, err := c.organizationClient.DeleteOrganizationalUnitWithContext(ctx, &organizations.DeleteOrganizationalUnitInput{
+			OrganizationalUnitId: &ouID,
 		})
+		return err
+	})
+	if err != nil {
+		return oops.Wrapf(err, "organizations.DeleteOrganizationalUnit %s", ouID)
+	}
+	return nil
+}
 
-	return err
+func (c Client) UpdateOrganizationUnit(ctx context.Context, ouID, newName string) error {
+	return c.retry.Do(ctx, func() error {
+		_, err := c.organizationClient.UpdateOrganizationalUnitWithContext(ctx,
+			&organizations.UpdateOrganizationalUnitInput{
+				Name:                 aws.String(newName),
+				OrganizationalUnitId: aws.String(ouID),
+			})
+		return err
+	})
 }
 
 func buildTags(tags []string) []*organizations.Tag {
@@ -305,11 +478,14 @@ func buildTags(tags []string) []*organizations.Tag {
 }
 
 func (c Client) TagResource(ctx context.Context, id string, tags []string) error {
-	_, err := c.organizationClient.TagResourceWithContext(ctx,
-		&organizations.TagResourceInput{
-			ResourceId: aws.String(id),
-			Tags:       buildTags(tags),
-		})
+	err := c.retry.Do(ctx, func() error {
+		_, err := c.organizationClient.TagResourceWithContext(ctx,
+			&organizations.TagResourceInput{
+				ResourceId: aws.String(id),
+				Tags:       buildTags(tags),
+			})
+		return err
+	})
 	if err != nil {
 		return oops.Wrapf(err, "tagging: %s", id)
 	}
@@ -327,44 +503,66 @@ func (c Client) UntagResources(ctx context.Context, id string, tags []string) er
 		tagKeys = append(tagKeys, &key)
 	}
 
-	_, err := c.organizationClient.UntagResourceWithContext(ctx,
-		&organizations.UntagResourceInput{
-			ResourceId: aws.String(id),
-			TagKeys:    tagKeys,
-		})
+	err := c.retry.Do(ctx, func() error {
+		_, err := c.organizationClient.UntagResourceWithContext(ctx,
+			&organizations.UntagResourceInput{
+				ResourceId: aws.String(id),
+				TagKeys:    tagKeys,
+			})
+		return err
+	})
 	if err != nil {
 		return oops.Wrapf(err, "tagging: %s", id)
 	}
 	return nil
 }
 
+// CreateAccount creates acct with its role and tags and waits until AWS has
+// created it. It returns the ID of the new account.
 func (c Client) CreateAccount(
 	ctx context.Context,
 	consoleUI runner.ConsoleUI,
 	mgmtAcct resource.Account,
-	acct *organizations.Account,
-	tags []string,
+	acct resource.Account,
 ) (string, error) {
-	consoleUI.Print(fmt.Sprintf("Creating Account: Name=%s Email=%s\n", *acct.Name, *acct.Email), mgmtAcct)
-	out, err := c.organizationClient.CreateAccount(&organizations.CreateAccountInput{
-		AccountName: acct.Name,
-		Email:       acct.Email,
-		Tags:        buildTags(tags),
+	consoleUI.Print(fmt.Sprintf("Creating Account: Name=%s Email=%s\n", acct.AccountName, acct.Email), mgmtAcct)
+	input := &organizations.CreateAccountInput{
+		AccountName: aws.String(acct.AccountName),
+		Email:       aws.String(acct.Email),
+		RoleName:    aws.String(acct.RoleName()),
+		Tags:        buildTags(acct.AllTags()),
+	}
+	if acct.IamUserAccessToBilling != nil {
+		input.IamUserAccessToBilling = aws.String(organizations.IAMUserAccessToBillingDeny)
+		if *acct.IamUserAccessToBilling {
+			input.IamUserAccessToBilling = aws.String(organizations.IAMUserAccessToBillingAllow)
+		}
+	}
+
+	var out *organizations.CreateAccountOutput
+	err := c.retry.forCreate().Do(ctx, func() error {
+		var err error
+		out, err = c.organizationClient.CreateAccountWithContext(ctx, input)
+		return err
 	})
 	if err != nil {
 		consoleUI.Print(fmt.Sprintf("Error creating account: %s.\n", err.Error()), mgmtAcct)
 		return "", err
 	}
 
+	requestId := *out.CreateAccountStatus.Id
 	for {
-
-		requestId := *out.CreateAccountStatus.Id
-		currStatus, err := c.organizationClient.DescribeCreateAccountStatus(&organizations.DescribeCreateAccountStatusInput{
-			CreateAccountRequestId: &requestId,
+		var currStatus *organizations.DescribeCreateAccountStatusOutput
+		err := c.retry.Do(ctx, func() error {
+			var err error
+			currStatus, err = c.organizationClient.DescribeCreateAccountStatusWithContext(ctx, &organizations.DescribeCreateAccountStatusInput{
+				CreateAccountRequestId: &requestId,
+			})
+			return err
 		})
 		if err != nil {
 			consoleUI.Print(fmt.Sprintf("Error fetching create status: %s\n", err), mgmtAcct)
-			continue
+			return "", oops.Wrapf(err, "DescribeCreateAccountStatus")
 		}
 
 		state := *currStatus.CreateAccountStatus.State
@@ -375,7 +573,7 @@ func (c Client) CreateAccount(
 			consoleUI.Print(fmt.Sprintf("Still creating %s...\n", accountName), mgmtAcct)
 		case "FAILED":
 			consoleUI.Print(fmt.Sprintf("Failed to create account %s. Error: %s\n", accountName, *currStatus.CreateAccountStatus.FailureReason), mgmtAcct)
-			return "", err
+			return "", fmt.Errorf("failed to create account %s: %s", accountName, *currStatus.CreateAccountStatus.FailureReason)
 
 		case "SUCCEEDED":
 			consoleUI.Print(fmt.Sprintf("Successfully created account %s.\n", accountName), mgmtAcct)
@@ -384,14 +582,22 @@ func (c Client) CreateAccount(
 			return "", fmt.Errorf("unexpected state: %s", state)
 		}
 
-		time.Sleep(5 * time.Second)
+		select {
+		case <-ctx.Done():
+			consoleUI.Print(fmt.Sprintf("Interrupted, AWS is still creating account %s.\n", accountName), mgmtAcct)
+			return "", ctx.Err()
+		case <-time.After(5 * time.Second):
+		}
 	}
 }
 
 func (c Client) CloseAccount(ctx context.Context, acctID, acctName, acctEmail string) error {
-	fmt.Printf("Closing Account: %s Email: %s\n", acctName, acctEmail)
-	_, err := c.organizationClient.CloseAccountWithContext(ctx, &organizations.CloseAccountInput{
-		AccountId: &acctID,
+	fmt.Fprintf(os.Stderr, "Closing Account: %s Email: %s\n", acctName, acctEmail)
+	err := c.retry.Do(ctx, func() error {
+		_, err := c.organizationClient.CloseAccountWithContext(ctx, &organizations.CloseAccountInput{
+			AccountId: &acctID,
+		})
+		return err
 	})
 	if err != nil {
 		return oops.Wrapf(err, "closing account")
@@ -401,7 +607,12 @@ func (c Client) CloseAccount(ctx context.Context, acctID, acctName, acctEmail st
 }
 
 func (c Client) GetRootId() (string, error) {
-	rootsOutput, err := c.organizationClient.ListRoots(&organizations.ListRootsInput{})
+	var rootsOutput *organizations.ListRootsOutput
+	err := c.retry.Do(context.Background(), func() error {
+		var err error
+		rootsOutput, err = c.organizationClient.ListRoots(&organizations.ListRootsInput{})
+		return err
+	})
 	if err != nil {
 		return "", oops.Wrapf(err, "organizations.ListRootsInput, make sure you have access to organizations from this role")
 	}
@@ -413,37 +624,50 @@ func (c Client) GetRootId() (string, error) {
 
 func (c Client) ListOrganizationalUnits(parentID string) ([]*organizations.OrganizationalUnit, error) {
 	var OUs []*organizations.OrganizationalUnit
-	err := c.organizationClient.ListOrganizationalUnitsForParentPages(&organizations.ListOrganizationalUnitsForParentInput{
-		ParentId: &parentID,
-	}, func(page *organizations.ListOrganizationalUnitsForParentOutput, lastPage bool) bool {
-		OUs = append(OUs, page.OrganizationalUnits...)
-		return !lastPage
+	err := c.retry.Do(context.Background(), func() error {
+		OUs = nil
+		return c.organizationClient.ListOrganizationalUnitsForParentPages(&organizations.ListOrganizationalUnitsForParentInput{
+			ParentId: &parentID,
+		}, func(page *organizations.ListOrganizationalUnitsForParentOutput, lastPage bool) bool {
+			OUs = append(OUs, page.OrganizationalUnits...)
+			return !lastPage
+		})
 	})
 	return OUs, err
 }
 
 func (c Client) ListAccountsForParent(parentID string) ([]*organizations.Account, error) {
 	var accounts []*organizations.Account
-	err := c.organizationClient.ListAccountsForParentPages(&organizations.ListAccountsForParentInput{
-		ParentId: &parentID,
-	}, func(page *organizations.ListAccountsForParentOutput, lastPage bool) bool {
-		accounts = append(accounts, page.Accounts...)
-		return !lastPage
+	err := c.retry.Do(context.Background(), func() error {
+		accounts = nil
+		return c.organizationClient.ListAccountsForParentPages(&organizations.ListAccountsForParentInput{
+			ParentId: &parentID,
+		}, func(page *organizations.ListAccountsForParentOutput, lastPage bool) bool {
+			accounts = append(accounts, page.Accounts...)
+			return !lastPage
+		})
 	})
 
 	return accounts, oops.Wrapf(err, "organizations.ListAccountsForParent")
 }
 
 func (c Client) DelegateAdmin(ctx context.Context, acctID, servicePrincipal string) error {
-	if _, err := c.organizationClient.EnableAWSServiceAccessWithContext(ctx, &organizations.EnableAWSServiceAccessInput{
-		ServicePrincipal: &servicePrincipal,
-	}); err != nil {
+	err := c.retry.Do(ctx, func() error {
+		_, err := c.organizationClient.EnableAWSServiceAccessWithContext(ctx, &organizations.EnableAWSServiceAccessInput{
+			ServicePrincipal: &servicePrincipal,
+		})
+		return err
+	})
+	if err != nil {
 		return oops.Wrapf(err, "organizations.EnableAWSServiceAccess service %s", servicePrincipal)
 	}
 
-	_, err := c.organizationClient.RegisterDelegatedAdministratorWithContext(ctx, &organizations.RegisterDelegatedAdministratorInput{
-		AccountId:        &acctID,
-		ServicePrincipal: &servicePrincipal,
+	err = c.retry.Do(ctx, func() error {
+		_, err := c.organizationClient.RegisterDelegatedAdministratorWithContext(ctx, &organizations.RegisterDelegatedAdministratorInput{
+			AccountId:        &acctID,
+			ServicePrincipal: &servicePrincipal,
+		})
+		return err
 	})
 	if err != nil {
 		return oops.Wrapf(err, "organizations.RegisterDelegatedAdministrator service %s", servicePrincipal)
@@ -457,13 +681,16 @@ func (c Client) DelegateAdmin(ctx context.Context, acctID, servicePrincipal stri
 // value with a slice of service principals that are delegated to the account key.
 func (c Client) FetchDelegatedAdminPrincipals(ctx context.Context) (map[string][]string, error) {
 	var delegatedAccounts []string
-	err := c.organizationClient.ListDelegatedAdministratorsPagesWithContext(ctx, &organizations.ListDelegatedAdministratorsInput{},
-		func(page *organizations.ListDelegatedAdministratorsOutput, lastPage bool) bool {
-			for _, acct := range page.DelegatedAdministrators {
-				delegatedAccounts = append(delegatedAccounts, *acct.Id)
-			}
-			return !lastPage
-		})
+	err := c.retry.Do(ctx, func() error {
+		delegatedAccounts = nil
+		return c.organizationClient.ListDelegatedAdministratorsPagesWithContext(ctx, &organizations.ListDelegatedAdministratorsInput{},
+			func(page *organizations.ListDelegatedAdministratorsOutput, lastPage bool) bool {
+				for _, acct := range page.DelegatedAdministrators {
+					delegatedAccounts = append(delegatedAccounts, *acct.Id)
+				}
+				return !lastPage
+			})
+	})
 	if err != nil {
 		return nil, oops.Wrapf(err, "organizations.ListDelegatedAdministrators")
 	}
@@ -472,13 +699,16 @@ func (c Client) FetchDelegatedAdminPrincipals(ctx context.Context) (map[string][
 	resp := make(map[string][]string)
 	for _, acct := range delegatedAccounts {
 		var servicePrincipals []string
-		err := c.organizationClient.ListDelegatedServicesForAccountPagesWithContext(ctx, &organizations.ListDelegatedServicesForAccountInput{
-			AccountId: &acct,
-		}, func(page *organizations.ListDelegatedServicesForAccountOutput, lastPage bool) bool {
-			for _, service := range page.DelegatedServices {
-				servicePrincipals = append(servicePrincipals, *service.ServicePrincipal)
-			}
-			return !lastPage
+		err := c.retry.Do(ctx, func() error {
+			servicePrincipals = nil
+			return c.organizationClient.ListDelegatedServicesForAccountPagesWithContext(ctx, &organizations.ListDelegatedServicesForAccountInput{
+				AccountId: &acct,
+			}, func(page *organizations.ListDelegatedServicesForAccountOutput, lastPage bool) bool {
+				for _, service := range page.DelegatedServices {
+					servicePrincipals = append(servicePrincipals, *service.ServicePrincipal)
+				}
+				return !lastPage
+			})
 		})
 		if err != nil {
 			return nil, oops.Wrapf(err, "organizations.ListDelegatedServicesForAccount acctID: %s", acct)
@@ -489,8 +719,168 @@ func (c Client) FetchDelegatedAdminPrincipals(ctx context.Context) (map[string][
 	return resp, nil
 }
 
+// ListServiceControlPolicies returns the Service Control Policies attached
+// directly to targetID, an OU, account or root ID.
+func (c Client) ListServiceControlPolicies(ctx context.Context, targetID string) ([]*organizations.PolicySummary, error) {
+	return c.listPolicies(ctx, targetID, organizations.PolicyTypeServiceControlPolicy)
+}
+
+// listPolicies returns the policies of policyType attached directly to
+// targetID.
+func (c Client) listPolicies(ctx context.Context, targetID, policyType string) ([]*organizations.PolicySummary, error) {
+	var policies []*organizations.PolicySummary
+	err := c.retry.Do(ctx, func() error {
+		policies = nil
+		return c.organizationClient.ListPoliciesForTargetPagesWithContext(ctx, &organizations.ListPoliciesForTargetInput{
+			TargetId: &targetID,
+			Filter:   &policyType,
+		},
+			func(page *organizations.ListPoliciesForTargetOutput, lastPage bool) bool {
+				policies = append(policies, page.Policies...)
+				return !lastPage
+			},
+		)
+	})
+	if err != nil {
+		return nil, oops.Wrapf(err, "organizations.ListPoliciesForTarget targetID: %s", targetID)
+	}
+	return policies, nil
+}
+
+// ListAllServiceControlPolicies returns every Service Control Policy in the
+// organization.
+func (c Client) ListAllServiceControlPolicies(ctx context.Context) ([]*organizations.PolicySummary, error) {
+	var policies []*organizations.PolicySummary
+	err := c.retry.Do(ctx, func() error {
+		policies = nil
+		return c.organizationClient.ListPoliciesPagesWithContext(ctx, &organizations.ListPoliciesInput{
+			Filter: aws.String(organizations.PolicyTypeServiceControlPolicy),
+		},
+			func(page *organizations.ListPoliciesOutput, lastPage bool) bool {
+				policies = append(policies, page.Policies...)
+				return !lastPage
+			},
+		)
+	})
+	if err != nil {
+		return nil, oops.Wrapf(err, "organizations.ListPolicies")
+	}
+	return policies, nil
+}
+
+// DescribePolicy returns the policy policyID with its content.
+func (c Client) DescribePolicy(ctx context.Context, policyID string) (*organizations.Policy, error) {
+	var out *organizations.DescribePolicyOutput
+	err := c.retry.Do(ctx, func() error {
+		var err error
+		out, err = c.organizationClient.DescribePolicyWithContext(ctx, &organizations.DescribePolicyInput{
+			PolicyId: &policyID,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, oops.Wrapf(err, "organizations.DescribePolicy policyID: %s", policyID)
+	}
+	return out.Policy, nil
+}
+
+// ListTargetsForPolicy returns the OUs, accounts and roots policyID is
+// attached to.
+func (c Client) ListTargetsForPolicy(ctx context.Context, policyID string) ([]*organizations.PolicyTargetSummary, error) {
+	var targets []*organizations.PolicyTargetSummary
+	err := c.retry.Do(ctx, func() error {
+		targets = nil
+		return c.organizationClient.ListTargetsForPolicyPagesWithContext(ctx, &organizations.ListTargetsForPolicyInput{
+			PolicyId: &policyID,
+		},
+			func(page *organizations.ListTargetsForPolicyOutput, lastPage bool) bool {
+				targets = append(targets, page.Targets...)
+				return !lastPage
+			},
+		)
+	})
+	if err != nil {
+		return nil, oops.Wrapf(err, "organizations.ListTargetsForPolicy policyID: %s", policyID)
+	}
+	return targets, nil
+}
+
+// CreateServiceControlPolicy creates a Service Control Policy and returns its
+// ID.
+func (c Client) CreateServiceControlPolicy(ctx context.Context, name, content string, tags []string) (string, error) {
+	var out *organizations.CreatePolicyOutput
+	err := c.retry.forCreate().Do(ctx, func() error {
+		var err error
+		out, err = c.organizationClient.CreatePolicyWithContext(ctx, &organizations.CreatePolicyInput{
+			Name:        &name,
+			Description: aws.String("Managed by telophase"),
+			Content:     &content,
+			Type:        aws.String(organizations.PolicyTypeServiceControlPolicy),
+			Tags:        buildTags(tags),
+		})
+		return err
+	})
+	if err != nil {
+		return "", oops.Wrapf(err, "organizations.CreatePolicy name: %s", name)
+	}
+	return aws.StringValue(out.Policy.PolicySummary.Id), nil
+}
+
+// UpdatePolicyContent replaces the content of policyID.
+func (c Client) UpdatePolicyContent(ctx context.Context, policyID, content string) error {
+	err := c.retry.Do(ctx, func() error {
+		_, err := c.organizationClient.UpdatePolicyWithContext(ctx, &organizations.UpdatePolicyInput{
+			PolicyId: &policyID,
+			Content:  &content,
+		})
+		return err
+	})
+	if err != nil {
+		return oops.Wrapf(err, "organizations.UpdatePolicy policyID: %s", policyID)
+	}
+	return nil
+}
+
+func (c Client) AttachPolicy(ctx context.Context, policyID, targetID string) error {
+	err := c.retry.Do(ctx, func() error {
+		_, err := c.organizationClient.AttachPolicyWithContext(ctx, &organizations.AttachPolicyInput{
+			PolicyId: &policyID,
+			TargetId: &targetID,
+		})
+		return err
+	})
+	if err != nil {
+		return oops.Wrapf(err, "organizations.AttachPolicy policy %s to %s", policyID, targetID)
+	}
+	return nil
+}
+
+func (c Client) DetachPolicy(ctx context.Context, policyID, targetID string) error {
+	err := c.retry.Do(ctx, func() error {
+		_, err := c.organizationClient.DetachPolicyWithContext(ctx, &organizations.DetachPolicyInput{
+			PolicyId: &policyID,
+			TargetId: &targetID,
+		})
+		return err
+	})
+	if err != nil {
+		return oops.Wrapf(err, "organizations.DetachPolicy policy %s from %s", policyID, targetID)
+	}
+	return nil
+}
+
 func (c Client) FetchOUAndDescendents(ctx context.Context, ouID, mgmtAccountID string) (resource.OrganizationUnit, error) {
-	var ou resource.OrganizationUnit
+	ou, err := c.fetchOUAndDescendents(ctx, ouID, mgmtAccountID)
+	if err != nil {
+		return resource.OrganizationUnit{}, err
+	}
+	return *ou, nil
+}
+
+// fetchOUAndDescendents returns OUs by pointer so that the Parent of every
+// account and child OU is the OU in the returned tree.
+func (c Client) fetchOUAndDescendents(ctx context.Context, ouID, mgmtAccountID string) (*resource.OrganizationUnit, error) {
+	ou := &resource.OrganizationUnit{}
 
 	var providerOU *organizations.OrganizationalUnit
 
@@ -505,7 +895,7 @@ func (c Client) FetchOUAndDescendents(ctx context.Context, ouID, mgmtAccountID s
 		var err error
 		providerOU, err = c.GetOrganizationUnit(ctx, ouID)
 		if err != nil {
-			return ou, err
+			return nil, err
 		}
 	}
 
@@ -514,14 +904,14 @@ func (c Client) FetchOUAndDescendents(ctx context.Context, ouID, mgmtAccountID s
 
 	groupAccounts, err := c.CurrentAccountsForParent(ctx, *ou.OUID)
 	if err != nil {
-		return ou, err
+		return nil, err
 	}
 
 	for _, providerAcct := range groupAccounts {
 		acct := resource.Account{
 			AccountID:   *providerAcct.Id,
 			Email:       *providerAcct.Email,
-			Parent:      &ou,
+			Parent:      ou,
 			AccountName: *providerAcct.Name,
 			Status:      aws.StringValue(providerAcct.Status),
 		}
@@ -533,16 +923,16 @@ func (c Client) FetchOUAndDescendents(ctx context.Context, ouID, mgmtAccountID s
 
 	children, err := c.GetOrganizationUnitChildren(ctx, ouID)
 	if err != nil {
-		return ou, err
+		return nil, err
 	}
 
 	for _, providerChild := range children {
-		child, err := c.FetchOUAndDescendents(ctx, *providerChild.Id, mgmtAccountID)
+		child, err := c.fetchOUAndDescendents(ctx, *providerChild.Id, mgmtAccountID)
 		if err != nil {
-			return ou, err
+			return nil, err
 		}
-		child.Parent = &ou
-		ou.ChildOUs = append(ou.ChildOUs, &child)
+		child.Parent = ou
+		ou.ChildOUs = append(ou.ChildOUs, child)
 	}
 
 	return ou, nil
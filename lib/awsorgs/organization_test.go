@@ -0,0 +1,174 @@
+package awsorgs_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/organizations"
+	"github.com/aws/aws-sdk-go/service/organizations/organizationsiface"
+	"github.com/santiago-labs/telophasecli/cmd/runner"
+	"github.com/santiago-labs/telophasecli/lib/awsorgs"
+	"github.com/santiago-labs/telophasecli/lib/awsorgs/awsorgsmock"
+	"github.com/santiago-labs/telophasecli/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingOrganizations records the calls that change the organization.
+// OU 1ou has the child OU 2ou, and Service Control Policies are enabled.
+type recordingOrganizations struct {
+	organizationsiface.OrganizationsAPI
+
+	calls    []string
+	policies map[string][]string
+}
+
+func (m *recordingOrganizations) ListRoots(*organizations.ListRootsInput) (*organizations.ListRootsOutput, error) {
+	return &organizations.ListRootsOutput{
+		Roots: []*organizations.Root{{
+			Id: aws.String("r-0000"),
+			PolicyTypes: []*organizations.PolicyTypeSummary{
+				{Type: aws.String(organizations.PolicyTypeServiceControlPolicy), Status: aws.String(organizations.PolicyTypeStatusEnabled)},
+				{Type: aws.String(organizations.PolicyTypeTagPolicy), Status: aws.String(organizations.PolicyTypeStatusPendingDisable)},
+			},
+		}},
+	}, nil
+}
+
+func (m *recordingOrganizations) ListOrganizationalUnitsForParentPagesWithContext(ctx aws.Context, input *organizations.ListOrganizationalUnitsForParentInput, fn func(*organizations.ListOrganizationalUnitsForParentOutput, bool) bool, opts ...request.Option) error {
+	var ous []*organizations.OrganizationalUnit
+	if aws.StringValue(input.ParentId) == "1ou" {
+		ous = append(ous, &organizations.OrganizationalUnit{Id: aws.String("2ou"), Name: aws.String("Child")})
+	}
+	fn(&organizations.ListOrganizationalUnitsForParentOutput{OrganizationalUnits: ous}, true)
+	return nil
+}
+
+func (m *recordingOrganizations) ListPoliciesForTargetPagesWithContext(ctx aws.Context, input *organizations.ListPoliciesForTargetInput, fn func(*organizations.ListPoliciesForTargetOutput, bool) bool, opts ...request.Option) error {
+	var policies []*organizations.PolicySummary
+	for _, id := range m.policies[aws.StringValue(input.TargetId)] {
+		policies = append(policies, &organizations.PolicySummary{Id: aws.String(id), Name: aws.String(id)})
+	}
+	fn(&organizations.ListPoliciesForTargetOutput{Policies: policies}, true)
+	return nil
+}
+
+func (m *recordingOrganizations) CreateOrganizationalUnitWithContext(ctx aws.Context, input *organizations.CreateOrganizationalUnitInput, opts ...request.Option) (*organizations.CreateOrganizationalUnitOutput, error) {
+	var tags []string
+	for _, tag := range input.Tags {
+		tags = append(tags, aws.StringValue(tag.Key)+"="+aws.StringValue(tag.Value))
+	}
+	m.calls = append(m.calls, fmt.Sprintf("CreateOrganizationalUnit %s in %s tags %v", *input.Name, *input.ParentId, tags))
+
+	id := "ou-new-" + *input.Name
+	// AWS attaches FullAWSAccess to new OUs.
+	m.policies[id] = []string{"p-FullAWSAccess"}
+	return &organizations.CreateOrganizationalUnitOutput{
+		OrganizationalUnit: &organizations.OrganizationalUnit{Id: aws.String(id), Name: input.Name},
+	}, nil
+}
+
+func (m *recordingOrganizations) AttachPolicyWithContext(ctx aws.Context, input *organizations.AttachPolicyInput, opts ...request.Option) (*organizations.AttachPolicyOutput, error) {
+	m.calls = append(m.calls, fmt.Sprintf("AttachPolicy %s to %s", *input.PolicyId, *input.TargetId))
+	return &organizations.AttachPolicyOutput{}, nil
+}
+
+func (m *recordingOrganizations) DetachPolicyWithContext(ctx aws.Context, input *organizations.DetachPolicyInput, opts ...request.Option) (*organizations.DetachPolicyOutput, error) {
+	m.calls = append(m.calls, fmt.Sprintf("DetachPolicy %s from %s", *input.PolicyId, *input.TargetId))
+	return &organizations.DetachPolicyOutput{}, nil
+}
+
+func (m *recordingOrganizations) MoveAccountWithContext(ctx aws.Context, input *organizations.MoveAccountInput, opts ...request.Option) (*organizations.MoveAccountOutput, error) {
+	m.calls = append(m.calls, fmt.Sprintf("MoveAccount %s from %s to %s", *input.AccountId, *input.SourceParentId, *input.DestinationParentId))
+	return &organizations.MoveAccountOutput{}, nil
+}
+
+func (m *recordingOrganizations) CreateAccountWithContext(ctx aws.Context, input *organizations.CreateAccountInput, opts ...request.Option) (*organizations.CreateAccountOutput, error) {
+	var tags []string
+	for _, tag := range input.Tags {
+		tags = append(tags, aws.StringValue(tag.Key)+"="+aws.StringValue(tag.Value))
+	}
+	m.calls = append(m.calls, fmt.Sprintf("CreateAccount %s role %s billing %s tags %v", *input.AccountName, aws.StringValue(input.RoleName), aws.StringValue(input.IamUserAccessToBilling), tags))
+	return m.OrganizationsAPI.CreateAccountWithContext(ctx, input, opts...)
+}
+
+func (m *recordingOrganizations) DeleteOrganizationalUnitWithContext(ctx aws.Context, input *organizations.DeleteOrganizationalUnitInput, opts ...request.Option) (*organizations.DeleteOrganizationalUnitOutput, error) {
+	m.calls = append(m.calls, fmt.Sprintf("DeleteOrganizationalUnit %s", *input.OrganizationalUnitId))
+	return &organizations.DeleteOrganizationalUnitOutput{}, nil
+}
+
+func TestRecreateOU(t *testing.T) {
+	orgs := &recordingOrganizations{
+		OrganizationsAPI: awsorgsmock.New(),
+		policies: map[string][]string{
+			"1ou": {"p-denyregions"},
+			"2ou": {"p-FullAWSAccess", "p-denyiam"},
+		},
+	}
+	client := awsorgs.New(&awsorgs.Config{
+		OrganizationClient: orgs,
+		RetryPolicy:        &testRetryPolicy,
+	})
+
+	newIDs, err := client.RecreateOU(context.Background(), runner.NewSTDErr(), resource.Account{}, "1ou", "Example", "ou-parent")
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		"1ou": "ou-new-Example",
+		"2ou": "ou-new-Child",
+	}, newIDs)
+	assert.Equal(t, []string{
+		"CreateOrganizationalUnit Example in ou-parent tags [ou=ExampleTenants]",
+		"AttachPolicy p-denyregions to ou-new-Example",
+		"DetachPolicy p-FullAWSAccess from ou-new-Example",
+		"MoveAccount 10000000000 from 1ou to ou-new-Example",
+		"MoveAccount 20000000000 from 1ou to ou-new-Example",
+		"CreateOrganizationalUnit Child in ou-new-Example tags []",
+		"AttachPolicy p-denyiam to ou-new-Child",
+		"DeleteOrganizationalUnit 2ou",
+		"DeleteOrganizationalUnit 1ou",
+	}, orgs.calls)
+}
+
+func TestCreateAccount(t *testing.T) {
+	tests := []struct {
+		name string
+		acct resource.Account
+		want string
+	}{
+		{
+			name: "defaults",
+			acct: resource.Account{AccountName: "test5", Email: "test5@example.com"},
+			want: "CreateAccount test5 role OrganizationAccountAccessRole billing  tags [AccountName=test5]",
+		},
+		{
+			name: "role name and billing access",
+			acct: resource.Account{
+				AccountName:            "test5",
+				Email:                  "test5@example.com",
+				AssumeRoleName:         "AdminRole",
+				IamUserAccessToBilling: aws.Bool(false),
+				Tags:                   []string{"env=dev"},
+			},
+			want: "CreateAccount test5 role AdminRole billing DENY tags [AccountName=test5 env=dev]",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			orgs := &recordingOrganizations{OrganizationsAPI: awsorgsmock.New()}
+			client := awsorgs.New(&awsorgs.Config{
+				OrganizationClient: orgs,
+				RetryPolicy:        &testRetryPolicy,
+			})
+
+			acctID, err := client.CreateAccount(context.Background(), runner.NewSTDErr(), resource.Account{}, tc.acct)
+			require.NoError(t, err)
+			assert.Equal(t, "50000000000", acctID)
+			assert.Equal(t, []string{tc.want}, orgs.calls)
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package awsorgs
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/organizations"
+)
+
+// RetryPolicy retries Organizations API calls that fail because of throttling
+// or concurrent changes to the organization.
+type RetryPolicy struct {
+	// MaxAttempts is the number of times a call is made before its error is
+	// returned.
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry. It doubles after every
+	// retry up to MaxDelay and a random jitter of up to the delay is used.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// RetryableCodes are the AWS error codes that are retried.
+	RetryableCodes []string
+}
+
+// DefaultRetryPolicy is used by clients created without a RetryPolicy.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 8,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    30 * time.Second,
+	RetryableCodes: []string{
+		organizations.ErrCodeTooManyRequestsException,
+		organizations.ErrCodeConcurrentModificationException,
+		organizations.ErrCodeServiceException,
+		"ThrottlingException",
+	},
+}
+
+// createRetryableCodes are the codes that are retried when creating accounts,
+// OUs and policies. A ServiceException may be returned after AWS created the
+// resource, so retrying it could create the resource twice.
+var createRetryableCodes = map[string]bool{
+	organizations.ErrCodeTooManyRequestsException:        true,
+	organizations.ErrCodeConcurrentModificationException: true,
+	"ThrottlingException":                                true,
+}
+
+// forCreate returns the policy for calls that create a resource. Only the
+// codes of p that are returned before AWS accepted the request are retried.
+func (p RetryPolicy) forCreate() RetryPolicy {
+	var codes []string
+	for _, code := range p.RetryableCodes {
+		if createRetryableCodes[code] {
+			codes = append(codes, code)
+		}
+	}
+	p.RetryableCodes = codes
+	return p
+}
+
+// Do calls fn until it succeeds, returns an error that isn't retryable, or
+// MaxAttempts is reached.
+func (p RetryPolicy) Do(ctx context.Context, fn func() error) error {
+	var err error
+	for attempt := 0; ; attempt++ {
+		err = fn()
+		if err == nil || !p.retryable(err) || attempt+1 >= p.MaxAttempts {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(p.delay(attempt)):
+		}
+	}
+}
+
+func (p RetryPolicy) retryable(err error) bool {
+	awsErr, ok := err.(awserr.Error)
+	if !ok {
+		return false
+	}
+	for _, code := range p.RetryableCodes {
+		if awsErr.Code() == code {
+			return true
+		}
+	}
+	return false
+}
+
+// delay returns the delay before retrying after attempt with full jitter.
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	delay := p.MaxDelay
+	if attempt < 32 && p.BaseDelay<<attempt > 0 && p.BaseDelay<<attempt < p.MaxDelay {
+		delay = p.BaseDelay << attempt
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(delay) + 1))
+}
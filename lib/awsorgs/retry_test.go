@@ -0,0 +1,147 @@
+package awsorgs_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/organizations"
+	"github.com/santiago-labs/telophasecli/cmd/runner"
+	"github.com/santiago-labs/telophasecli/lib/awsorgs"
+	"github.com/santiago-labs/telophasecli/lib/awsorgs/awsorgsmock"
+	"github.com/santiago-labs/telophasecli/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testRetryPolicy = awsorgs.RetryPolicy{
+	MaxAttempts:    3,
+	BaseDelay:      time.Millisecond,
+	MaxDelay:       5 * time.Millisecond,
+	RetryableCodes: awsorgs.DefaultRetryPolicy.RetryableCodes,
+}
+
+func throttled() error {
+	return awserr.New(organizations.ErrCodeTooManyRequestsException, "rate exceeded", nil)
+}
+
+func concurrentModification() error {
+	return awserr.New(organizations.ErrCodeConcurrentModificationException, "try again", nil)
+}
+
+func TestRetryPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures awsorgsmock.Failures
+		wantErr  bool
+		wantLeft int
+	}{
+		{
+			name:     "retried until it succeeds",
+			failures: awsorgsmock.Failures{"ListRoots": {throttled(), concurrentModification()}},
+		},
+		{
+			name:     "max attempts",
+			failures: awsorgsmock.Failures{"ListRoots": {throttled(), throttled(), throttled(), throttled()}},
+			wantErr:  true,
+			wantLeft: 1,
+		},
+		{
+			name:     "not retryable",
+			failures: awsorgsmock.Failures{"ListRoots": {awserr.New(organizations.ErrCodeAccessDeniedException, "denied", nil), throttled()}},
+			wantErr:  true,
+			wantLeft: 1,
+		},
+		{
+			name:     "not an AWS error",
+			failures: awsorgsmock.Failures{"ListRoots": {errors.New("boom")}},
+			wantErr:  true,
+		},
+	}
+
+	for _, tc := range tests {
+		client := awsorgs.New(&awsorgs.Config{
+			OrganizationClient: awsorgsmock.NewWithFailures(tc.failures),
+			RetryPolicy:        &testRetryPolicy,
+		})
+
+		rootID, err := client.GetRootId()
+		if tc.wantErr {
+			assert.Error(t, err, tc.name)
+		} else {
+			require.NoError(t, err, tc.name)
+			assert.Equal(t, "r-0000", rootID, tc.name)
+		}
+		assert.Len(t, tc.failures["ListRoots"], tc.wantLeft, tc.name)
+	}
+}
+
+func TestRetryPolicyEveryMethod(t *testing.T) {
+	ctx := context.Background()
+	failures := awsorgsmock.Failures{
+		"ListAccounts":                {throttled()},
+		"DescribeOrganization":        {concurrentModification()},
+		"ListTagsForResource":         {throttled(), throttled()},
+		"TagResource":                 {concurrentModification()},
+		"CreateAccount":               {throttled()},
+		"DescribeCreateAccountStatus": {throttled(), concurrentModification()},
+	}
+	client := awsorgs.New(&awsorgs.Config{
+		OrganizationClient: awsorgsmock.NewWithFailures(failures),
+		RetryPolicy:        &testRetryPolicy,
+	})
+
+	mgmtAcct, err := client.FetchManagementAccount(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "00000000000", mgmtAcct.AccountID)
+
+	_, err = client.GetTags(ctx, "1ou")
+	require.NoError(t, err)
+
+	require.NoError(t, client.TagResource(ctx, "1ou", []string{"env=prod"}))
+
+	acctID, err := client.CreateAccount(ctx, runner.NewSTDErr(), resource.Account{}, resource.Account{
+		AccountName: "test5",
+		Email:       "test5@example.com",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "50000000000", acctID)
+
+	for method, left := range failures {
+		assert.Empty(t, left, method)
+	}
+}
+
+func TestRetryPolicyCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	failures := awsorgsmock.Failures{"TagResource": {throttled(), throttled()}}
+	client := awsorgs.New(&awsorgs.Config{
+		OrganizationClient: awsorgsmock.NewWithFailures(failures),
+		RetryPolicy:        &awsorgs.DefaultRetryPolicy,
+	})
+
+	assert.Error(t, client.TagResource(ctx, "1ou", []string{"env=prod"}))
+	assert.Len(t, failures["TagResource"], 1)
+}
+
+func TestRetryPolicyCreateServiceException(t *testing.T) {
+	failures := awsorgsmock.Failures{
+		"CreateAccount": {awserr.New(organizations.ErrCodeServiceException, "internal error", nil)},
+	}
+	client := awsorgs.New(&awsorgs.Config{
+		OrganizationClient: awsorgsmock.NewWithFailures(failures),
+		RetryPolicy:        &testRetryPolicy,
+	})
+
+	// The account may have been created, so the call isn't retried.
+	_, err := client.CreateAccount(context.Background(), runner.NewSTDErr(), resource.Account{}, resource.Account{
+		AccountName: "test5",
+		Email:       "test5@example.com",
+	})
+	assert.Error(t, err)
+	assert.Empty(t, failures["CreateAccount"])
+}
@@ -1,15 +1,23 @@
 package awssess
 
 import (
+	"context"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/aws/aws-sdk-go/service/sts/stsiface"
 	"github.com/samsarahq/go/oops"
 )
 
+// RoleReadyTimeout is how long to wait for the role of a new account before it
+// can be assumed.
+const RoleReadyTimeout = 10 * time.Minute
+
 func DefaultSession(cfgs ...*aws.Config) (*session.Session, error) {
 	if os.Getenv("LOCALSTACK") != "" {
 		cfg := aws.NewConfig()
@@ -24,7 +32,7 @@ func DefaultSession(cfgs ...*aws.Config) (*session.Session, error) {
 	return sess, nil
 }
 
-func AssumeRole(svc *sts.STS, input *sts.AssumeRoleInput) (*sts.AssumeRoleOutput, error) {
+func AssumeRole(svc stsiface.STSAPI, input *sts.AssumeRoleInput) (*sts.AssumeRoleOutput, error) {
 	if os.Getenv("LOCALSTACK") != "" {
 		// Localstack doesn't handle IAM checks so let everything through.
 		return &sts.AssumeRoleOutput{
@@ -45,6 +53,35 @@ func AssumeRole(svc *sts.STS, input *sts.AssumeRoleInput) (*sts.AssumeRoleOutput
 	return result, nil
 }
 
+// WaitForRole assumes roleARN until it succeeds. The role of a new account can
+// be denied for a few minutes after the account is created. Errors other than
+// AccessDenied are returned right away, and the last AccessDenied is returned
+// if the role still can't be assumed after timeout.
+func WaitForRole(ctx context.Context, svc stsiface.STSAPI, roleARN string, timeout, interval time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		_, err := AssumeRole(svc, &sts.AssumeRoleInput{
+			RoleArn:         aws.String(roleARN),
+			RoleSessionName: aws.String("telophase-org"),
+		})
+		if err == nil {
+			return nil
+		}
+		if awsErr, ok := oops.Cause(err).(awserr.Error); !ok || awsErr.Code() != "AccessDenied" {
+			return err
+		}
+		if time.Now().Add(interval).After(deadline) {
+			return oops.Wrapf(err, "role %s can't be assumed after %s", roleARN, timeout)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
 // RoleARN to accountID.
 func RoleARNToAccountID(roleARN string) string {
 	parts := strings.Split(roleARN, ":")
@@ -0,0 +1,62 @@
+package awssess_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/aws/aws-sdk-go/service/sts/stsiface"
+	"github.com/santiago-labs/telophasecli/lib/awssess"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSTS fails AssumeRole with errs before it succeeds.
+type fakeSTS struct {
+	stsiface.STSAPI
+
+	errs  []error
+	calls int
+}
+
+func (f *fakeSTS) AssumeRole(input *sts.AssumeRoleInput) (*sts.AssumeRoleOutput, error) {
+	f.calls++
+	if len(f.errs) > 0 {
+		err := f.errs[0]
+		f.errs = f.errs[1:]
+		return nil, err
+	}
+	return &sts.AssumeRoleOutput{Credentials: &sts.Credentials{}}, nil
+}
+
+func accessDenied() error {
+	return awserr.New("AccessDenied", "not authorized to perform: sts:AssumeRole", nil)
+}
+
+func TestWaitForRole(t *testing.T) {
+	t.Setenv("LOCALSTACK", "")
+	ctx := context.Background()
+	roleARN := "arn:aws:iam::50000000000:role/AdminRole"
+
+	t.Run("waits while access is denied", func(t *testing.T) {
+		svc := &fakeSTS{errs: []error{accessDenied(), accessDenied()}}
+		require.NoError(t, awssess.WaitForRole(ctx, svc, roleARN, time.Minute, time.Millisecond))
+		assert.Equal(t, 3, svc.calls)
+	})
+
+	t.Run("gives up after the timeout", func(t *testing.T) {
+		svc := &fakeSTS{errs: []error{accessDenied(), accessDenied(), accessDenied()}}
+		err := awssess.WaitForRole(ctx, svc, roleARN, 0, time.Millisecond)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "can't be assumed")
+		assert.Equal(t, 1, svc.calls)
+	})
+
+	t.Run("returns other errors", func(t *testing.T) {
+		svc := &fakeSTS{errs: []error{awserr.New("ExpiredToken", "expired", nil)}}
+		require.Error(t, awssess.WaitForRole(ctx, svc, roleARN, time.Minute, time.Millisecond))
+		assert.Equal(t, 1, svc.calls)
+	})
+}
@@ -0,0 +1,401 @@
+// Package lock keeps two deploys from changing the same AWS Organization at
+// the same time with a lock stored in a DynamoDB table.
+package lock
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/samsarahq/go/oops"
+	"github.com/santiago-labs/telophasecli/lib/awssess"
+)
+
+// TableEnv is the environment variable that sets the default lock table.
+const TableEnv = "TELOPHASE_LOCK_TABLE"
+
+// DefaultTTL is how long a lock is held if its owner stops renewing it, e.g.
+// because the process was killed.
+const DefaultTTL = 15 * time.Minute
+
+const (
+	keyAttribute = "LockID"
+	// The lock table has a single partition key so that it can be shared with
+	// other tools, like a Terraform state lock table.
+	keyType = "S"
+)
+
+// Info describes who holds a lock.
+type Info struct {
+	ID         string
+	Owner      string
+	Command    string
+	AcquiredAt time.Time
+	ExpiresAt  time.Time
+
+	token string
+}
+
+func (i Info) String() string {
+	return fmt.Sprintf("%s (%s) since %s, expires at %s", i.Owner, i.Command, i.AcquiredAt.Format(time.RFC3339), i.ExpiresAt.Format(time.RFC3339))
+}
+
+// LockedError is returned when the lock is held by someone else.
+type LockedError struct {
+	Info Info
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("%s is locked by %s", e.Info.ID, e.Info)
+}
+
+// Locker acquires locks in a DynamoDB table. The table is created if it does
+// not exist.
+type Locker struct {
+	client dynamodbiface.DynamoDBAPI
+	table  string
+	ttl    time.Duration
+	now    func() time.Time
+}
+
+func New(client dynamodbiface.DynamoDBAPI, table string, ttl time.Duration) *Locker {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Locker{
+		client: client,
+		table:  table,
+		ttl:    ttl,
+		now:    time.Now,
+	}
+}
+
+// NewDefault returns a Locker using the current credentials. It works with
+// LocalStack.
+func NewDefault(table string, ttl time.Duration) (*Locker, error) {
+	sess, err := awssess.DefaultSession()
+	if err != nil {
+		return nil, err
+	}
+	return New(dynamodb.New(sess), table, ttl), nil
+}
+
+// Owner describes the current process for Info.Owner.
+func Owner() string {
+	user := os.Getenv("USER")
+	if user == "" {
+		user = "unknown"
+	}
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s@%s (pid %d)", user, host, os.Getpid())
+}
+
+// Lock is a held lock. It is renewed until it is released.
+type Lock struct {
+	locker *Locker
+	info   Info
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+
+	lost    chan struct{}
+	lostErr error
+}
+
+func (l *Lock) Info() Info {
+	return l.info
+}
+
+// Lost is closed if the lock can no longer be renewed, because someone else
+// took it or renewals failed for the whole TTL. Whoever holds the lock must
+// stop changing the organization. Err returns why the lock was lost.
+func (l *Lock) Lost() <-chan struct{} {
+	return l.lost
+}
+
+// Err returns why the lock was lost, or nil if it is still held.
+func (l *Lock) Err() error {
+	select {
+	case <-l.lost:
+		return l.lostErr
+	default:
+		return nil
+	}
+}
+
+// Acquire takes the lock id. It returns a *LockedError if another owner holds
+// the lock and it has not expired.
+func (l *Locker) Acquire(ctx context.Context, id, owner, command string) (*Lock, error) {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return nil, oops.Wrapf(err, "generating lock token")
+	}
+
+	now := l.now()
+	info := Info{
+		ID:         id,
+		Owner:      owner,
+		Command:    command,
+		AcquiredAt: now,
+		ExpiresAt:  now.Add(l.ttl),
+		token:      hex.EncodeToString(token),
+	}
+
+	input := &dynamodb.PutItemInput{
+		TableName:           aws.String(l.table),
+		Item:                toItem(info),
+		ConditionExpression: aws.String("attribute_not_exists(#id) OR #expires < :now"),
+		ExpressionAttributeNames: map[string]*string{
+			"#id":      aws.String(keyAttribute),
+			"#expires": aws.String("ExpiresAt"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":now": {N: aws.String(strconv.FormatInt(now.Unix(), 10))},
+		},
+	}
+
+	_, err := l.client.PutItemWithContext(ctx, input)
+	if isCode(err, dynamodb.ErrCodeResourceNotFoundException) {
+		if err := l.createTable(ctx); err != nil {
+			return nil, err
+		}
+		_, err = l.client.PutItemWithContext(ctx, input)
+	}
+	if isCode(err, dynamodb.ErrCodeConditionalCheckFailedException) {
+		holder, getErr := l.Get(ctx, id)
+		if getErr != nil {
+			return nil, getErr
+		}
+		if holder == nil {
+			// The lock was released after the put, try again.
+			return l.Acquire(ctx, id, owner, command)
+		}
+		return nil, &LockedError{Info: *holder}
+	}
+	if err != nil {
+		return nil, oops.Wrapf(err, "acquiring lock %s in table %s", id, l.table)
+	}
+
+	lock := &Lock{
+		locker: l,
+		info:   info,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+		lost:   make(chan struct{}),
+	}
+	go lock.renew()
+	return lock, nil
+}
+
+// renew extends the lock until it is released so that long deploys keep it.
+// A failed renewal is retried on the next tick. The lock is lost if another
+// owner holds it or if every renewal fails until it expires.
+func (l *Lock) renew() {
+	defer close(l.done)
+
+	ticker := time.NewTicker(l.locker.ttl / 3)
+	defer ticker.Stop()
+	expiresAt := l.info.ExpiresAt
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			now := l.locker.now()
+			_, err := l.locker.client.UpdateItem(&dynamodb.UpdateItemInput{
+				TableName:           aws.String(l.locker.table),
+				Key:                 key(l.info.ID),
+				UpdateExpression:    aws.String("SET #expires = :expires"),
+				ConditionExpression: aws.String("#token = :token"),
+				ExpressionAttributeNames: map[string]*string{
+					"#expires": aws.String("ExpiresAt"),
+					"#token":   aws.String("Token"),
+				},
+				ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+					":expires": {N: aws.String(strconv.FormatInt(now.Add(l.locker.ttl).Unix(), 10))},
+					":token":   {S: aws.String(l.info.token)},
+				},
+			})
+			if isCode(err, dynamodb.ErrCodeConditionalCheckFailedException) {
+				l.lose(oops.Errorf("lock %s was taken or force unlocked", l.info.ID))
+				return
+			}
+			if err != nil {
+				if !now.Before(expiresAt) {
+					l.lose(oops.Wrapf(err, "lock %s expired, renewing it failed", l.info.ID))
+					return
+				}
+				continue
+			}
+			expiresAt = now.Add(l.locker.ttl)
+		}
+	}
+}
+
+func (l *Lock) lose(err error) {
+	l.lostErr = err
+	close(l.lost)
+}
+
+// Release stops renewing the lock and deletes it if it is still held by this
+// Lock.
+func (l *Lock) Release() error {
+	if l == nil {
+		return nil
+	}
+
+	var err error
+	l.once.Do(func() {
+		close(l.stop)
+		<-l.done
+
+		_, err = l.locker.client.DeleteItem(&dynamodb.DeleteItemInput{
+			TableName:           aws.String(l.locker.table),
+			Key:                 key(l.info.ID),
+			ConditionExpression: aws.String("#token = :token"),
+			ExpressionAttributeNames: map[string]*string{
+				"#token": aws.String("Token"),
+			},
+			ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+				":token": {S: aws.String(l.info.token)},
+			},
+		})
+		if isCode(err, dynamodb.ErrCodeConditionalCheckFailedException) {
+			// The lock expired and was taken or force unlocked.
+			err = nil
+		}
+		if err != nil {
+			err = oops.Wrapf(err, "releasing lock %s", l.info.ID)
+		}
+	})
+	return err
+}
+
+// Get returns who holds the lock id, or nil if nobody does.
+func (l *Locker) Get(ctx context.Context, id string) (*Info, error) {
+	out, err := l.client.GetItemWithContext(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(l.table),
+		Key:            key(id),
+		ConsistentRead: aws.Bool(true),
+	})
+	if isCode(err, dynamodb.ErrCodeResourceNotFoundException) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, oops.Wrapf(err, "reading lock %s from table %s", id, l.table)
+	}
+	if len(out.Item) == 0 {
+		return nil, nil
+	}
+
+	info := fromItem(out.Item)
+	return &info, nil
+}
+
+// ForceUnlock deletes the lock id whoever holds it and returns who held it.
+func (l *Locker) ForceUnlock(ctx context.Context, id string) (*Info, error) {
+	out, err := l.client.DeleteItemWithContext(ctx, &dynamodb.DeleteItemInput{
+		TableName:    aws.String(l.table),
+		Key:          key(id),
+		ReturnValues: aws.String(dynamodb.ReturnValueAllOld),
+	})
+	if isCode(err, dynamodb.ErrCodeResourceNotFoundException) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, oops.Wrapf(err, "deleting lock %s from table %s", id, l.table)
+	}
+	if len(out.Attributes) == 0 {
+		return nil, nil
+	}
+
+	info := fromItem(out.Attributes)
+	return &info, nil
+}
+
+func (l *Locker) createTable(ctx context.Context) error {
+	_, err := l.client.CreateTableWithContext(ctx, &dynamodb.CreateTableInput{
+		TableName: aws.String(l.table),
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{
+			{AttributeName: aws.String(keyAttribute), AttributeType: aws.String(keyType)},
+		},
+		KeySchema: []*dynamodb.KeySchemaElement{
+			{AttributeName: aws.String(keyAttribute), KeyType: aws.String(dynamodb.KeyTypeHash)},
+		},
+		BillingMode: aws.String(dynamodb.BillingModePayPerRequest),
+	})
+	// Another deploy may have created the table at the same time.
+	if err != nil && !isCode(err, dynamodb.ErrCodeResourceInUseException) {
+		return oops.Wrapf(err, "creating lock table %s", l.table)
+	}
+
+	if err := l.client.WaitUntilTableExistsWithContext(ctx, &dynamodb.DescribeTableInput{
+		TableName: aws.String(l.table),
+	}); err != nil {
+		return oops.Wrapf(err, "waiting for lock table %s", l.table)
+	}
+	return nil
+}
+
+func key(id string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		keyAttribute: {S: aws.String(id)},
+	}
+}
+
+func toItem(info Info) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		keyAttribute: {S: aws.String(info.ID)},
+		"Owner":      {S: aws.String(info.Owner)},
+		"Command":    {S: aws.String(info.Command)},
+		"AcquiredAt": {N: aws.String(strconv.FormatInt(info.AcquiredAt.Unix(), 10))},
+		"ExpiresAt":  {N: aws.String(strconv.FormatInt(info.ExpiresAt.Unix(), 10))},
+		"Token":      {S: aws.String(info.token)},
+	}
+}
+
+func fromItem(item map[string]*dynamodb.AttributeValue) Info {
+	return Info{
+		ID:         stringAttribute(item[keyAttribute]),
+		Owner:      stringAttribute(item["Owner"]),
+		Command:    stringAttribute(item["Command"]),
+		AcquiredAt: timeAttribute(item["AcquiredAt"]),
+		ExpiresAt:  timeAttribute(item["ExpiresAt"]),
+		token:      stringAttribute(item["Token"]),
+	}
+}
+
+func stringAttribute(value *dynamodb.AttributeValue) string {
+	if value == nil {
+		return ""
+	}
+	return aws.StringValue(value.S)
+}
+
+func timeAttribute(value *dynamodb.AttributeValue) time.Time {
+	if value == nil {
+		return time.Time{}
+	}
+	seconds, _ := strconv.ParseInt(aws.StringValue(value.N), 10, 64)
+	return time.Unix(seconds, 0).UTC()
+}
+
+func isCode(err error, code string) bool {
+	if awsErr, ok := err.(awserr.Error); ok {
+		return awsErr.Code() == code
+	}
+	return false
+}
@@ -0,0 +1,211 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDynamoDB implements the requests Locker makes against a single table.
+type fakeDynamoDB struct {
+	dynamodbiface.DynamoDBAPI
+
+	mu      sync.Mutex
+	created bool
+	items   map[string]map[string]*dynamodb.AttributeValue
+	// updateErr fails every renewal.
+	updateErr error
+}
+
+func (f *fakeDynamoDB) PutItemWithContext(_ aws.Context, input *dynamodb.PutItemInput, _ ...request.Option) (*dynamodb.PutItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.created {
+		return nil, awserr.New(dynamodb.ErrCodeResourceNotFoundException, "no table", nil)
+	}
+
+	id := aws.StringValue(input.Item[keyAttribute].S)
+	if existing, ok := f.items[id]; ok {
+		now, _ := strconv.ParseInt(aws.StringValue(input.ExpressionAttributeValues[":now"].N), 10, 64)
+		if timeAttribute(existing["ExpiresAt"]).Unix() >= now {
+			return nil, awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "locked", nil)
+		}
+	}
+	f.items[id] = input.Item
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func (f *fakeDynamoDB) GetItemWithContext(_ aws.Context, input *dynamodb.GetItemInput, _ ...request.Option) (*dynamodb.GetItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return &dynamodb.GetItemOutput{Item: f.items[aws.StringValue(input.Key[keyAttribute].S)]}, nil
+}
+
+func (f *fakeDynamoDB) UpdateItem(input *dynamodb.UpdateItemInput) (*dynamodb.UpdateItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.updateErr != nil {
+		return nil, f.updateErr
+	}
+	item, ok := f.items[aws.StringValue(input.Key[keyAttribute].S)]
+	if !ok || stringAttribute(item["Token"]) != aws.StringValue(input.ExpressionAttributeValues[":token"].S) {
+		return nil, awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "not held", nil)
+	}
+	item["ExpiresAt"] = input.ExpressionAttributeValues[":expires"]
+	return &dynamodb.UpdateItemOutput{}, nil
+}
+
+func (f *fakeDynamoDB) DeleteItem(input *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	id := aws.StringValue(input.Key[keyAttribute].S)
+	item, ok := f.items[id]
+	if !ok || stringAttribute(item["Token"]) != aws.StringValue(input.ExpressionAttributeValues[":token"].S) {
+		return nil, awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "not held", nil)
+	}
+	delete(f.items, id)
+	return &dynamodb.DeleteItemOutput{}, nil
+}
+
+func (f *fakeDynamoDB) DeleteItemWithContext(_ aws.Context, input *dynamodb.DeleteItemInput, _ ...request.Option) (*dynamodb.DeleteItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	id := aws.StringValue(input.Key[keyAttribute].S)
+	item := f.items[id]
+	delete(f.items, id)
+	return &dynamodb.DeleteItemOutput{Attributes: item}, nil
+}
+
+func (f *fakeDynamoDB) CreateTableWithContext(aws.Context, *dynamodb.CreateTableInput, ...request.Option) (*dynamodb.CreateTableOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.created = true
+	f.items = map[string]map[string]*dynamodb.AttributeValue{}
+	return &dynamodb.CreateTableOutput{}, nil
+}
+
+func (f *fakeDynamoDB) WaitUntilTableExistsWithContext(aws.Context, *dynamodb.DescribeTableInput, ...request.WaiterOption) error {
+	return nil
+}
+
+func TestAcquire(t *testing.T) {
+	ctx := context.Background()
+	client := &fakeDynamoDB{}
+	now := time.Unix(1700000000, 0)
+	locker := New(client, "telophase-locks", time.Hour)
+	locker.now = func() time.Time { return now }
+
+	first, err := locker.Acquire(ctx, "org-111111111111", "alice@ci", "deploy")
+	require.NoError(t, err)
+	assert.True(t, client.created, "the table is created on first use")
+
+	_, err = locker.Acquire(ctx, "org-111111111111", "bob@ci", "destroy")
+	var lockedErr *LockedError
+	require.True(t, errors.As(err, &lockedErr))
+	assert.Equal(t, "alice@ci", lockedErr.Info.Owner)
+	assert.Equal(t, "deploy", lockedErr.Info.Command)
+	assert.Equal(t, now.Add(time.Hour).Unix(), lockedErr.Info.ExpiresAt.Unix())
+
+	// Other organizations have their own lock.
+	other, err := locker.Acquire(ctx, "org-222222222222", "bob@ci", "deploy")
+	require.NoError(t, err)
+	require.NoError(t, other.Release())
+
+	require.NoError(t, first.Release())
+	holder, err := locker.Get(ctx, "org-111111111111")
+	require.NoError(t, err)
+	assert.Nil(t, holder)
+
+	second, err := locker.Acquire(ctx, "org-111111111111", "bob@ci", "destroy")
+	require.NoError(t, err)
+	defer second.Release()
+	assert.NoError(t, first.Release(), "releasing twice does nothing")
+	holder, err = locker.Get(ctx, "org-111111111111")
+	require.NoError(t, err)
+	assert.Equal(t, "bob@ci", holder.Owner)
+}
+
+func TestAcquireExpired(t *testing.T) {
+	ctx := context.Background()
+	client := &fakeDynamoDB{}
+	now := time.Unix(1700000000, 0)
+	locker := New(client, "telophase-locks", time.Hour)
+	locker.now = func() time.Time { return now }
+
+	stale, err := locker.Acquire(ctx, "org-111111111111", "alice@ci", "deploy")
+	require.NoError(t, err)
+
+	now = now.Add(2 * time.Hour)
+	fresh, err := locker.Acquire(ctx, "org-111111111111", "bob@ci", "deploy")
+	require.NoError(t, err)
+
+	// The expired owner can't release the new owner's lock.
+	require.NoError(t, stale.Release())
+	holder, err := locker.Get(ctx, "org-111111111111")
+	require.NoError(t, err)
+	assert.Equal(t, "bob@ci", holder.Owner)
+
+	holder, err = locker.ForceUnlock(ctx, "org-111111111111")
+	require.NoError(t, err)
+	assert.Equal(t, "bob@ci", holder.Owner)
+	holder, err = locker.Get(ctx, "org-111111111111")
+	require.NoError(t, err)
+	assert.Nil(t, holder)
+	assert.NoError(t, fresh.Release())
+}
+
+func TestLockLost(t *testing.T) {
+	tests := []struct {
+		name string
+		lose func(*fakeDynamoDB, *Locker)
+	}{
+		{
+			name: "force unlocked",
+			lose: func(_ *fakeDynamoDB, locker *Locker) {
+				locker.ForceUnlock(context.Background(), "org-111111111111")
+			},
+		},
+		{
+			name: "renewals fail",
+			lose: func(client *fakeDynamoDB, _ *Locker) {
+				client.mu.Lock()
+				defer client.mu.Unlock()
+				client.updateErr = awserr.New("ThrottlingException", "slow down", nil)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client := &fakeDynamoDB{}
+			locker := New(client, "telophase-locks", 60*time.Millisecond)
+
+			l, err := locker.Acquire(context.Background(), "org-111111111111", "alice@ci", "deploy")
+			require.NoError(t, err)
+			defer l.Release()
+
+			// The lock is renewed while it is held.
+			time.Sleep(100 * time.Millisecond)
+			assert.NoError(t, l.Err())
+
+			tc.lose(client, locker)
+			select {
+			case <-l.Lost():
+			case <-time.After(time.Second):
+				t.Fatal("lock was not lost")
+			}
+			assert.Error(t, l.Err())
+		})
+	}
+}
@@ -0,0 +1,162 @@
+// Package outputs passes the outputs of stacks into the stacks that run after
+// them with ${telophase.outputs.<account>.<stack>.<key>} references.
+package outputs
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+)
+
+var referenceRegex = regexp.MustCompile(`\$\{telophase\.outputs\.([^.}]+)\.([^.}]+)\.([^}]+)\}`)
+
+// Reference is an output of a stack referenced by another stack.
+type Reference struct {
+	// Account is the AccountName of the account the stack is deployed to.
+	Account string
+	// Stack is the Name of the stack.
+	Stack string
+	Key   string
+}
+
+func (r Reference) String() string {
+	return fmt.Sprintf("${telophase.outputs.%s.%s.%s}", r.Account, r.Stack, r.Key)
+}
+
+// References returns the output references in s.
+func References(s string) []Reference {
+	var refs []Reference
+	for _, match := range referenceRegex.FindAllStringSubmatch(s, -1) {
+		refs = append(refs, Reference{Account: match[1], Stack: match[2], Key: match[3]})
+	}
+	return refs
+}
+
+// Computed is what references resolve to in a diff when the referenced stack
+// has not been deployed yet.
+const Computed = "<computed>"
+
+// Store holds the outputs of the stacks that have run. It is safe to use
+// concurrently.
+type Store struct {
+	mu      sync.Mutex
+	outputs map[string]map[string]*stackOutputs
+	// computeMissing resolves references to outputs that don't exist to
+	// Computed instead of failing.
+	computeMissing bool
+}
+
+// stackOutputs are the outputs of a stack and the region it ran in.
+type stackOutputs struct {
+	region string
+	values map[string]string
+	// multiRegion is set when the stack ran in more than one region, so a
+	// reference can't tell which region's outputs it means.
+	multiRegion bool
+}
+
+func NewStore() *Store {
+	return &Store{
+		outputs: make(map[string]map[string]*stackOutputs),
+	}
+}
+
+// NewDiffStore returns a Store for diffs. Stacks that have not been deployed
+// yet have no outputs, so references to them resolve to Computed.
+func NewDiffStore() *Store {
+	store := NewStore()
+	store.computeMissing = true
+	return store
+}
+
+// Set records the outputs of stack in account that ran in region.
+func (s *Store) Set(account, stack, region string, values map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.outputs[account] == nil {
+		s.outputs[account] = make(map[string]*stackOutputs)
+	}
+	previous := s.outputs[account][stack]
+	s.outputs[account][stack] = &stackOutputs{
+		region:      region,
+		values:      values,
+		multiRegion: previous != nil && (previous.multiRegion || previous.region != region),
+	}
+}
+
+// Get returns the output referenced by ref.
+func (s *Store) Get(ref Reference) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stack, ok := s.outputs[ref.Account][ref.Stack]
+	if !ok {
+		return "", false
+	}
+	value, ok := stack.values[ref.Key]
+	return value, ok
+}
+
+// Outputs returns the outputs of stack in account.
+func (s *Store) Outputs(account, stack string) (map[string]string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	outputs, ok := s.outputs[account][stack]
+	if !ok {
+		return nil, false
+	}
+	return outputs.values, true
+}
+
+func (s *Store) resolve(ref Reference) (string, error) {
+	s.mu.Lock()
+	stack, ok := s.outputs[ref.Account][ref.Stack]
+	s.mu.Unlock()
+
+	if ok && stack.multiRegion {
+		return "", fmt.Errorf("%s can't be resolved, stack %s in account %s runs in more than one region", ref, ref.Stack, ref.Account)
+	}
+	if value, ok := s.Get(ref); ok {
+		return value, nil
+	}
+	if s.computeMissing {
+		return Computed, nil
+	}
+	return "", fmt.Errorf("%s not found, stack %s in account %s has to run first and have an output named %s", ref, ref.Stack, ref.Account, ref.Key)
+}
+
+// Resolve replaces the output references in str with their values. A nil
+// Store leaves str unchanged.
+func (s *Store) Resolve(str string) (string, error) {
+	if s == nil {
+		return str, nil
+	}
+
+	var resolveErr error
+	resolved := referenceRegex.ReplaceAllStringFunc(str, func(match string) string {
+		value, err := s.resolve(References(match)[0])
+		if err != nil && resolveErr == nil {
+			resolveErr = err
+		}
+		return value
+	})
+	if resolveErr != nil {
+		return "", resolveErr
+	}
+	return resolved, nil
+}
+
+// ResolveAll resolves the output references in each of values.
+func (s *Store) ResolveAll(values []string) ([]string, error) {
+	var resolved []string
+	for _, value := range values {
+		r, err := s.Resolve(value)
+		if err != nil {
+			return nil, err
+		}
+		resolved = append(resolved, r)
+	}
+	return resolved, nil
+}
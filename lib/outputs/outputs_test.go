@@ -0,0 +1,56 @@
+package outputs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReferences(t *testing.T) {
+	assert.Equal(t, []Reference{
+		{Account: "log-archive", Stack: "bucket", Key: "BucketArn"},
+		{Account: "prod", Stack: "vpc", Key: "subnet.ids"},
+	}, References(`bucket = "${telophase.outputs.log-archive.bucket.BucketArn}" subnets = ${telophase.outputs.prod.vpc.subnet.ids}`))
+
+	assert.Empty(t, References("${telophase.account_id} ${telophase.outputs.prod}"))
+}
+
+func TestResolve(t *testing.T) {
+	store := NewStore()
+	store.Set("log-archive", "bucket", "us-east-1", map[string]string{"BucketArn": "arn:aws:s3:::logs"})
+
+	resolved, err := store.Resolve("BucketArn=${telophase.outputs.log-archive.bucket.BucketArn}")
+	require.NoError(t, err)
+	assert.Equal(t, "BucketArn=arn:aws:s3:::logs", resolved)
+
+	_, err = store.Resolve("${telophase.outputs.log-archive.bucket.Missing}")
+	assert.EqualError(t, err, "${telophase.outputs.log-archive.bucket.Missing} not found, stack bucket in account log-archive has to run first and have an output named Missing")
+
+	var nilStore *Store
+	resolved, err = nilStore.Resolve("${telophase.outputs.log-archive.bucket.BucketArn}")
+	require.NoError(t, err)
+	assert.Equal(t, "${telophase.outputs.log-archive.bucket.BucketArn}", resolved)
+}
+
+func TestResolveMultiRegion(t *testing.T) {
+	store := NewStore()
+	store.Set("prod", "vpc", "us-east-1", map[string]string{"VpcId": "vpc-1"})
+	store.Set("prod", "vpc", "us-east-1", map[string]string{"VpcId": "vpc-1"})
+	resolved, err := store.Resolve("${telophase.outputs.prod.vpc.VpcId}")
+	require.NoError(t, err)
+	assert.Equal(t, "vpc-1", resolved)
+
+	store.Set("prod", "vpc", "us-west-2", map[string]string{"VpcId": "vpc-2"})
+	_, err = store.Resolve("${telophase.outputs.prod.vpc.VpcId}")
+	assert.EqualError(t, err, "${telophase.outputs.prod.vpc.VpcId} can't be resolved, stack vpc in account prod runs in more than one region")
+}
+
+func TestResolveDiff(t *testing.T) {
+	store := NewDiffStore()
+	store.Set("log-archive", "bucket", "", map[string]string{"BucketArn": "arn:aws:s3:::logs"})
+
+	resolved, err := store.Resolve("${telophase.outputs.log-archive.bucket.BucketArn} ${telophase.outputs.log-archive.trail.TrailArn}")
+	require.NoError(t, err)
+	assert.Equal(t, "arn:aws:s3:::logs <computed>", resolved)
+}
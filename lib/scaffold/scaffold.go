@@ -0,0 +1,147 @@
+// Package scaffold generates a starter telophase directory with an
+// organization.yml and example stacks.
+package scaffold
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/samsarahq/go/oops"
+	"github.com/santiago-labs/telophasecli/resource"
+	"gopkg.in/yaml.v3"
+)
+
+// The templates end in .tmpl so that the example CDK app's go.mod does not
+// make the directory a separate module that can't be embedded.
+//
+//go:embed templates
+var templates embed.FS
+
+// Organization is the live AWS Organization the scaffold is generated for.
+type Organization struct {
+	RootID            string
+	ManagementAccount resource.Account
+	// OUs are the Organization Units under the root.
+	OUs []*resource.OrganizationUnit
+	// Region the example stacks are deployed to.
+	Region     string
+	LocalStack bool
+}
+
+type templateData struct {
+	RootID                string
+	ManagementAccountName string
+	ManagementAccountID   string
+	OUs                   string
+	Region                string
+	LocalStack            bool
+}
+
+// ouEntry is an existing Organization Unit in the generated organization.yml.
+type ouEntry struct {
+	Name              string    `yaml:"Name"`
+	OrganizationUnits []ouEntry `yaml:"OrganizationUnits,omitempty"`
+}
+
+// Write generates the scaffold in dir and returns the paths of the files it
+// wrote. Nothing is written if any of the files already exist.
+func Write(dir string, org Organization) ([]string, error) {
+	data, err := newTemplateData(org)
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string][]byte{}
+	var paths []string
+	err = fs.WalkDir(templates, "templates", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		tmpl, err := template.New(filepath.Base(path)).Funcs(template.FuncMap{
+			"indent": indent,
+		}).ParseFS(templates, path)
+		if err != nil {
+			return oops.Wrapf(err, "parse template %s", path)
+		}
+
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return oops.Wrapf(err, "execute template %s", path)
+		}
+
+		target := filepath.Join(dir, strings.TrimSuffix(strings.TrimPrefix(path, "templates/"), ".tmpl"))
+		files[target] = buf.Bytes()
+		paths = append(paths, target)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, path := range paths {
+		if _, err := os.Stat(path); err == nil {
+			return nil, fmt.Errorf("file %s already exists we will not overwrite it", path)
+		}
+	}
+
+	for _, path := range paths {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(path, files[path], 0644); err != nil {
+			return nil, err
+		}
+	}
+
+	return paths, nil
+}
+
+func newTemplateData(org Organization) (templateData, error) {
+	data := templateData{
+		RootID:                org.RootID,
+		ManagementAccountName: org.ManagementAccount.AccountName,
+		ManagementAccountID:   org.ManagementAccount.AccountID,
+		Region:                org.Region,
+		LocalStack:            org.LocalStack,
+	}
+
+	if len(org.OUs) == 0 {
+		return data, nil
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(4)
+	if err := enc.Encode(ouEntries(org.OUs)); err != nil {
+		return data, oops.Wrapf(err, "encode OUs")
+	}
+	if err := enc.Close(); err != nil {
+		return data, oops.Wrapf(err, "encode OUs")
+	}
+	data.OUs = strings.TrimSuffix(buf.String(), "\n")
+
+	return data, nil
+}
+
+func ouEntries(ous []*resource.OrganizationUnit) []ouEntry {
+	var entries []ouEntry
+	for _, ou := range ous {
+		entries = append(entries, ouEntry{
+			Name:              ou.OUName,
+			OrganizationUnits: ouEntries(ou.ChildOUs),
+		})
+	}
+	return entries
+}
+
+func indent(spaces int, s string) string {
+	prefix := strings.Repeat(" ", spaces)
+	return prefix + strings.ReplaceAll(s, "\n", "\n"+prefix)
+}
@@ -0,0 +1,62 @@
+package scaffold
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/santiago-labs/telophasecli/lib/ymlparser"
+	"github.com/santiago-labs/telophasecli/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+	org := Organization{
+		RootID:            "r-0000",
+		ManagementAccount: resource.Account{AccountID: "000000000000", AccountName: "mgmt"},
+		OUs: []*resource.OrganizationUnit{
+			{
+				OUName:   "Production",
+				ChildOUs: []*resource.OrganizationUnit{{OUName: "Tenants"}},
+			},
+			{OUName: "Development"},
+		},
+		Region: "us-east-1",
+	}
+
+	paths, err := Write(dir, org)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		filepath.Join(dir, "README.md"),
+		filepath.Join(dir, "organization.yml"),
+		filepath.Join(dir, "stacks/cdk/app.go"),
+		filepath.Join(dir, "stacks/cdk/cdk.json"),
+		filepath.Join(dir, "stacks/cdk/go.mod"),
+		filepath.Join(dir, "stacks/cloudformation/template.yml"),
+		filepath.Join(dir, "stacks/terraform/main.tf"),
+	}, paths)
+
+	data, err := os.ReadFile(filepath.Join(dir, "organization.yml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `    OrganizationUnits:
+        # Organization Units that already exist. Add the accounts in them with
+        # `+"`telophasecli account import --merge`"+`.
+        - Name: Production
+          OrganizationUnits:
+            - Name: Tenants
+        - Name: Development
+`)
+
+	// Stack paths in organization.yml are relative to where telophasecli is
+	// run.
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	defer os.Chdir(wd)
+	assert.Empty(t, ymlparser.ValidateOrganization("organization.yml"))
+
+	_, err = Write(dir, org)
+	assert.Error(t, err)
+}
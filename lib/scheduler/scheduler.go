@@ -0,0 +1,83 @@
+// Package scheduler limits how much work telophasecli runs at the same time.
+package scheduler
+
+import (
+	"context"
+	"sync"
+)
+
+// Scheduler queues work so that at most parallelism functions run at the same
+// time and at most regionParallelism of them in the same region. A limit of 0
+// is unlimited.
+type Scheduler struct {
+	slots             chan struct{}
+	regionParallelism int
+
+	lock        sync.Mutex
+	regionSlots map[string]chan struct{}
+}
+
+func New(parallelism, regionParallelism int) *Scheduler {
+	s := &Scheduler{
+		regionParallelism: regionParallelism,
+		regionSlots:       map[string]chan struct{}{},
+	}
+	if parallelism > 0 {
+		s.slots = make(chan struct{}, parallelism)
+	}
+	return s
+}
+
+// Run waits until there is a free slot for region and calls fn. An empty region
+// is only limited by the total parallelism, e.g. for global services.
+func (s *Scheduler) Run(ctx context.Context, region string, fn func() error) error {
+	// The region slot is taken first so that work waiting on a busy region
+	// doesn't hold a slot that work in another region could use.
+	regionSlots := s.regionSlotsFor(region)
+	if err := acquire(ctx, regionSlots); err != nil {
+		return err
+	}
+	defer release(regionSlots)
+
+	if err := acquire(ctx, s.slots); err != nil {
+		return err
+	}
+	defer release(s.slots)
+
+	return fn()
+}
+
+func (s *Scheduler) regionSlotsFor(region string) chan struct{} {
+	if region == "" || s.regionParallelism <= 0 {
+		return nil
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	slots, ok := s.regionSlots[region]
+	if !ok {
+		slots = make(chan struct{}, s.regionParallelism)
+		s.regionSlots[region] = slots
+	}
+	return slots
+}
+
+func acquire(ctx context.Context, slots chan struct{}) error {
+	if slots == nil {
+		return nil
+	}
+
+	select {
+	case slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func release(slots chan struct{}) {
+	if slots != nil {
+		<-slots
+	}
+}
@@ -0,0 +1,111 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchedulerLimits(t *testing.T) {
+	tests := []struct {
+		description       string
+		parallelism       int
+		regionParallelism int
+		regions           []string
+		wantMax           int64
+		wantMaxPerRegion  int64
+	}{
+		{
+			description: "total parallelism",
+			parallelism: 2,
+			regions:     []string{"us-east-1", "us-east-1", "us-west-2", "us-west-2", ""},
+			wantMax:     2,
+		},
+		{
+			description:       "region parallelism",
+			parallelism:       10,
+			regionParallelism: 1,
+			regions:           []string{"us-east-1", "us-east-1", "us-east-1", "us-west-2", "us-west-2"},
+			wantMax:           2,
+			wantMaxPerRegion:  1,
+		},
+		{
+			description: "unlimited",
+			regions:     []string{"us-east-1", "us-east-1", "us-east-1"},
+			wantMax:     3,
+		},
+	}
+
+	for _, tc := range tests {
+		s := New(tc.parallelism, tc.regionParallelism)
+
+		var running, maxRunning int64
+		perRegion := map[string]*int64{}
+		maxPerRegion := map[string]*int64{}
+		for _, region := range tc.regions {
+			perRegion[region] = new(int64)
+			maxPerRegion[region] = new(int64)
+		}
+
+		var wg sync.WaitGroup
+		for _, region := range tc.regions {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				s.Run(context.Background(), region, func() error {
+					setMax(&maxRunning, atomic.AddInt64(&running, 1))
+					setMax(maxPerRegion[region], atomic.AddInt64(perRegion[region], 1))
+					time.Sleep(20 * time.Millisecond)
+					atomic.AddInt64(perRegion[region], -1)
+					atomic.AddInt64(&running, -1)
+					return nil
+				})
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, tc.wantMax, maxRunning, tc.description)
+		if tc.wantMaxPerRegion > 0 {
+			for region, max := range maxPerRegion {
+				assert.Equal(t, tc.wantMaxPerRegion, *max, "%s %s", tc.description, region)
+			}
+		}
+	}
+}
+
+func TestSchedulerCanceled(t *testing.T) {
+	s := New(1, 0)
+	started := make(chan struct{})
+	block := make(chan struct{})
+	go s.Run(context.Background(), "", func() error {
+		close(started)
+		<-block
+		return nil
+	})
+	defer close(block)
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	var called bool
+	err := s.Run(ctx, "", func() error {
+		called = true
+		return nil
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.False(t, called)
+}
+
+func setMax(max *int64, v int64) {
+	for {
+		curr := atomic.LoadInt64(max)
+		if v <= curr || atomic.CompareAndSwapInt64(max, curr, v) {
+			return
+		}
+	}
+}
@@ -3,6 +3,7 @@ package terraform
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"io/fs"
 	"os"
@@ -11,6 +12,7 @@ import (
 	"strings"
 
 	"github.com/samsarahq/go/oops"
+	"github.com/santiago-labs/telophasecli/lib/outputs"
 	"github.com/santiago-labs/telophasecli/resource"
 )
 
@@ -23,7 +25,9 @@ func TmpPath(acct resource.Account, filePath string) string {
 	return path.Join("telophasedirs", fmt.Sprintf("tf-tmp%s-%s", acct.ID(), hashString))
 }
 
-func CopyDir(stack resource.Stack, dst string, resource resource.Resource) error {
+// CopyDir copies the stack to dst, substituting the telophase variables and
+// the output references resolved from store in every file.
+func CopyDir(stack resource.Stack, dst string, resource resource.Resource, store *outputs.Store) error {
 	ignoreDir := "telophasedirs"
 
 	abs, err := filepath.Abs(stack.Path)
@@ -45,12 +49,12 @@ func CopyDir(stack resource.Stack, dst string, resource resource.Resource) error
 		if info.IsDir() {
 			return os.MkdirAll(targetPath, info.Mode())
 		} else {
-			return replaceVariablesInFile(path, targetPath, resource, stack)
+			return replaceVariablesInFile(path, targetPath, resource, stack, store)
 		}
 	})
 }
 
-func replaceVariablesInFile(srcFile, dstFile string, resource resource.Resource, stack resource.Stack) error {
+func replaceVariablesInFile(srcFile, dstFile string, resource resource.Resource, stack resource.Stack, store *outputs.Store) error {
 	fileInfo, err := os.Stat(srcFile)
 	if err != nil {
 		return oops.Wrapf(err, "error accessing file %s", srcFile)
@@ -80,5 +84,32 @@ func replaceVariablesInFile(srcFile, dstFile string, resource resource.Resource,
 		return oops.Errorf("Region needs to be set on stack if performing substitution")
 	}
 
+	updatedContent, err = store.Resolve(updatedContent)
+	if err != nil {
+		return oops.Wrapf(err, "resolving outputs in %s", srcFile)
+	}
+
 	return os.WriteFile(dstFile, []byte(updatedContent), fileInfo.Mode())
 }
+
+// ParseOutputs returns the values of the output of `terraform output -json`.
+// Values that aren't strings are JSON encoded.
+func ParseOutputs(data []byte) (map[string]string, error) {
+	var tfOutputs map[string]struct {
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal(data, &tfOutputs); err != nil {
+		return nil, oops.Wrapf(err, "parsing terraform outputs")
+	}
+
+	values := make(map[string]string, len(tfOutputs))
+	for key, output := range tfOutputs {
+		var str string
+		if err := json.Unmarshal(output.Value, &str); err == nil {
+			values[key] = str
+			continue
+		}
+		values[key] = string(output.Value)
+	}
+	return values, nil
+}
@@ -0,0 +1,410 @@
+package ymlparser
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/samsarahq/go/oops"
+	"github.com/santiago-labs/telophasecli/lib/awsorgs"
+	"github.com/santiago-labs/telophasecli/resource"
+	"gopkg.in/yaml.v3"
+)
+
+// ImportedOrganization is an AWS Organization fetched by `account import`.
+type ImportedOrganization struct {
+	Root *resource.OrganizationUnit
+
+	// ServiceControlPolicies are the names of the Service Control Policies
+	// attached to each OU and account keyed by ID. AWS managed policies are not
+	// included because they are attached to every target.
+	ServiceControlPolicies map[string][]string
+}
+
+// NewImportedOrganization adds the tags, delegated administrator services and
+// Service Control Policies of every OU and account to root, an organization
+// fetched with FetchOUAndDescendents.
+func NewImportedOrganization(ctx context.Context, orgClient awsorgs.Client, root *resource.OrganizationUnit) (*ImportedOrganization, error) {
+	delegatedAdmins, err := orgClient.FetchDelegatedAdminPrincipals(ctx)
+	if err != nil {
+		return nil, oops.Wrapf(err, "FetchDelegatedAdminPrincipals")
+	}
+
+	imported := &ImportedOrganization{
+		Root:                   root,
+		ServiceControlPolicies: map[string][]string{},
+	}
+	if err := imported.hydrate(ctx, orgClient, root, nil, delegatedAdmins); err != nil {
+		return nil, err
+	}
+
+	return imported, nil
+}
+
+// hydrate sets the tags of ou and its descendents. Tags inherited from a parent
+// OU are left out so they are only written once.
+func (i *ImportedOrganization) hydrate(
+	ctx context.Context,
+	orgClient awsorgs.Client,
+	ou *resource.OrganizationUnit,
+	inheritedTags []string,
+	delegatedAdmins map[string][]string,
+) error {
+	if err := i.fetchServiceControlPolicies(ctx, orgClient, ou.ID()); err != nil {
+		return err
+	}
+
+	// The root can't be tagged.
+	if ou.Parent != nil {
+		tags, err := orgClient.GetTags(ctx, ou.ID())
+		if err != nil {
+			return oops.Wrapf(err, "GetTags OUID: %s", ou.ID())
+		}
+		ou.Tags = importableTags(tags, inheritedTags)
+		inheritedTags = append(append([]string{}, inheritedTags...), ou.Tags...)
+	}
+
+	for _, acct := range ou.Accounts {
+		acct.Parent = ou
+		if err := i.fetchServiceControlPolicies(ctx, orgClient, acct.AccountID); err != nil {
+			return err
+		}
+
+		tags, err := orgClient.GetTags(ctx, acct.AccountID)
+		if err != nil {
+			return oops.Wrapf(err, "GetTags Account ID: %s", acct.AccountID)
+		}
+		acct.Tags = importableTags(tags, append([]string{"AccountName=" + acct.AccountName}, inheritedTags...))
+		acct.DelegatedAdministratorServices = delegatedAdmins[acct.AccountID]
+	}
+
+	for _, child := range ou.ChildOUs {
+		child.Parent = ou
+		if err := i.hydrate(ctx, orgClient, child, inheritedTags, delegatedAdmins); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (i *ImportedOrganization) fetchServiceControlPolicies(ctx context.Context, orgClient awsorgs.Client, targetID string) error {
+	policies, err := orgClient.ListServiceControlPolicies(ctx, targetID)
+	if err != nil {
+		return err
+	}
+
+	for _, policy := range policies {
+		if aws.BoolValue(policy.AwsManaged) {
+			continue
+		}
+		i.ServiceControlPolicies[targetID] = append(
+			i.ServiceControlPolicies[targetID],
+			fmt.Sprintf("%s (%s)", aws.StringValue(policy.Name), aws.StringValue(policy.Id)),
+		)
+	}
+
+	return nil
+}
+
+func importableTags(tags, inheritedTags []string) []string {
+	var result []string
+	for _, tag := range tags {
+		if resource.IgnorableTag(tag) || oneOf(tag, inheritedTags) {
+			continue
+		}
+		result = append(result, tag)
+	}
+	return result
+}
+
+func oneOf(s string, options []string) bool {
+	for _, option := range options {
+		if s == option {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteImportedOrgFile writes the imported organization to a new file at
+// filepath. Service Control Policies are written as comments because their
+// definitions are not imported.
+func WriteImportedOrgFile(filepath string, imported *ImportedOrganization) error {
+	if fileExists(filepath) {
+		return fmt.Errorf("file %s already exists we will not overwrite it", filepath)
+	}
+
+	var doc yaml.Node
+	if err := doc.Encode(orgDatav2{Organization: *imported.Root}); err != nil {
+		return oops.Wrapf(err, "Encode")
+	}
+	_, ouNode := mappingValue(&doc, "Organization")
+	imported.commentOU(ouNode, imported.Root)
+
+	return writeYAMLNode(filepath, &doc)
+}
+
+// commentOU adds the Service Control Policies attached to ou and its
+// descendents as comments on node, the encoded ou.
+func (i *ImportedOrganization) commentOU(node *yaml.Node, ou *resource.OrganizationUnit) {
+	i.comment(node, ou.ID())
+
+	if _, accounts := mappingValue(node, "Accounts"); accounts != nil {
+		for idx, acct := range ou.Accounts {
+			if idx < len(accounts.Content) {
+				i.comment(accounts.Content[idx], acct.AccountID)
+			}
+		}
+	}
+
+	if _, children := mappingValue(node, "OrganizationUnits"); children != nil {
+		for idx, child := range ou.ChildOUs {
+			if idx < len(children.Content) {
+				i.commentOU(children.Content[idx], child)
+			}
+		}
+	}
+}
+
+func (i *ImportedOrganization) comment(node *yaml.Node, id string) {
+	policies := i.ServiceControlPolicies[id]
+	if len(policies) == 0 || node.Kind != yaml.MappingNode || len(node.Content) == 0 {
+		return
+	}
+
+	lines := []string{"Service Control Policies attached in AWS that are not managed by telophase:"}
+	for _, policy := range policies {
+		lines = append(lines, "- "+policy)
+	}
+	node.Content[0].HeadComment = strings.Join(lines, "\n")
+}
+
+// MergeOrgFile adds the OUs and accounts of the imported organization that are
+// not in the organization file at filepath, including the files referenced by
+// OUFilepath. OUs are matched by name under the same parent and accounts by
+// email. Existing comments and stacks are kept. It returns a description of
+// every OU and account added.
+func MergeOrgFile(filepath string, imported *ImportedOrganization) ([]string, error) {
+	m := &orgFileMerger{
+		imported: imported,
+		docs:     map[string]*yaml.Node{},
+		modified: map[string]bool{},
+		emails:   map[string]bool{},
+	}
+
+	doc, err := m.load(filepath)
+	if err != nil {
+		return nil, err
+	}
+	_, ouNode := mappingValue(doc.Content[0], "Organization")
+	if ouNode == nil {
+		return nil, fmt.Errorf("file %s has no Organization", filepath)
+	}
+
+	if err := m.collectEmails(ouNode, filepath); err != nil {
+		return nil, err
+	}
+	if err := m.merge(ouNode, filepath, imported.Root); err != nil {
+		return nil, err
+	}
+
+	for _, path := range m.order {
+		if !m.modified[path] {
+			continue
+		}
+		if err := writeYAMLNode(path, m.docs[path]); err != nil {
+			return nil, err
+		}
+	}
+
+	return m.added, nil
+}
+
+type orgFileMerger struct {
+	imported *ImportedOrganization
+
+	// docs are the parsed files keyed by path, in the order they were read.
+	docs     map[string]*yaml.Node
+	order    []string
+	modified map[string]bool
+	emails   map[string]bool
+	added    []string
+}
+
+func (m *orgFileMerger) load(path string) (*yaml.Node, error) {
+	if doc, ok := m.docs[path]; ok {
+		return doc, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("err: %s reading file %s", err.Error(), path)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, oops.Wrapf(err, "Unmarshal %s", path)
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return nil, fmt.Errorf("file %s is empty", path)
+	}
+
+	m.docs[path] = &doc
+	m.order = append(m.order, path)
+	return &doc, nil
+}
+
+// resolve returns the OU node that node points to with OUFilepath and the file
+// it is in.
+func (m *orgFileMerger) resolve(node *yaml.Node, path string) (*yaml.Node, string, error) {
+	_, ouFilepath := mappingValue(node, "OUFilepath")
+	if ouFilepath == nil {
+		return node, path, nil
+	}
+
+	doc, err := m.load(ouFilepath.Value)
+	if err != nil {
+		return nil, "", err
+	}
+	return doc.Content[0], ouFilepath.Value, nil
+}
+
+func (m *orgFileMerger) collectEmails(node *yaml.Node, path string) error {
+	node, path, err := m.resolve(node, path)
+	if err != nil {
+		return err
+	}
+
+	if _, accounts := mappingValue(node, "Accounts"); accounts != nil {
+		for _, acct := range accounts.Content {
+			if _, email := mappingValue(acct, "Email"); email != nil {
+				m.emails[email.Value] = true
+			}
+		}
+	}
+
+	if _, children := mappingValue(node, "OrganizationUnits"); children != nil {
+		for _, child := range children.Content {
+			if err := m.collectEmails(child, path); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (m *orgFileMerger) merge(node *yaml.Node, path string, ou *resource.OrganizationUnit) error {
+	node, path, err := m.resolve(node, path)
+	if err != nil {
+		return err
+	}
+
+	for _, acct := range ou.Accounts {
+		// The management account does not need to be managed by Telophase.
+		if m.emails[acct.Email] || acct.ManagementAccount {
+			continue
+		}
+
+		var acctNode yaml.Node
+		if err := acctNode.Encode(acct); err != nil {
+			return oops.Wrapf(err, "Encode account %s", acct.Email)
+		}
+		m.imported.comment(&acctNode, acct.AccountID)
+		m.appendTo(node, "Accounts", &acctNode, path)
+		m.emails[acct.Email] = true
+		m.added = append(m.added, fmt.Sprintf("Account %s (%s) in Organization Unit %s", acct.AccountName, acct.Email, ou.OUName))
+	}
+
+	for _, child := range ou.ChildOUs {
+		childNode, childPath, err := m.findOU(node, path, child.OUName)
+		if err != nil {
+			return err
+		}
+		if childNode != nil {
+			if err := m.merge(childNode, childPath, child); err != nil {
+				return err
+			}
+			continue
+		}
+
+		newOU := m.newOU(child)
+		var ouNode yaml.Node
+		if err := ouNode.Encode(newOU); err != nil {
+			return oops.Wrapf(err, "Encode Organization Unit %s", child.OUName)
+		}
+		m.imported.commentOU(&ouNode, newOU)
+		m.appendTo(node, "OrganizationUnits", &ouNode, path)
+		m.added = append(m.added, fmt.Sprintf("Organization Unit %s in %s", child.OUName, ou.OUName))
+	}
+
+	return nil
+}
+
+func (m *orgFileMerger) findOU(node *yaml.Node, path, name string) (*yaml.Node, string, error) {
+	_, children := mappingValue(node, "OrganizationUnits")
+	if children == nil {
+		return nil, "", nil
+	}
+
+	for _, child := range children.Content {
+		childNode, childPath, err := m.resolve(child, path)
+		if err != nil {
+			return nil, "", err
+		}
+		if _, childName := mappingValue(childNode, "Name"); childName != nil && strings.TrimSpace(childName.Value) == name {
+			return childNode, childPath, nil
+		}
+	}
+
+	return nil, "", nil
+}
+
+// newOU copies ou without the accounts that are already in the organization
+// file, for example accounts that were moved to ou outside of telophase.
+func (m *orgFileMerger) newOU(ou *resource.OrganizationUnit) *resource.OrganizationUnit {
+	result := *ou
+	result.Accounts = nil
+	result.ChildOUs = nil
+
+	for _, acct := range ou.Accounts {
+		if m.emails[acct.Email] || acct.ManagementAccount {
+			continue
+		}
+		m.emails[acct.Email] = true
+		result.Accounts = append(result.Accounts, acct)
+	}
+	for _, child := range ou.ChildOUs {
+		result.ChildOUs = append(result.ChildOUs, m.newOU(child))
+	}
+
+	return &result
+}
+
+func (m *orgFileMerger) appendTo(node *yaml.Node, key string, item *yaml.Node, path string) {
+	_, seq := mappingValue(node, key)
+	if seq == nil {
+		seq = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, seq)
+	}
+	seq.Content = append(seq.Content, item)
+	m.modified[path] = true
+}
+
+func writeYAMLNode(filepath string, node *yaml.Node) error {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(4)
+	if err := enc.Encode(node); err != nil {
+		return oops.Wrapf(err, "Encode %s", filepath)
+	}
+	if err := enc.Close(); err != nil {
+		return oops.Wrapf(err, "Encode %s", filepath)
+	}
+
+	return os.WriteFile(filepath, buf.Bytes(), 0644)
+}
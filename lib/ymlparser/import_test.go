@@ -0,0 +1,166 @@
+package ymlparser
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/santiago-labs/telophasecli/lib/awsorgs"
+	"github.com/santiago-labs/telophasecli/lib/awsorgs/awsorgsmock"
+	"github.com/santiago-labs/telophasecli/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// importedOU is the organization in awsorgsmock as returned by
+// FetchOUAndDescendents.
+func importedOU() *resource.OrganizationUnit {
+	root := &resource.OrganizationUnit{OUName: "root", OUID: aws.String("r-0000")}
+	tenants := &resource.OrganizationUnit{OUName: "ExampleOU", OUID: aws.String("1ou"), Parent: root}
+	acquired := &resource.OrganizationUnit{OUName: "Acquired", OUID: aws.String("2ou"), Parent: root}
+	root.ChildOUs = []*resource.OrganizationUnit{tenants, acquired}
+
+	root.Accounts = []*resource.Account{
+		{AccountID: "00000000000", AccountName: "test0", Email: "test0@example.com", ManagementAccount: true},
+	}
+	tenants.Accounts = []*resource.Account{
+		{AccountID: "10000000000", AccountName: "test1", Email: "test1@example.com"},
+		{AccountID: "40000000000", AccountName: "test4", Email: "test4@example.com"},
+	}
+	acquired.Accounts = []*resource.Account{
+		{AccountID: "30000000000", AccountName: "test3", Email: "test3@example.com"},
+	}
+	return root
+}
+
+func importedOrganization(t *testing.T) *ImportedOrganization {
+	mockClient := awsorgs.New(&awsorgs.Config{
+		OrganizationClient: awsorgsmock.New(),
+	})
+
+	imported, err := NewImportedOrganization(context.Background(), mockClient, importedOU())
+	require.NoError(t, err)
+	return imported
+}
+
+func TestNewImportedOrganization(t *testing.T) {
+	imported := importedOrganization(t)
+
+	tenants := imported.Root.ChildOUs[0]
+	assert.Equal(t, []string{"ou=ExampleTenants"}, tenants.Tags)
+	assert.Empty(t, tenants.Accounts[0].Tags)
+	assert.Equal(t, []string{"team=data"}, tenants.Accounts[1].Tags)
+	assert.Equal(t, []string{"guardduty.amazonaws.com"}, tenants.Accounts[1].DelegatedAdministratorServices)
+	assert.Equal(t, map[string][]string{
+		"1ou": {"DenyRegions (p-denyregions)"},
+	}, imported.ServiceControlPolicies)
+}
+
+func TestWriteImportedOrgFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "organization.yml")
+	require.NoError(t, WriteImportedOrgFile(path, importedOrganization(t)))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, `Organization:
+    Name: root
+    OrganizationUnits:
+        - # Service Control Policies attached in AWS that are not managed by telophase:
+          # - DenyRegions (p-denyregions)
+          Name: ExampleOU
+          Tags:
+            - ou=ExampleTenants
+          Accounts:
+            - Email: test1@example.com
+              AccountName: test1
+              Delete: false
+            - Email: test4@example.com
+              AccountName: test4
+              Tags:
+                - team=data
+              Delete: false
+              DelegatedAdministratorServices:
+                - guardduty.amazonaws.com
+        - Name: Acquired
+          Accounts:
+            - Email: test3@example.com
+              AccountName: test3
+              Delete: false
+    Accounts:
+        - Email: test0@example.com
+          AccountName: test0
+          Delete: false
+`, string(data))
+
+	assert.Error(t, WriteImportedOrgFile(path, importedOrganization(t)))
+}
+
+func TestMergeOrgFile(t *testing.T) {
+	dir := t.TempDir()
+	orgPath := filepath.Join(dir, "organization.yml")
+	childPath := filepath.Join(dir, "example-ou.yml")
+
+	require.NoError(t, os.WriteFile(orgPath, []byte(`Organization:
+    Name: root
+    OrganizationUnits:
+        # Tenants are managed in their own file.
+        - OUFilepath: `+childPath+`
+`), 0644))
+	require.NoError(t, os.WriteFile(childPath, []byte(`Name: ExampleOU
+Stacks:
+    - Type: CDK
+      Path: examples/localstack/s3-remote-state
+      Name: example
+Accounts:
+    # Moved from the Acquired OU.
+    - Email: test3@example.com
+      AccountName: test3
+    - Email: test1@example.com
+      AccountName: test1
+`), 0644))
+
+	added, err := MergeOrgFile(orgPath, importedOrganization(t))
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"Account test4 (test4@example.com) in Organization Unit ExampleOU",
+		"Organization Unit Acquired in root",
+	}, added)
+
+	data, err := os.ReadFile(orgPath)
+	require.NoError(t, err)
+	assert.Equal(t, `Organization:
+    Name: root
+    OrganizationUnits:
+        # Tenants are managed in their own file.
+        - OUFilepath: `+childPath+`
+        - Name: Acquired
+`, string(data))
+
+	data, err = os.ReadFile(childPath)
+	require.NoError(t, err)
+	assert.Equal(t, `Name: ExampleOU
+Stacks:
+    - Type: CDK
+      Path: examples/localstack/s3-remote-state
+      Name: example
+Accounts:
+    # Moved from the Acquired OU.
+    - Email: test3@example.com
+      AccountName: test3
+    - Email: test1@example.com
+      AccountName: test1
+    - Email: test4@example.com
+      AccountName: test4
+      Tags:
+        - team=data
+      Delete: false
+      DelegatedAdministratorServices:
+        - guardduty.amazonaws.com
+`, string(data))
+
+	added, err = MergeOrgFile(orgPath, importedOrganization(t))
+	require.NoError(t, err)
+	assert.Empty(t, added)
+}
@@ -98,7 +98,9 @@ func (p Parser) HydrateParsedOrg(ctx context.Context, parsedOrg *resource.Organi
 		Name: &rootName,
 	}
 	parsedOrg.OUName = "root"
-	p.hydrateOUID(parsedOrg, rootOU)
+	if err := p.hydrateOUID(parsedOrg, rootOU); err != nil {
+		return err
+	}
 	hydrateOUParent(parsedOrg)
 	hydrateAccountParent(parsedOrg)
 
@@ -141,41 +143,92 @@ func hydrateAccountParent(ou *resource.OrganizationUnit) {
 }
 
 func (p Parser) hydrateOUID(parsedOU *resource.OrganizationUnit, providerOU *organizations.OrganizationalUnit) error {
+	var providerChildren []*organizations.OrganizationalUnit
 	if providerOU != nil {
 		parsedOU.OUID = providerOU.Id
-		providerChildren, err := p.orgClient.GetOrganizationUnitChildren(context.TODO(), *parsedOU.OUID)
+		var err error
+		providerChildren, err = p.orgClient.GetOrganizationUnitChildren(context.TODO(), *parsedOU.OUID)
 		if err != nil {
 			return oops.Wrapf(err, "GetOrganizationUnitChildren for OUID: %s", *parsedOU.OUID)
 		}
+	}
 
-		for _, parsedChild := range parsedOU.ChildOUs {
-			var found bool
-			for _, providerChild := range providerChildren {
-				if parsedChild.OUName == *providerChild.Name {
-					found = true
-					err = p.hydrateOUID(parsedChild, providerChild)
-					if err != nil {
-						return err
-					}
-				}
-			}
+	for _, parsedChild := range parsedOU.ChildOUs {
+		providerChild, err := p.matchProviderOU(parsedChild, parsedOU.ChildOUs, providerChildren)
+		if err != nil {
+			return err
+		}
+		if err := p.hydrateOUID(parsedChild, providerChild); err != nil {
+			return err
+		}
+	}
 
-			if !found {
-				err := p.hydrateOUID(parsedChild, nil)
-				if err != nil {
-					return err
-				}
-			}
+	return nil
+}
+
+// matchProviderOU returns the OU in AWS for parsedOU, or nil if it doesn't
+// exist yet. An OU with a PinnedOUID is looked up by ID wherever it is in the
+// organization.
+func (p Parser) matchProviderOU(
+	parsedOU *resource.OrganizationUnit,
+	siblings []*resource.OrganizationUnit,
+	providerChildren []*organizations.OrganizationalUnit,
+) (*organizations.OrganizationalUnit, error) {
+	if parsedOU.PinnedOUID == "" {
+		return matchOUByName(parsedOU, siblings, providerChildren), nil
+	}
+
+	providerOU, err := p.orgClient.GetOrganizationUnit(context.TODO(), parsedOU.PinnedOUID)
+	if err == nil {
+		return providerOU, nil
+	}
+
+	// Moving an OU recreates it with a new ID, so a pinned OU that was moved
+	// is found by its name under its parent.
+	if providerChild := matchOUByName(parsedOU, siblings, providerChildren); providerChild != nil {
+		return nil, oops.Errorf("Organization Unit %s has OUID %s, which doesn't exist anymore. It was probably recreated by moving it, set its OUID to %s", parsedOU.OUName, parsedOU.PinnedOUID, aws.StringValue(providerChild.Id))
+	}
+	return nil, oops.Wrapf(err, "Organization Unit %s has OUID %s", parsedOU.OUName, parsedOU.PinnedOUID)
+}
+
+// matchOUByName returns the child OU in AWS named like parsedOU or, failing
+// that, like one of its PreviousNames. OUs pinned by a sibling are skipped, and
+// so are previous names a sibling still uses, so that an OU can be renamed and
+// a new OU can take its old name in the same deploy.
+func matchOUByName(
+	parsedOU *resource.OrganizationUnit,
+	siblings []*resource.OrganizationUnit,
+	providerChildren []*organizations.OrganizationalUnit,
+) *organizations.OrganizationalUnit {
+	pinned := map[string]bool{}
+	names := map[string]bool{}
+	for _, sibling := range siblings {
+		if sibling.PinnedOUID != "" {
+			pinned[sibling.PinnedOUID] = true
 		}
-	} else {
-		for _, parsedChild := range parsedOU.ChildOUs {
-			err := p.hydrateOUID(parsedChild, nil)
-			if err != nil {
-				return err
+		names[sibling.OUName] = true
+	}
+
+	find := func(name string) *organizations.OrganizationalUnit {
+		for _, providerChild := range providerChildren {
+			if aws.StringValue(providerChild.Name) == name && !pinned[aws.StringValue(providerChild.Id)] {
+				return providerChild
 			}
 		}
+		return nil
 	}
 
+	if providerChild := find(parsedOU.OUName); providerChild != nil {
+		return providerChild
+	}
+	for _, previousName := range parsedOU.PreviousNames {
+		if names[previousName] {
+			continue
+		}
+		if providerChild := find(previousName); providerChild != nil {
+			return providerChild
+		}
+	}
 	return nil
 }
 
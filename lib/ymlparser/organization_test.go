@@ -6,11 +6,16 @@ import (
 	"testing"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/organizations"
+	"github.com/aws/aws-sdk-go/service/organizations/organizationsiface"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/santiago-labs/telophasecli/lib/awsorgs"
 	"github.com/santiago-labs/telophasecli/lib/awsorgs/awsorgsmock"
 	"github.com/santiago-labs/telophasecli/resource"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -107,3 +112,84 @@ func TestParseOrganization(t *testing.T) {
 		}
 	}
 }
+
+func TestMatchOUByName(t *testing.T) {
+	providerChildren := []*organizations.OrganizationalUnit{
+		{Id: aws.String("ou-prod"), Name: aws.String("Prod")},
+		{Id: aws.String("ou-dev"), Name: aws.String("Dev")},
+	}
+
+	tests := []struct {
+		name     string
+		siblings []*resource.OrganizationUnit
+		want     *string
+	}{
+		{
+			name:     "matches by name",
+			siblings: []*resource.OrganizationUnit{{OUName: "Prod"}},
+			want:     aws.String("ou-prod"),
+		},
+		{
+			name:     "matches by previous name",
+			siblings: []*resource.OrganizationUnit{{OUName: "Production", PreviousNames: []string{"Old", "Prod"}}},
+			want:     aws.String("ou-prod"),
+		},
+		{
+			name: "previous name taken by a sibling",
+			siblings: []*resource.OrganizationUnit{
+				{OUName: "Production", PreviousNames: []string{"Prod"}},
+				{OUName: "Prod"},
+			},
+		},
+		{
+			name: "OU pinned by a sibling",
+			siblings: []*resource.OrganizationUnit{
+				{OUName: "Prod"},
+				{OUName: "Production", PinnedOUID: "ou-prod"},
+			},
+		},
+		{
+			name:     "new OU",
+			siblings: []*resource.OrganizationUnit{{OUName: "Staging", PreviousNames: []string{"Stage"}}},
+		},
+	}
+
+	for _, tc := range tests {
+		got := matchOUByName(tc.siblings[0], tc.siblings, providerChildren)
+		if tc.want == nil {
+			assert.Nil(t, got, tc.name)
+			continue
+		}
+		require.NotNil(t, got, tc.name)
+		assert.Equal(t, *tc.want, *got.Id, tc.name)
+	}
+}
+
+// deletedOUOrganizations has no OUs that can be described by ID, like an OU
+// that was deleted by moving it.
+type deletedOUOrganizations struct {
+	organizationsiface.OrganizationsAPI
+}
+
+func (m deletedOUOrganizations) DescribeOrganizationalUnitWithContext(aws.Context, *organizations.DescribeOrganizationalUnitInput, ...request.Option) (*organizations.DescribeOrganizationalUnitOutput, error) {
+	return nil, awserr.New(organizations.ErrCodeOrganizationalUnitNotFoundException, "not found", nil)
+}
+
+func TestMatchProviderOUMovedPinnedOU(t *testing.T) {
+	parser := NewParser(awsorgs.New(&awsorgs.Config{
+		OrganizationClient: deletedOUOrganizations{OrganizationsAPI: awsorgsmock.New()},
+	}))
+	providerChildren := []*organizations.OrganizationalUnit{
+		{Id: aws.String("ou-prod2"), Name: aws.String("Prod")},
+	}
+
+	moved := &resource.OrganizationUnit{OUName: "Prod", PinnedOUID: "ou-prod"}
+	_, err := parser.matchProviderOU(moved, []*resource.OrganizationUnit{moved}, providerChildren)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "set its OUID to ou-prod2")
+
+	deleted := &resource.OrganizationUnit{OUName: "Staging", PinnedOUID: "ou-staging"}
+	_, err = parser.matchProviderOU(deleted, []*resource.OrganizationUnit{deleted}, providerChildren)
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "set its OUID")
+}
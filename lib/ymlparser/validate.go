@@ -0,0 +1,593 @@
+package ymlparser
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/samsarahq/go/oops"
+	"github.com/santiago-labs/telophasecli/resource"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	yamlLineRegex     = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+	unknownFieldRegex = regexp.MustCompile(`^field (\S+) not found in type resource\.(\w+)$`)
+	// roleNameRegex matches IAM role names.
+	roleNameRegex = regexp.MustCompile(`^[\w+=,.@-]{1,64}$`)
+)
+
+// ValidationError is a problem found in an organization file. File and Line
+// point at the YAML node that caused the problem.
+type ValidationError struct {
+	File    string
+	Line    int
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("%s: %s", e.File, e.Message)
+	}
+	return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Message)
+}
+
+type location struct {
+	file string
+	line int
+}
+
+func (l location) String() string {
+	return fmt.Sprintf("%s:%d", l.file, l.line)
+}
+
+type policyLocation struct {
+	location
+	document string
+}
+
+type validator struct {
+	errs   []ValidationError
+	emails map[string]location
+	ouIDs  map[string]location
+	// policies are the documents of the Policy stacks by Name.
+	policies map[string]policyLocation
+	// files holds the OUFilepath chain currently being validated so that a
+	// file including itself is reported instead of recursing forever.
+	files []string
+}
+
+// ValidateOrganization parses the organization file at filepath, following
+// every OUFilepath, and returns all of the problems it finds. It does not make
+// any calls to AWS so it can run without credentials.
+func ValidateOrganization(filepath string) []ValidationError {
+	v := &validator{
+		emails:   make(map[string]location),
+		ouIDs:    make(map[string]location),
+		policies: make(map[string]policyLocation),
+	}
+
+	doc, ok := v.parseFile(filepath, &orgDatav2{})
+	if ok {
+		orgKey, orgNode := mappingValue(doc, "Organization")
+		if orgNode == nil {
+			v.addf(filepath, doc.Line, "missing top level Organization key")
+		} else {
+			v.validateOU(filepath, orgKey.Line, orgNode)
+		}
+	}
+
+	// Dependencies span files so they are checked on the parsed organization
+	// once every file is known to be valid.
+	if len(v.errs) == 0 {
+		v.validateDependencies(filepath)
+	}
+
+	sort.SliceStable(v.errs, func(i, j int) bool {
+		if v.errs[i].File != v.errs[j].File {
+			return v.errs[i].File < v.errs[j].File
+		}
+		return v.errs[i].Line < v.errs[j].Line
+	})
+
+	return v.errs
+}
+
+func (v *validator) addf(file string, line int, format string, a ...interface{}) {
+	v.errs = append(v.errs, ValidationError{
+		File:    file,
+		Line:    line,
+		Message: fmt.Sprintf(format, a...),
+	})
+}
+
+// parseFile reads the YAML file, reports syntax errors and unknown keys, and
+// returns the top level mapping node.
+func (v *validator) parseFile(filepath string, strict interface{}) (*yaml.Node, bool) {
+	data, err := os.ReadFile(filepath)
+	if err != nil {
+		v.addf(filepath, 0, "reading file: %s", err)
+		return nil, false
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		v.addYAMLError(filepath, err)
+		return nil, false
+	}
+	if len(doc.Content) == 0 {
+		v.addf(filepath, 0, "file is empty")
+		return nil, false
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(strict); err != nil {
+		v.addYAMLError(filepath, err)
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		v.addf(filepath, root.Line, "expected a mapping at the top level of the file")
+		return nil, false
+	}
+
+	return root, true
+}
+
+func (v *validator) addYAMLError(filepath string, err error) {
+	var typeErr *yaml.TypeError
+	if !errors.As(err, &typeErr) {
+		v.errs = append(v.errs, yamlMessageToError(filepath, err.Error()))
+		return
+	}
+
+	for _, msg := range typeErr.Errors {
+		v.errs = append(v.errs, yamlMessageToError(filepath, msg))
+	}
+}
+
+func yamlMessageToError(filepath, msg string) ValidationError {
+	matches := yamlLineRegex.FindStringSubmatch(msg)
+	if matches == nil {
+		return ValidationError{File: filepath, Message: msg}
+	}
+
+	line, _ := strconv.Atoi(matches[1])
+	msg = matches[2]
+	if field := unknownFieldRegex.FindStringSubmatch(msg); field != nil {
+		msg = fmt.Sprintf("unknown key %s in %s", field[1], field[2])
+	}
+
+	return ValidationError{File: filepath, Line: line, Message: msg}
+}
+
+// validateOU validates an Organization Unit mapping and returns the OU's name
+// so the caller can check for duplicate names under the same parent.
+func (v *validator) validateOU(file string, line int, node *yaml.Node) string {
+	if node.Kind != yaml.MappingNode {
+		v.addf(file, line, "expected a mapping for Organization Unit")
+		return ""
+	}
+
+	if pathKey, pathNode := mappingValue(node, "OUFilepath"); pathNode != nil {
+		if len(node.Content) > 2 {
+			v.addf(file, pathKey.Line, "OUFilepath cannot be combined with other keys, they are replaced by the contents of %s", pathNode.Value)
+		}
+		return v.validateOUFile(file, pathNode)
+	}
+
+	_, nameNode := mappingValue(node, "Name")
+	name := ""
+	if nameNode != nil {
+		name = nameNode.Value
+	}
+
+	if _, idNode := mappingValue(node, "OUID"); idNode != nil {
+		if !strings.HasPrefix(idNode.Value, "ou-") {
+			v.addf(file, idNode.Line, "OUID %s should be an Organization Unit ID like ou-ab12-cdef3456", idNode.Value)
+		} else if first, ok := v.ouIDs[idNode.Value]; ok {
+			v.addf(file, idNode.Line, "duplicate OUID %s, first used at %s", idNode.Value, first)
+		} else {
+			v.ouIDs[idNode.Value] = location{file: file, line: idNode.Line}
+		}
+	}
+
+	if _, previousNode := mappingValue(node, "PreviousNames"); previousNode != nil && previousNode.Kind == yaml.SequenceNode {
+		for _, previous := range previousNode.Content {
+			if previous.Value == name {
+				v.addf(file, previous.Line, "PreviousNames of Organization Unit %s should not include its Name", name)
+			}
+		}
+	}
+
+	if _, tagsNode := mappingValue(node, "Tags"); tagsNode != nil {
+		v.validateTags(file, tagsNode)
+	}
+
+	if _, stacksNode := mappingValue(node, "Stacks"); stacksNode != nil {
+		v.validateStacks(file, stacksNode, false)
+	}
+
+	if _, scpNode := mappingValue(node, "ServiceControlPolicies"); scpNode != nil {
+		v.validateStacks(file, scpNode, true)
+	}
+
+	if rolloutKey, rolloutNode := mappingValue(node, "Rollout"); rolloutNode != nil {
+		var rollout resource.Rollout
+		// Decoding errors are reported by the strict decode of the file.
+		if err := rolloutNode.Decode(&rollout); err == nil {
+			if err := rollout.Validate(); err != nil {
+				v.addf(file, rolloutKey.Line, "%s", err)
+			}
+		}
+	}
+
+	if _, acctsNode := mappingValue(node, "Accounts"); acctsNode != nil {
+		if acctsNode.Kind != yaml.SequenceNode {
+			v.addf(file, acctsNode.Line, "Accounts should be a list")
+		} else {
+			for _, acctNode := range acctsNode.Content {
+				v.validateAccount(file, acctNode)
+			}
+		}
+	}
+
+	groupsKey, groupsNode := mappingValue(node, "AccountGroups")
+	_, childrenNode := mappingValue(node, "OrganizationUnits")
+	if groupsNode != nil && childrenNode != nil {
+		v.addf(file, groupsKey.Line, "cannot set both AccountGroups and OrganizationUnits fields on Organization Unit: %s", name)
+	}
+
+	childNames := make(map[string]location)
+	for _, children := range []*yaml.Node{childrenNode, groupsNode} {
+		if children == nil {
+			continue
+		}
+		if children.Kind != yaml.SequenceNode {
+			v.addf(file, children.Line, "OrganizationUnits should be a list")
+			continue
+		}
+
+		for _, childNode := range children.Content {
+			childName := v.validateOU(file, childNode.Line, childNode)
+			if childName == "" {
+				if _, pathNode := mappingValue(childNode, "OUFilepath"); pathNode == nil {
+					v.addf(file, childNode.Line, "Organization Unit is missing a Name")
+				}
+				continue
+			}
+
+			if first, ok := childNames[childName]; ok {
+				v.addf(file, childNode.Line, "duplicate Organization Unit name %q under %q, first defined at %s", childName, name, first)
+				continue
+			}
+			childNames[childName] = location{file: file, line: childNode.Line}
+		}
+	}
+
+	return name
+}
+
+func (v *validator) validateOUFile(file string, pathNode *yaml.Node) string {
+	childFile := pathNode.Value
+	for _, f := range v.files {
+		if f == childFile {
+			v.addf(file, pathNode.Line, "OUFilepath %s includes itself", childFile)
+			return ""
+		}
+	}
+
+	if _, err := os.Stat(childFile); err != nil {
+		v.addf(file, pathNode.Line, "OUFilepath %s does not exist", childFile)
+		return ""
+	}
+
+	v.files = append(v.files, childFile)
+	defer func() {
+		v.files = v.files[:len(v.files)-1]
+	}()
+
+	root, ok := v.parseFile(childFile, &resource.OrganizationUnit{})
+	if !ok {
+		return ""
+	}
+
+	name := v.validateOU(childFile, root.Line, root)
+	if name == "" {
+		if _, nested := mappingValue(root, "OUFilepath"); nested == nil {
+			v.addf(childFile, root.Line, "Organization Unit is missing a Name")
+		}
+	}
+	return name
+}
+
+func (v *validator) validateAccount(file string, node *yaml.Node) {
+	if node.Kind != yaml.MappingNode {
+		v.addf(file, node.Line, "expected a mapping for Account")
+		return
+	}
+
+	_, emailNode := mappingValue(node, "Email")
+	if emailNode == nil || emailNode.Value == "" {
+		v.addf(file, node.Line, "Account is missing an Email")
+	} else {
+		if first, ok := v.emails[emailNode.Value]; ok {
+			v.addf(file, emailNode.Line, "duplicate account email %s, first used at %s", emailNode.Value, first)
+		} else {
+			v.emails[emailNode.Value] = location{file: file, line: emailNode.Line}
+		}
+	}
+
+	if _, nameNode := mappingValue(node, "AccountName"); nameNode == nil || nameNode.Value == "" {
+		v.addf(file, node.Line, "Account is missing an AccountName")
+	}
+
+	if _, roleNode := mappingValue(node, "AssumeRoleName"); roleNode != nil && !roleNameRegex.MatchString(roleNode.Value) {
+		v.addf(file, roleNode.Line, "AssumeRoleName %s should be an IAM role name of up to 64 letters, numbers and +=,.@_- characters", roleNode.Value)
+	}
+
+	if _, tagsNode := mappingValue(node, "Tags"); tagsNode != nil {
+		v.validateTags(file, tagsNode)
+	}
+
+	if _, stacksNode := mappingValue(node, "Stacks"); stacksNode != nil {
+		v.validateStacks(file, stacksNode, false)
+	}
+
+	if _, scpNode := mappingValue(node, "ServiceControlPolicies"); scpNode != nil {
+		v.validateStacks(file, scpNode, true)
+	}
+}
+
+func (v *validator) validateStacks(file string, node *yaml.Node, scp bool) {
+	if node.Kind != yaml.SequenceNode {
+		v.addf(file, node.Line, "stacks should be a list")
+		return
+	}
+
+	for _, stackNode := range node.Content {
+		var stack resource.Stack
+		if err := stackNode.Decode(&stack); err != nil {
+			// Decoding errors are reported by the strict decode of the file.
+			continue
+		}
+
+		if err := stack.Validate(); err != nil {
+			v.addf(file, stackNode.Line, "%s", oops.Cause(err))
+		}
+
+		if scp && stack.Type != "Terraform" && stack.Type != "Policy" {
+			v.addf(file, stackNode.Line, "ServiceControlPolicies only support Terraform and Policy stacks not: %s", stack.Type)
+		}
+		if !scp && stack.Type == "Policy" {
+			v.addf(file, stackNode.Line, "Policy stacks are only supported in ServiceControlPolicies")
+		}
+
+		if dependsKey, dependsNode := mappingValue(stackNode, "DependsOn"); dependsNode != nil {
+			if scp {
+				v.addf(file, dependsKey.Line, "DependsOn is not supported for ServiceControlPolicies")
+			}
+			v.validateDependsOn(file, dependsNode, stack.DependsOn)
+		}
+
+		if rolloutKey, rolloutNode := mappingValue(stackNode, "Rollout"); rolloutNode != nil && scp {
+			v.addf(file, rolloutKey.Line, "Rollout is not supported for ServiceControlPolicies")
+		}
+
+		if stack.Type == "Policy" {
+			v.validatePolicy(file, stackNode.Line, stack)
+			continue
+		}
+
+		if stack.Path == "" {
+			v.addf(file, stackNode.Line, "stack is missing a Path")
+			continue
+		}
+
+		info, err := os.Stat(stack.Path)
+		if err != nil {
+			v.addf(file, stackNode.Line, "stack Path %s does not exist", stack.Path)
+			continue
+		}
+		if stack.Type == "Cloudformation" && info.IsDir() {
+			v.addf(file, stackNode.Line, "Cloudformation stack Path %s should be a template file not a directory", stack.Path)
+		}
+		if (stack.Type == "Terraform" || stack.Type == "CDK") && !info.IsDir() {
+			v.addf(file, stackNode.Line, "%s stack Path %s should be a directory", stack.Type, stack.Path)
+		}
+	}
+}
+
+// validatePolicy checks that the document of a Policy stack is valid JSON and
+// is the same as the document of other Policy stacks with the same Name.
+// Stack.Validate has already checked its fields.
+func (v *validator) validatePolicy(file string, line int, stack resource.Stack) {
+	if stack.Name == "" || (stack.Path == "") == (stack.Document == "") {
+		return
+	}
+	if stack.Path != "" {
+		if info, err := os.Stat(stack.Path); err != nil {
+			v.addf(file, line, "stack Path %s does not exist", stack.Path)
+			return
+		} else if info.IsDir() {
+			v.addf(file, line, "Policy stack Path %s should be a JSON file not a directory", stack.Path)
+			return
+		}
+	}
+
+	document, err := stack.PolicyDocument()
+	if err != nil {
+		v.addf(file, line, "%s", oops.Cause(err))
+		return
+	}
+
+	if first, ok := v.policies[stack.Name]; ok && first.document != document {
+		v.addf(file, line, "Service Control Policy %s has a different document than at %s", stack.Name, first.location)
+	} else if !ok {
+		v.policies[stack.Name] = policyLocation{location: location{file: file, line: line}, document: document}
+	}
+}
+
+func (v *validator) validateDependsOn(file string, node *yaml.Node, deps []resource.StackDependency) {
+	if node.Kind != yaml.SequenceNode || len(node.Content) != len(deps) {
+		v.addf(file, node.Line, "DependsOn should be a list")
+		return
+	}
+
+	for i, dep := range deps {
+		line := node.Content[i].Line
+		if dep.Stack == "" {
+			v.addf(file, line, "DependsOn is missing a Stack")
+		}
+		if dep.Account != "" && dep.Tag != "" {
+			v.addf(file, line, "DependsOn cannot set both Account and Tag")
+		}
+	}
+}
+
+// validateDependencies checks that every DependsOn and output reference
+// matches a stack and that stacks don't depend on each other.
+func (v *validator) validateDependencies(filepath string) {
+	data, err := os.ReadFile(filepath)
+	if err != nil {
+		v.addf(filepath, 0, "reading file: %s", err)
+		return
+	}
+	var org orgDatav2
+	if err := yaml.Unmarshal(data, &org); err != nil {
+		v.addYAMLError(filepath, err)
+		return
+	}
+	if err := (Parser{}).hydrateOUFilepaths(context.Background(), &org.Organization); err != nil {
+		v.addf(filepath, 0, "%s", oops.Cause(err))
+		return
+	}
+	hydrateOUParent(&org.Organization)
+	hydrateAccountParent(&org.Organization)
+
+	// Regions are not expanded because Region: all needs AWS to list the
+	// account's regions and a dependency matches a stack in every region.
+	var stacks []resource.AccountStack
+	for _, acct := range org.Organization.AllDescendentAccounts() {
+		var acctStacks []resource.Stack
+		if acct.Parent != nil && !acct.NoStackInheritance {
+			acctStacks = append(acctStacks, acct.Parent.AllBaselineStacks()...)
+		}
+		acctStacks = append(acctStacks, acct.BaselineStacks...)
+		for _, stack := range acctStacks {
+			stacks = append(stacks, resource.AccountStack{Account: acct, Stack: stack})
+		}
+	}
+
+	// Stacks of an Organization Unit are reported once, not for every account.
+	unmatched := make(map[string]struct{})
+	for i, stack := range stacks {
+		for _, dep := range stack.Stack.Dependencies() {
+			matched := false
+			for j, candidate := range stacks {
+				if j != i && dep.Matches(stack.Account, candidate) {
+					matched = true
+					break
+				}
+			}
+			name := stack.Stack.Name
+			if name == "" {
+				name = stack.Stack.Path
+			}
+			msg := fmt.Sprintf("stack %s depends on %s which does not match any stack", name, dep)
+			if _, ok := unmatched[msg]; !matched && !ok {
+				unmatched[msg] = struct{}{}
+				v.addf(filepath, 0, "%s", msg)
+			}
+		}
+	}
+
+	// Outputs are stored by account and stack name, so a reference can't pick
+	// the region of a stack deployed to more than one region.
+	multiRegion := make(map[string]struct{})
+	for _, stack := range stacks {
+		for _, ref := range stack.Stack.OutputReferences() {
+			dep := resource.StackDependency{Stack: ref.Stack, Account: ref.Account}
+			for _, candidate := range stacks {
+				region := candidate.Stack.Region
+				if !dep.Matches(stack.Account, candidate) || (region != "all" && !strings.Contains(region, ",")) {
+					continue
+				}
+				msg := fmt.Sprintf("%s references stack %s in account %s which is deployed to more than one region", ref, ref.Stack, ref.Account)
+				if _, ok := multiRegion[msg]; !ok {
+					multiRegion[msg] = struct{}{}
+					v.addf(filepath, 0, "%s", msg)
+				}
+			}
+		}
+	}
+
+	if _, err := resource.StackDependencies(stacks); err != nil {
+		v.addf(filepath, 0, "%s", err)
+	}
+}
+
+// validateTags checks that tags can be translated into AWS tags. Tags are
+// either `key` or `key=value`.
+func (v *validator) validateTags(file string, node *yaml.Node) {
+	if node.Kind != yaml.SequenceNode {
+		v.addf(file, node.Line, "Tags should be a list")
+		return
+	}
+
+	keys := make(map[string]struct{})
+	for _, tagNode := range node.Content {
+		if tagNode.Kind != yaml.ScalarNode {
+			v.addf(file, tagNode.Line, "tag should be a string of the form key=value")
+			continue
+		}
+
+		tag := tagNode.Value
+		parts := strings.Split(tag, "=")
+		key := parts[0]
+		switch {
+		case strings.TrimSpace(tag) == "":
+			v.addf(file, tagNode.Line, "tag is empty")
+			continue
+		case len(parts) > 2:
+			v.addf(file, tagNode.Line, "tag %q should contain at most one =", tag)
+			continue
+		case strings.TrimSpace(key) == "":
+			v.addf(file, tagNode.Line, "tag %q is missing a key", tag)
+			continue
+		case strings.HasPrefix(strings.ToLower(key), "aws:"):
+			v.addf(file, tagNode.Line, "tag %q uses the reserved aws: prefix", tag)
+		case len(key) > 128:
+			v.addf(file, tagNode.Line, "tag key %q is longer than 128 characters", key)
+		case len(parts) == 2 && len(parts[1]) > 256:
+			v.addf(file, tagNode.Line, "tag value for key %q is longer than 256 characters", key)
+		}
+
+		if _, ok := keys[key]; ok {
+			v.addf(file, tagNode.Line, "duplicate tag key: %s", key)
+		}
+		keys[key] = struct{}{}
+	}
+}
+
+// mappingValue returns the key and value nodes for key in a mapping node.
+func mappingValue(node *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i], node.Content[i+1]
+		}
+	}
+
+	return nil, nil
+}
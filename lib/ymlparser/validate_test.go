@@ -0,0 +1,209 @@
+package ymlparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateOrganization(t *testing.T) {
+	tests := []struct {
+		name    string
+		orgPath string
+		want    []ValidationError
+	}{
+		{
+			name:    "valid organization with child filepath",
+			orgPath: "./testdata/validate/organization-valid.yml",
+		},
+		{
+			name:    "invalid organization",
+			orgPath: "./testdata/validate/organization-invalid.yml",
+			want: []ValidationError{
+				{
+					File:    "./testdata/validate/organization-child-invalid.yml",
+					Line:    3,
+					Message: `tag "=dev" is missing a key`,
+				},
+				{
+					File:    "./testdata/validate/organization-child-invalid.yml",
+					Line:    5,
+					Message: "Account is missing an AccountName",
+				},
+				{
+					File:    "./testdata/validate/organization-invalid.yml",
+					Line:    6,
+					Message: `tag "env=prod=us" should contain at most one =`,
+				},
+				{
+					File:    "./testdata/validate/organization-invalid.yml",
+					Line:    8,
+					Message: "stack Path ./testdata/validate/tf/missing does not exist",
+				},
+				{
+					File:    "./testdata/validate/organization-invalid.yml",
+					Line:    13,
+					Message: "unknown key Tagz in Account",
+				},
+				{
+					File:    "./testdata/validate/organization-invalid.yml",
+					Line:    15,
+					Message: `duplicate Organization Unit name "Production" under "root", first defined at ./testdata/validate/organization-invalid.yml:4`,
+				},
+				{
+					File:    "./testdata/validate/organization-invalid.yml",
+					Line:    17,
+					Message: "duplicate account email prod1@example.com, first used at ./testdata/validate/organization-invalid.yml:11",
+				},
+				{
+					File:    "./testdata/validate/organization-invalid.yml",
+					Line:    20,
+					Message: "Workspace: (dev) should not be set for CDK stack",
+				},
+			},
+		},
+		{
+			name:    "valid stack dependencies",
+			orgPath: "./testdata/validate/organization-depends-on.yml",
+		},
+		{
+			name:    "invalid stack dependencies",
+			orgPath: "./testdata/validate/organization-depends-on-invalid.yml",
+			want: []ValidationError{
+				{
+					File:    "./testdata/validate/organization-depends-on-invalid.yml",
+					Line:    11,
+					Message: "DependsOn is missing a Stack",
+				},
+				{
+					File:    "./testdata/validate/organization-depends-on-invalid.yml",
+					Line:    12,
+					Message: "DependsOn cannot set both Account and Tag",
+				},
+				{
+					File:    "./testdata/validate/organization-depends-on-invalid.yml",
+					Line:    18,
+					Message: "DependsOn is not supported for ServiceControlPolicies",
+				},
+			},
+		},
+		{
+			name:    "stack dependency cycle",
+			orgPath: "./testdata/validate/organization-depends-on-cycle.yml",
+			want: []ValidationError{
+				{
+					File:    "./testdata/validate/organization-depends-on-cycle.yml",
+					Message: "stack cloudtrail depends on missing which does not match any stack",
+				},
+				{
+					File:    "./testdata/validate/organization-depends-on-cycle.yml",
+					Message: "stack dependency cycle: log-bucket in account log-archive -> cloudtrail in account prod1 -> log-bucket in account log-archive. Stacks also depend on the stack listed before them in the same account",
+				},
+			},
+		},
+		{
+			name:    "output of a multi-region stack",
+			orgPath: "./testdata/validate/organization-outputs-multi-region.yml",
+			want: []ValidationError{
+				{
+					File:    "./testdata/validate/organization-outputs-multi-region.yml",
+					Message: "${telophase.outputs.prod1.vpc.VpcId} references stack vpc in account prod1 which is deployed to more than one region",
+				},
+			},
+		},
+		{
+			name:    "invalid rollout",
+			orgPath: "./testdata/validate/organization-rollout-invalid.yml",
+			want: []ValidationError{
+				{
+					File:    "./testdata/validate/organization-rollout-invalid.yml",
+					Line:    5,
+					Message: "Rollout needs at least one wave in Waves",
+				},
+				{
+					File:    "./testdata/validate/organization-rollout-invalid.yml",
+					Line:    11,
+					Message: "Rollout wave 10% is smaller than an earlier wave, percentages include the earlier waves",
+				},
+				{
+					File:    "./testdata/validate/organization-rollout-invalid.yml",
+					Line:    18,
+					Message: "Rollout is not supported for ServiceControlPolicies",
+				},
+			},
+		},
+		{
+			name:    "invalid renames",
+			orgPath: "./testdata/validate/organization-rename-invalid.yml",
+			want: []ValidationError{
+				{
+					File:    "./testdata/validate/organization-rename-invalid.yml",
+					Line:    6,
+					Message: "PreviousNames of Organization Unit Production should not include its Name",
+				},
+				{
+					File:    "./testdata/validate/organization-rename-invalid.yml",
+					Line:    8,
+					Message: "duplicate OUID ou-ab12-prod0001, first used at ./testdata/validate/organization-rename-invalid.yml:5",
+				},
+				{
+					File:    "./testdata/validate/organization-rename-invalid.yml",
+					Line:    10,
+					Message: "OUID dev should be an Organization Unit ID like ou-ab12-cdef3456",
+				},
+			},
+		},
+		{
+			name:    "invalid account",
+			orgPath: "./testdata/validate/organization-account-invalid.yml",
+			want: []ValidationError{
+				{
+					File:    "./testdata/validate/organization-account-invalid.yml",
+					Line:    6,
+					Message: "AssumeRoleName Admin Role should be an IAM role name of up to 64 letters, numbers and +=,.@_- characters",
+				},
+			},
+		},
+		{
+			name:    "invalid policies",
+			orgPath: "./testdata/validate/organization-policy-invalid.yml",
+			want: []ValidationError{
+				{
+					File:    "./testdata/validate/organization-policy-invalid.yml",
+					Line:    9,
+					Message: "policy broken is not valid JSON: unexpected end of JSON input",
+				},
+				{
+					File:    "./testdata/validate/organization-policy-invalid.yml",
+					Line:    12,
+					Message: "Policy stack empty should set one of Path or Document",
+				},
+				{
+					File:    "./testdata/validate/organization-policy-invalid.yml",
+					Line:    16,
+					Message: "Service Control Policy deny-leave has a different document than at ./testdata/validate/organization-policy-invalid.yml:6",
+				},
+				{
+					File:    "./testdata/validate/organization-policy-invalid.yml",
+					Line:    20,
+					Message: "Policy stacks are only supported in ServiceControlPolicies",
+				},
+			},
+		},
+		{
+			name:    "missing file",
+			orgPath: "./testdata/validate/does-not-exist.yml",
+			want: []ValidationError{
+				{
+					File:    "./testdata/validate/does-not-exist.yml",
+					Message: "reading file: open ./testdata/validate/does-not-exist.yml: no such file or directory",
+				},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		errs := ValidateOrganization(tc.orgPath)
+		assert.Equal(t, tc.want, errs, tc.name)
+	}
+}
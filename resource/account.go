@@ -29,15 +29,28 @@ type Account struct {
 	Parent                         *OrganizationUnit `yaml:"-"`
 
 	Status string `yaml:"-,omitempty"`
+
+	// IamUserAccessToBilling lets IAM users and roles in the account see its
+	// billing. It is only used when the account is created, and AWS allows it
+	// if it is unset.
+	IamUserAccessToBilling *bool `yaml:"IamUserAccessToBilling,omitempty"`
 }
 
-func (a Account) AssumeRoleARN() string {
-	assumeRoleName := "OrganizationAccountAccessRole"
+// DefaultAssumeRoleName is the role AWS creates in new accounts when no role
+// name is given.
+const DefaultAssumeRoleName = "OrganizationAccountAccessRole"
+
+// RoleName returns the name of the role telophase assumes in the account. New
+// accounts are created with this role.
+func (a Account) RoleName() string {
 	if a.AssumeRoleName != "" {
-		assumeRoleName = a.AssumeRoleName
+		return a.AssumeRoleName
 	}
+	return DefaultAssumeRoleName
+}
 
-	return fmt.Sprintf("arn:aws:iam::%s:role/%s", a.AccountID, assumeRoleName)
+func (a Account) AssumeRoleARN() string {
+	return fmt.Sprintf("arn:aws:iam::%s:role/%s", a.AccountID, a.RoleName())
 }
 
 func (a Account) ID() string {
@@ -73,6 +86,26 @@ func (a Account) AllTags() []string {
 	return tags
 }
 
+// IgnorableTag returns whether tag is set by Telophase on every resource
+// rather than in organization.yml.
+func IgnorableTag(tag string) bool {
+	ignorableTags := map[string]struct{}{
+		"TelophaseManaged=true": {},
+	}
+	ignorableKeys := map[string]struct{}{
+		"AccountName": {},
+	}
+
+	_, ok := ignorableTags[tag]
+	if ok {
+		return true
+	}
+	if _, ok := ignorableKeys[strings.Split(tag, "=")[0]]; ok {
+		return true
+	}
+	return false
+}
+
 func (a Account) AllAWSTags() []string {
 	var tags []string
 	tags = append(tags, a.AWSTags...)
@@ -82,14 +115,15 @@ func (a Account) AllAWSTags() []string {
 	return tags
 }
 
-func (a Account) CurrentTags() []string {
-	// Default tags for every account
+// CurrentTags returns the tags of the account and the tags it inherits from its
+// parents.
+func (a Account) CurrentTags() ([]string, error) {
 	var tags []string
 	currTags := make(map[string]struct{})
 	for _, tag := range a.Tags {
 		key := strings.Split(tag, "=")[0]
 		if _, exists := currTags[key]; exists {
-			panic(fmt.Sprintf("duplicate tag key: %s on account with email: %s", key, a.Email))
+			return nil, fmt.Errorf("duplicate tag key: %s on account with email: %s", key, a.Email)
 		}
 	}
 
@@ -98,14 +132,14 @@ func (a Account) CurrentTags() []string {
 		for _, tag := range a.Parent.AllTags() {
 			key := strings.Split(tag, "=")[0]
 			if _, exists := currTags[key]; exists {
-				panic(fmt.Sprintf("duplicate tag key: %s on account with email : %s inherited from parent tree", key, a.Email))
+				return nil, fmt.Errorf("duplicate tag key: %s on account with email: %s inherited from parent tree", key, a.Email)
 			}
 
 			tags = append(tags, tag)
 		}
 	}
 
-	return tags
+	return tags, nil
 }
 
 func (a Account) AllBaselineStacks() ([]Stack, error) {
@@ -166,6 +200,10 @@ func (a Account) GenerateStacks(stack Stack) ([]Stack, error) {
 	if stack.Region != "all" {
 		return []Stack{stack}, nil
 	}
+	// The regions of an account that hasn't been created yet aren't known.
+	if !a.IsProvisioned() {
+		return []Stack{stack}, nil
+	}
 
 	sess, err := awssess.DefaultSession()
 	if err != nil {
@@ -197,6 +197,21 @@ func TestAllBaselineStacks(t *testing.T) {
 	}
 }
 
+func TestAllBaselineStacksNotProvisioned(t *testing.T) {
+	// The regions of an account that doesn't exist yet can't be listed.
+	acct := resource.Account{
+		AccountName: "new",
+		Email:       "new@example.com",
+		BaselineStacks: []resource.Stack{
+			{Type: "Terraform", Path: "tf/all", Region: "all"},
+		},
+	}
+
+	baselineStacks, err := acct.AllBaselineStacks()
+	assert.NoError(t, err)
+	assert.Equal(t, acct.BaselineStacks, baselineStacks)
+}
+
 func TestFilterBaselineStacks(t *testing.T) {
 	hydrateOUParent(rootOU)
 	hydrateAccountParent(rootOU)
@@ -323,3 +338,18 @@ func findAcctByEmail(ou *resource.OrganizationUnit, email string) *resource.Acco
 
 	return nil
 }
+
+func TestCurrentTags(t *testing.T) {
+	ou := &resource.OrganizationUnit{Tags: []string{"team=platform"}}
+
+	acct := resource.Account{Email: "a@example.com", Tags: []string{"env=dev"}, Parent: ou}
+	tags, err := acct.CurrentTags()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"env=dev", "team=platform"}, tags)
+
+	// Accounts can set the same keys as their parents.
+	acct = resource.Account{Email: "a@example.com", Tags: []string{"team=data"}, Parent: ou}
+	tags, err = acct.CurrentTags()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"team=data", "team=platform"}, tags)
+}
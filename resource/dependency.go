@@ -0,0 +1,217 @@
+package resource
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/santiago-labs/telophasecli/lib/outputs"
+)
+
+// StackDependency is a stack that is deployed before the stack that depends on
+// it. Without Account or Tag it is a stack in the same account.
+type StackDependency struct {
+	// Stack is the Name of the stack.
+	Stack string `yaml:"Stack"`
+	// Account is the AccountName of the account the stack is deployed to.
+	Account string `yaml:"Account,omitempty"`
+	// Tag selects the stack in every account with the tag.
+	Tag string `yaml:"Tag,omitempty"`
+}
+
+func (d StackDependency) String() string {
+	switch {
+	case d.Account != "":
+		return fmt.Sprintf("%s in account %s", d.Stack, d.Account)
+	case d.Tag != "":
+		return fmt.Sprintf("%s in accounts tagged %s", d.Stack, d.Tag)
+	}
+	return d.Stack
+}
+
+// Matches returns whether candidate is selected by the dependency of a stack
+// in acct.
+func (d StackDependency) Matches(acct *Account, candidate AccountStack) bool {
+	if !candidate.Stack.HasName(d.Stack) {
+		return false
+	}
+
+	switch {
+	case d.Account != "":
+		return candidate.Account.AccountName == d.Account
+	case d.Tag != "":
+		return candidate.Account.hasTag(d.Tag)
+	}
+	return candidate.Account.Email == acct.Email
+}
+
+// OutputReferences returns the outputs of other stacks the stack references in
+// its CloudformationParameters, CDKContext or Terraform files.
+func (s Stack) OutputReferences() []outputs.Reference {
+	var refs []outputs.Reference
+	for _, value := range append(append([]string{}, s.CloudformationParameters...), s.CDKContext...) {
+		refs = append(refs, outputs.References(value)...)
+	}
+
+	if s.Type == "Terraform" {
+		// Errors are ignored because missing paths are reported by validate
+		// and when the stack runs.
+		filepath.WalkDir(s.Path, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			if d.IsDir() {
+				if d.Name() == "telophasedirs" || d.Name() == ".terraform" {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return nil
+			}
+			refs = append(refs, outputs.References(string(content))...)
+			return nil
+		})
+	}
+
+	return refs
+}
+
+// Dependencies returns the DependsOn of the stack and the stacks whose outputs
+// it references.
+func (s Stack) Dependencies() []StackDependency {
+	deps := append([]StackDependency{}, s.DependsOn...)
+	for _, ref := range s.OutputReferences() {
+		dep := StackDependency{Stack: ref.Stack, Account: ref.Account}
+		if !containsDependency(deps, dep) {
+			deps = append(deps, dep)
+		}
+	}
+	return deps
+}
+
+func containsDependency(deps []StackDependency, dep StackDependency) bool {
+	for _, d := range deps {
+		if d == dep {
+			return true
+		}
+	}
+	return false
+}
+
+// AccountStack is a stack deployed to an account.
+type AccountStack struct {
+	Account *Account
+	Stack   Stack
+}
+
+func (s AccountStack) String() string {
+	name := s.Stack.Name
+	if name == "" {
+		name = s.Stack.Path
+	}
+	if s.Stack.Region != "" {
+		name = fmt.Sprintf("%s (%s)", name, s.Stack.Region)
+	}
+	return fmt.Sprintf("%s in account %s", name, s.Account.AccountName)
+}
+
+// StackDependencies returns the indexes of the stacks each stack waits for.
+// A stack waits for the stack listed before it in the same account and for the
+// stacks matching its Dependencies. Stacks of an account must be next to each
+// other in stacks. It returns an error if the dependencies, together with the
+// RolloutSteps, have a cycle.
+func StackDependencies(stacks []AccountStack) ([][]int, error) {
+	deps := make([][]int, len(stacks))
+	for i, stack := range stacks {
+		if i > 0 && stacks[i-1].Account.Email == stack.Account.Email {
+			deps[i] = append(deps[i], i-1)
+		}
+
+		for _, dep := range stack.Stack.Dependencies() {
+			for j, candidate := range stacks {
+				if j != i && !containsIndex(deps[i], j) && dep.Matches(stack.Account, candidate) {
+					deps[i] = append(deps[i], j)
+				}
+			}
+		}
+	}
+
+	// Rollout waves also order stacks so they are part of the check.
+	waits := make([][]int, len(deps))
+	hasRollout := false
+	for i, step := range RolloutSteps(stacks) {
+		waits[i] = append(append([]int{}, deps[i]...), step.Waits...)
+		hasRollout = hasRollout || step.Wave > 0
+	}
+
+	if cycle := findCycle(waits); cycle != nil {
+		var names []string
+		for _, i := range cycle {
+			names = append(names, stacks[i].String())
+		}
+		msg := fmt.Sprintf("stack dependency cycle: %s. Stacks also depend on the stack listed before them in the same account", strings.Join(names, " -> "))
+		if hasRollout {
+			msg += " and on the earlier waves of their Rollout"
+		}
+		return nil, fmt.Errorf("%s", msg)
+	}
+
+	return deps, nil
+}
+
+func containsIndex(indexes []int, i int) bool {
+	for _, index := range indexes {
+		if index == i {
+			return true
+		}
+	}
+	return false
+}
+
+// findCycle returns the indexes of a cycle in deps, starting and ending with
+// the same index, or nil if there is no cycle.
+func findCycle(deps [][]int) []int {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(deps))
+	var path []int
+
+	var visit func(i int) []int
+	visit = func(i int) []int {
+		state[i] = visiting
+		path = append(path, i)
+		for _, dep := range deps[i] {
+			switch state[dep] {
+			case visiting:
+				for start, j := range path {
+					if j == dep {
+						return append(append([]int{}, path[start:]...), dep)
+					}
+				}
+			case unvisited:
+				if cycle := visit(dep); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[i] = visited
+		return nil
+	}
+
+	for i := range deps {
+		if state[i] == unvisited {
+			if cycle := visit(i); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package resource_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/santiago-labs/telophasecli/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStackDependencies(t *testing.T) {
+	logArchive := &resource.Account{Email: "logs@example.com", AccountName: "log-archive"}
+	prod1 := &resource.Account{Email: "prod1@example.com", AccountName: "prod1", Tags: []string{"env=production"}}
+	prod2 := &resource.Account{Email: "prod2@example.com", AccountName: "prod2", Tags: []string{"env=production"}}
+
+	logBucket := resource.Stack{Name: "log-bucket", Type: "Terraform", Path: "tf/bucket"}
+	cloudtrail := resource.Stack{
+		Name: "cloudtrail",
+		Type: "Terraform",
+		Path: "tf/cloudtrail",
+		DependsOn: []resource.StackDependency{
+			{Stack: "log-bucket", Account: "log-archive"},
+		},
+	}
+	alarms := resource.Stack{
+		Name: "alarms,monitoring",
+		Type: "Terraform",
+		Path: "tf/alarms",
+	}
+
+	tests := []struct {
+		name    string
+		stacks  []resource.AccountStack
+		want    [][]int
+		wantErr string
+	}{
+		{
+			name: "stacks in an account wait for the previous stack",
+			stacks: []resource.AccountStack{
+				{Account: prod1, Stack: cloudtrail},
+				{Account: prod1, Stack: alarms},
+				{Account: prod2, Stack: alarms},
+			},
+			want: [][]int{nil, {0}, nil},
+		},
+		{
+			name: "dependency on an account",
+			stacks: []resource.AccountStack{
+				{Account: logArchive, Stack: logBucket},
+				{Account: prod1, Stack: cloudtrail},
+				{Account: prod2, Stack: cloudtrail},
+			},
+			want: [][]int{nil, {0}, {0}},
+		},
+		{
+			name: "dependency on a tag and a comma separated name",
+			stacks: []resource.AccountStack{
+				{Account: prod1, Stack: alarms},
+				{Account: prod2, Stack: alarms},
+				{Account: logArchive, Stack: resource.Stack{
+					Name:      "dashboard",
+					DependsOn: []resource.StackDependency{{Stack: "monitoring", Tag: "env=production"}},
+				}},
+			},
+			want: [][]int{nil, nil, {0, 1}},
+		},
+		{
+			name: "cycle",
+			stacks: []resource.AccountStack{
+				{Account: logArchive, Stack: resource.Stack{
+					Name:      "log-bucket",
+					DependsOn: []resource.StackDependency{{Stack: "cloudtrail", Account: "prod1"}},
+				}},
+				{Account: prod1, Stack: cloudtrail},
+			},
+			wantErr: "stack dependency cycle: log-bucket in account log-archive -> cloudtrail in account prod1 -> log-bucket in account log-archive. Stacks also depend on the stack listed before them in the same account",
+		},
+		{
+			name: "cycle with list order",
+			stacks: []resource.AccountStack{
+				{Account: prod1, Stack: resource.Stack{
+					Name:      "first",
+					Region:    "us-west-2",
+					DependsOn: []resource.StackDependency{{Stack: "second"}},
+				}},
+				{Account: prod1, Stack: resource.Stack{Name: "second"}},
+			},
+			wantErr: "stack dependency cycle: first (us-west-2) in account prod1 -> second in account prod1 -> first (us-west-2) in account prod1. Stacks also depend on the stack listed before them in the same account",
+		},
+	}
+
+	for _, tc := range tests {
+		deps, err := resource.StackDependencies(tc.stacks)
+		if tc.wantErr != "" {
+			assert.EqualError(t, err, tc.wantErr, tc.name)
+			continue
+		}
+		require.NoError(t, err, tc.name)
+		assert.Equal(t, tc.want, deps, tc.name)
+	}
+}
+
+func TestStackDependenciesOutputReferences(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.tf"), []byte(`
+resource "aws_cloudtrail" "trail" {
+  s3_bucket_name = "${telophase.outputs.log-archive.log-bucket.BucketName}"
+}
+`), 0644))
+
+	logArchive := &resource.Account{Email: "logs@example.com", AccountName: "log-archive"}
+	prod := &resource.Account{Email: "prod@example.com", AccountName: "prod"}
+
+	deps, err := resource.StackDependencies([]resource.AccountStack{
+		{Account: prod, Stack: resource.Stack{Name: "cloudtrail", Type: "Terraform", Path: dir}},
+		{Account: prod, Stack: resource.Stack{
+			Name:                     "alarms",
+			Type:                     "Cloudformation",
+			Path:                     "cf/alarms.yml",
+			CloudformationParameters: []string{"Bucket=${telophase.outputs.log-archive.log-bucket.BucketName}"},
+		}},
+		{Account: logArchive, Stack: resource.Stack{Name: "log-bucket", Type: "Cloudformation", Path: "cf/bucket.yml"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, [][]int{{2}, {0, 2}}, deps[:2])
+}
@@ -10,7 +10,15 @@ type OrganizationUnit struct {
 	Accounts               []*Account          `yaml:"Accounts,omitempty"`
 	BaselineStacks         []Stack             `yaml:"Stacks,omitempty"`
 	ServiceControlPolicies []Stack             `yaml:"ServiceControlPolicies,omitempty"`
-	Parent                 *OrganizationUnit   `yaml:"-"`
+	// PinnedOUID is the ID of the OU in AWS. The OU is matched by ID instead
+	// of Name so that changing its Name renames it.
+	PinnedOUID string `yaml:"OUID,omitempty"`
+	// PreviousNames are names the OU had in AWS. An OU that is not found by
+	// Name is matched by them so that renaming it doesn't create a new OU.
+	PreviousNames []string `yaml:"PreviousNames,omitempty"`
+	// Rollout is the Rollout of the OU's Stacks that don't have their own.
+	Rollout *Rollout          `yaml:"Rollout,omitempty"`
+	Parent  *OrganizationUnit `yaml:"-"`
 
 	OUFilepath *string `yaml:"OUFilepath,omitempty"`
 }
@@ -53,7 +61,12 @@ func (grp OrganizationUnit) AllBaselineStacks() []Stack {
 	if grp.Parent != nil {
 		stacks = append(stacks, grp.Parent.AllBaselineStacks()...)
 	}
-	stacks = append(stacks, grp.BaselineStacks...)
+	for _, stack := range grp.BaselineStacks {
+		if stack.Rollout == nil {
+			stack.Rollout = grp.Rollout
+		}
+		stacks = append(stacks, stack)
+	}
 	return stacks
 }
 
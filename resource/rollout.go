@@ -0,0 +1,165 @@
+package resource
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Rollout deploys a stack to its accounts in waves instead of to every account
+// at once. A wave is a tag, selecting the accounts with the tag, or a
+// percentage, selecting that share of the accounts in the order they are
+// listed. Each wave only includes accounts that are not in an earlier wave and
+// accounts that are in no wave are deployed in a last wave.
+type Rollout struct {
+	Waves []string `yaml:"Waves"`
+	// HaltOnFailure skips the later waves if a stack in a wave fails.
+	HaltOnFailure bool `yaml:"HaltOnFailure,omitempty"`
+}
+
+func (r Rollout) Validate() error {
+	if len(r.Waves) == 0 {
+		return fmt.Errorf("Rollout needs at least one wave in Waves")
+	}
+
+	previous := 0.0
+	for _, wave := range r.Waves {
+		if strings.TrimSpace(wave) == "" {
+			return fmt.Errorf("Rollout wave cannot be empty")
+		}
+		percent, ok, err := wavePercent(wave)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		if percent < previous {
+			return fmt.Errorf("Rollout wave %s is smaller than an earlier wave, percentages include the earlier waves", wave)
+		}
+		previous = percent
+	}
+	return nil
+}
+
+// wavePercent returns the percentage of a wave like 10%. ok is false if the
+// wave is a tag.
+func wavePercent(wave string) (percent float64, ok bool, err error) {
+	if !strings.HasSuffix(wave, "%") {
+		return 0, false, nil
+	}
+	percent, err = strconv.ParseFloat(strings.TrimSuffix(wave, "%"), 64)
+	if err != nil || percent <= 0 || percent > 100 {
+		return 0, false, fmt.Errorf("Rollout wave %s should be a percentage between 0%% and 100%%", wave)
+	}
+	return percent, true, nil
+}
+
+// RolloutStep is where a stack is in its rollout.
+type RolloutStep struct {
+	// Wave is the wave of the stack starting at 1, or 0 if the stack has no
+	// Rollout.
+	Wave int
+	// Waits are the indexes of the stacks in the previous wave of the
+	// rollout.
+	Waits         []int
+	HaltOnFailure bool
+}
+
+// RolloutSteps orders the stacks with a Rollout into waves. Stacks with the
+// same Rollout, such as the stacks of an Organization Unit with a Rollout,
+// are rolled out together.
+func RolloutSteps(stacks []AccountStack) []RolloutStep {
+	steps := make([]RolloutStep, len(stacks))
+
+	var rollouts []*Rollout
+	members := map[*Rollout][]int{}
+	for i, stack := range stacks {
+		rollout := stack.Stack.Rollout
+		if rollout == nil {
+			continue
+		}
+		if _, ok := members[rollout]; !ok {
+			rollouts = append(rollouts, rollout)
+		}
+		members[rollout] = append(members[rollout], i)
+	}
+
+	for _, rollout := range rollouts {
+		waves := accountWaves(rollout, stacks, members[rollout])
+
+		// Waves without accounts are left out so that a stack waits for the
+		// closest earlier wave.
+		byWave := map[int][]int{}
+		for _, i := range members[rollout] {
+			byWave[waves[stacks[i].Account.Email]] = append(byWave[waves[stacks[i].Account.Email]], i)
+		}
+
+		var previous []int
+		wave := 0
+		for w := 1; w <= len(rollout.Waves)+1; w++ {
+			current, ok := byWave[w]
+			if !ok {
+				continue
+			}
+			wave++
+			for _, i := range current {
+				steps[i] = RolloutStep{
+					Wave:          wave,
+					Waits:         previous,
+					HaltOnFailure: rollout.HaltOnFailure,
+				}
+			}
+			previous = current
+		}
+	}
+
+	return steps
+}
+
+// accountWaves returns the wave, starting at 1, of every account the stacks
+// of rollout are deployed to.
+func accountWaves(rollout *Rollout, stacks []AccountStack, members []int) map[string]int {
+	var accts []*Account
+	seen := map[string]bool{}
+	for _, i := range members {
+		acct := stacks[i].Account
+		if !seen[acct.Email] {
+			seen[acct.Email] = true
+			accts = append(accts, acct)
+		}
+	}
+
+	waves := map[string]int{}
+	for w, wave := range rollout.Waves {
+		// Rollouts are validated when organization.yml is parsed.
+		percent, isPercent, _ := wavePercent(wave)
+		count := int(math.Ceil(percent / 100 * float64(len(accts))))
+
+		for i, acct := range accts {
+			if _, ok := waves[acct.Email]; ok {
+				continue
+			}
+			if isPercent && i < count || !isPercent && acct.hasTag(wave) {
+				waves[acct.Email] = w + 1
+			}
+		}
+	}
+
+	for _, acct := range accts {
+		if _, ok := waves[acct.Email]; !ok {
+			waves[acct.Email] = len(rollout.Waves) + 1
+		}
+	}
+	return waves
+}
+
+func (a Account) hasTag(tag string) bool {
+	for _, t := range a.AllTags() {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+package resource_test
+
+import (
+	"testing"
+
+	"github.com/santiago-labs/telophasecli/resource"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRolloutSteps(t *testing.T) {
+	sandbox := &resource.Account{Email: "sandbox@example.com", AccountName: "sandbox", Tags: []string{"canary"}}
+	prod1 := &resource.Account{Email: "prod1@example.com", AccountName: "prod1"}
+	prod2 := &resource.Account{Email: "prod2@example.com", AccountName: "prod2"}
+	prod3 := &resource.Account{Email: "prod3@example.com", AccountName: "prod3"}
+
+	rollout := &resource.Rollout{Waves: []string{"canary", "50%", "100%"}, HaltOnFailure: true}
+	baseline := resource.Stack{Name: "baseline", Type: "Terraform", Path: "tf/baseline", Rollout: rollout}
+	alarms := resource.Stack{Name: "alarms", Type: "Terraform", Path: "tf/alarms"}
+
+	stacks := []resource.AccountStack{
+		{Account: prod1, Stack: baseline},
+		{Account: prod2, Stack: baseline},
+		{Account: prod2, Stack: alarms},
+		{Account: prod3, Stack: baseline},
+		{Account: sandbox, Stack: baseline},
+	}
+
+	// 50% of the 4 accounts are prod1 and prod2.
+	assert.Equal(t, []resource.RolloutStep{
+		{Wave: 2, Waits: []int{4}, HaltOnFailure: true},
+		{Wave: 2, Waits: []int{4}, HaltOnFailure: true},
+		{},
+		{Wave: 3, Waits: []int{0, 1}, HaltOnFailure: true},
+		{Wave: 1, HaltOnFailure: true},
+	}, resource.RolloutSteps(stacks))
+
+	// alarms runs before baseline in prod2, which is in an earlier wave than
+	// prod3.
+	alarms.DependsOn = []resource.StackDependency{{Stack: "baseline", Account: "prod3"}}
+	stacks = []resource.AccountStack{
+		{Account: prod2, Stack: alarms},
+		{Account: prod2, Stack: baseline},
+		{Account: prod3, Stack: baseline},
+	}
+	_, err := resource.StackDependencies(stacks)
+	assert.EqualError(t, err, "stack dependency cycle: alarms in account prod2 -> baseline in account prod3 -> baseline in account prod2 -> alarms in account prod2. Stacks also depend on the stack listed before them in the same account and on the earlier waves of their Rollout")
+}
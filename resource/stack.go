@@ -1,7 +1,10 @@
 package resource
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"os"
 	"regexp"
 	"strings"
 	"time"
@@ -23,6 +26,22 @@ type Stack struct {
 
 	CloudformationParameters   []string `yaml:"CloudformationParameters,omitempty"`
 	CloudformationCapabilities []string `yaml:"CloudformationCapabilities,omitempty"`
+
+	// CDKContext is passed to CDK apps as --context key=value.
+	CDKContext []string `yaml:"CDKContext,omitempty"`
+
+	DependsOn []StackDependency `yaml:"DependsOn,omitempty"`
+
+	// Rollout deploys the stack to its accounts in waves.
+	Rollout *Rollout `yaml:"Rollout,omitempty"`
+
+	// Timeout is how long the stack's operation can run before it is
+	// canceled, e.g. 30m. It overrides --timeout.
+	Timeout string `yaml:"Timeout,omitempty"`
+
+	// Document is the JSON of a Policy stack written in organization.yml
+	// instead of in the file at Path.
+	Document string `yaml:"Document,omitempty"`
 }
 
 func (s Stack) NewForRegion(region string) Stack {
@@ -37,9 +56,30 @@ func (s Stack) NewForRegion(region string) Stack {
 
 		CloudformationParameters:   s.CloudformationParameters,
 		CloudformationCapabilities: s.CloudformationCapabilities,
+
+		CDKContext: s.CDKContext,
+
+		DependsOn: s.DependsOn,
+
+		Rollout: s.Rollout,
+
+		Timeout: s.Timeout,
+
+		Document: s.Document,
 	}
 }
 
+// HasName returns whether name is one of the comma separated names of the
+// stack.
+func (s Stack) HasName(name string) bool {
+	for _, stackName := range strings.Split(s.Name, ",") {
+		if strings.TrimSpace(stackName) == name {
+			return true
+		}
+	}
+	return false
+}
+
 func (s Stack) RoleARN(acct Account) *string {
 	if s.AssumeRoleName != "" {
 		result := fmt.Sprintf("arn:aws:iam::%s:role/%s", acct.AccountID, s.AssumeRoleName)
@@ -62,15 +102,51 @@ func (s Stack) WorkspaceEnabled() bool {
 	return s.Workspace != ""
 }
 
+// TimeoutDuration returns the parsed Timeout, or 0 if it is not set.
+func (s Stack) TimeoutDuration() (time.Duration, error) {
+	if s.Timeout == "" {
+		return 0, nil
+	}
+	timeout, err := time.ParseDuration(s.Timeout)
+	if err != nil {
+		return 0, oops.Errorf("Timeout (%s) should be a duration like 30m or 1h30m", s.Timeout)
+	}
+	if timeout <= 0 {
+		return 0, oops.Errorf("Timeout (%s) should be positive", s.Timeout)
+	}
+	return timeout, nil
+}
+
 func (s Stack) Validate() error {
+	if _, err := s.TimeoutDuration(); err != nil {
+		return err
+	}
+	if s.Rollout != nil {
+		if err := s.Rollout.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if s.Document != "" && s.Type != "Policy" {
+		return oops.Errorf("Document should only be set for Policy stack")
+	}
+
 	switch os := s.Type; os {
 	case "Terraform":
+		if len(s.CDKContext) > 0 {
+			return oops.Errorf("CDKContext should not be set for Terraform stack")
+		}
 		return nil
 
 	case "CDK":
 		if s.Workspace != "" {
 			return oops.Errorf("Workspace: (%s) should not be set for CDK stack", s.Workspace)
 		}
+		for _, context := range s.CDKContext {
+			if !strings.Contains(context, "=") {
+				return oops.Errorf("CDKContext (%s) should be = delimited", context)
+			}
+		}
 		return nil
 
 	case "Cloudformation":
@@ -84,14 +160,50 @@ func (s Stack) Validate() error {
 		if s.Workspace != "" {
 			return oops.Errorf("Workspace: (%s) should not be set for Cloudformation stack", s.Workspace)
 		}
+		if len(s.CDKContext) > 0 {
+			return oops.Errorf("CDKContext should not be set for Cloudformation stack")
+		}
+		return nil
+
+	case "Policy":
+		if s.Name == "" {
+			return oops.Errorf("Name should be set for Policy stack, it is the name of the policy")
+		}
+		if (s.Path == "") == (s.Document == "") {
+			return oops.Errorf("Policy stack %s should set one of Path or Document", s.Name)
+		}
+		if s.Region != "" || s.Workspace != "" || s.AssumeRoleName != "" || len(s.CDKContext) > 0 ||
+			len(s.CloudformationParameters) > 0 || len(s.CloudformationCapabilities) > 0 {
+			return oops.Errorf("Policy stack %s should only set Name and Path or Document", s.Name)
+		}
 		return nil
 
 	case "":
 		return oops.Errorf("stack type needs to be set for stack: %+v", s)
 
 	default:
-		return oops.Errorf("only support stack types of `Cloudformation`, `Terraform` and `CDK` not: %s", s.Type)
+		return oops.Errorf("only support stack types of `Cloudformation`, `Terraform`, `CDK` and `Policy` not: %s", s.Type)
+	}
+}
+
+// PolicyDocument returns the compacted JSON of a Policy stack. It is read from
+// Path if Document is not set. Whitespace is removed because it counts towards
+// the size limit of policies.
+func (s Stack) PolicyDocument() (string, error) {
+	document := []byte(s.Document)
+	if s.Path != "" {
+		var err error
+		document, err = os.ReadFile(s.Path)
+		if err != nil {
+			return "", oops.Wrapf(err, "reading policy %s", s.Path)
+		}
+	}
+
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, document); err != nil {
+		return "", oops.Errorf("policy %s is not valid JSON: %s", s.Name, err)
 	}
+	return compact.String(), nil
 }
 
 func (s Stack) CloudformationParametersType() ([]*cloudformation.Parameter, error) {
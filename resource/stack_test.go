@@ -3,6 +3,7 @@ package resource
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -180,3 +181,30 @@ func TestValidate(t *testing.T) {
 	}
 
 }
+
+func TestTimeoutDuration(t *testing.T) {
+	tests := []struct {
+		timeout string
+
+		want    time.Duration
+		wantErr bool
+	}{
+		{timeout: "", want: 0},
+		{timeout: "30m", want: 30 * time.Minute},
+		{timeout: "1h30m", want: 90 * time.Minute},
+		{timeout: "30", wantErr: true},
+		{timeout: "-5m", wantErr: true},
+	}
+
+	for _, tc := range tests {
+		stack := Stack{Type: "Terraform", Path: "path", Timeout: tc.timeout}
+		got, err := stack.TimeoutDuration()
+		if tc.wantErr {
+			assert.Error(t, err, tc.timeout)
+			assert.Error(t, stack.Validate(), tc.timeout)
+			continue
+		}
+		assert.NoError(t, err, tc.timeout)
+		assert.Equal(t, tc.want, got, tc.timeout)
+	}
+}
@@ -5,13 +5,16 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"os"
 	"text/template"
+	"time"
 
-	"github.com/aws/aws-sdk-go/service/organizations"
+	"github.com/aws/aws-sdk-go/service/sts"
 	"github.com/fatih/color"
 	"github.com/samsarahq/go/oops"
 	"github.com/santiago-labs/telophasecli/cmd/runner"
 	"github.com/santiago-labs/telophasecli/lib/awsorgs"
+	"github.com/santiago-labs/telophasecli/lib/awssess"
 	"github.com/santiago-labs/telophasecli/resource"
 )
 
@@ -68,19 +71,9 @@ func CollectAccountOps(
 	stackFilter string,
 ) ([]ResourceOperation, error) {
 
-	var acctStacks []resource.Stack
-	if stackFilter != "" && stackFilter != "*" {
-		baselineStacks, err := acct.FilterBaselineStacks(stackFilter)
-		if err != nil {
-			return nil, err
-		}
-		acctStacks = append(acctStacks, baselineStacks...)
-	} else {
-		baselineStacks, err := acct.AllBaselineStacks()
-		if err != nil {
-			return nil, err
-		}
-		acctStacks = append(acctStacks, baselineStacks...)
+	acctStacks, err := FilterAccountStacks(acct, stackFilter)
+	if err != nil {
+		return nil, err
 	}
 
 	var ops []ResourceOperation
@@ -90,13 +83,29 @@ func CollectAccountOps(
 		} else if stack.Type == "CDK" {
 			ops = append(ops, NewCDKOperation(consoleUI, acct, stack, operation))
 		} else if stack.Type == "Cloudformation" {
-			ops = append(ops, NewCloudformationOperation(consoleUI, acct, stack, operation))
+			op, err := NewCloudformationOperation(consoleUI, acct, stack, operation)
+			if err != nil {
+				return nil, oops.Wrapf(err, "Cloudformation stack %s", stack.Path)
+			}
+			ops = append(ops, op)
+		} else {
+			// Policy stacks are only deployed from ServiceControlPolicies.
+			return nil, oops.Errorf("%s stack %s can't be deployed to account %s, it can only be listed in ServiceControlPolicies", stack.Type, stack.Name, acct.AccountName)
 		}
 	}
 
 	return ops, nil
 }
 
+// FilterAccountStacks returns the stacks of acct matching the --stacks filter
+// in the order they are deployed.
+func FilterAccountStacks(acct *resource.Account, stackFilter string) ([]resource.Stack, error) {
+	if stackFilter != "" && stackFilter != "*" {
+		return acct.FilterBaselineStacks(stackFilter)
+	}
+	return acct.AllBaselineStacks()
+}
+
 func (ao *accountOperation) AddDependent(op ResourceOperation) {
 	ao.DependentOperations = append(ao.DependentOperations, op)
 }
@@ -107,11 +116,7 @@ func (ao *accountOperation) ListDependents() []ResourceOperation {
 
 func (ao *accountOperation) Call(ctx context.Context) error {
 	if ao.Operation == Create {
-		acct := &organizations.Account{
-			Email: &ao.Account.Email,
-			Name:  &ao.Account.AccountName,
-		}
-		acctID, err := ao.OrgClient.CreateAccount(ctx, ao.ConsoleUI, *ao.MgmtAccount, acct, ao.Account.AllTags())
+		acctID, err := ao.OrgClient.CreateAccount(ctx, ao.ConsoleUI, *ao.MgmtAccount, *ao.Account)
 		if err != nil {
 			return err
 		}
@@ -127,6 +132,10 @@ func (ao *accountOperation) Call(ctx context.Context) error {
 			return err
 		}
 
+		if err := ao.waitForRole(ctx); err != nil {
+			return err
+		}
+
 	} else if ao.Operation == UpdateParent {
 		err := ao.OrgClient.MoveAccount(ctx, ao.ConsoleUI, *ao.MgmtAccount, ao.Account.AccountID, *ao.CurrentParent.OUID, *ao.NewParent.OUID)
 		if err != nil {
@@ -148,7 +157,8 @@ func (ao *accountOperation) Call(ctx context.Context) error {
 			return fmt.Errorf("attempting to delete account: (name:%s email:%s id:%s) stopping because --allow-account-delete is not passed into telophasecli", ao.Account.AccountName, ao.Account.Email, ao.Account.AccountID)
 		}
 
-		// Stacks need to be cleaned up from an AWS account before its closed.
+		// Stacks need to be cleaned up from an AWS account before its closed
+		// with `telophasecli destroy`.
 		err := ao.OrgClient.CloseAccount(ctx, ao.Account.AccountID, ao.Account.AccountName, ao.Account.Email)
 		if err != nil {
 			return oops.Wrapf(err, "CloseAccounts")
@@ -169,6 +179,51 @@ func (ao *accountOperation) Call(ctx context.Context) error {
 	return nil
 }
 
+// waitForRole waits until the role of the new account can be assumed so that
+// the account's stacks can be deployed in the same run.
+func (ao *accountOperation) waitForRole(ctx context.Context) error {
+	if os.Getenv("TELOPHASE_BYPASS_ASSUME_ROLE") != "" {
+		return nil
+	}
+
+	sess, err := awssess.DefaultSession()
+	if err != nil {
+		return err
+	}
+	roleARN := ao.Account.AssumeRoleARN()
+	ao.ConsoleUI.Print(fmt.Sprintf("Waiting for role %s to be assumable", roleARN), *ao.Account)
+	if err := awssess.WaitForRole(ctx, sts.New(sess), roleARN, awssess.RoleReadyTimeout, 10*time.Second); err != nil {
+		return oops.Wrapf(err, "waiting for role of account %s", ao.Account.AccountName)
+	}
+	ao.ConsoleUI.Print(fmt.Sprintf("Role %s is ready", roleARN), *ao.Account)
+	return nil
+}
+
+func (ao *accountOperation) Describe() Description {
+	desc := Description{
+		Operation:              OperationName(ao.Operation),
+		ResourceType:           ao.Account.Type(),
+		ResourceID:             ao.Account.AccountID,
+		ResourceName:           ao.Account.AccountName,
+		Email:                  ao.Account.Email,
+		DelegateAdminPrincipal: ao.DelegateAdminPrincipal,
+		AllowDelete:            ao.AllowDelete,
+	}
+	// Parents are only set on the operations that change them.
+	if ao.Operation == Create || ao.Operation == UpdateParent {
+		desc.CurrentParent = parentDescription(ao.CurrentParent)
+		desc.NewParent = parentDescription(ao.NewParent)
+	}
+	if ao.Operation == Create {
+		desc.TagsAdded = ao.Account.AllTags()
+	}
+	if ao.TagsDiff != nil {
+		desc.TagsAdded = ao.TagsDiff.Added
+		desc.TagsRemoved = ao.TagsDiff.Removed
+	}
+	return desc
+}
+
 func (ao *accountOperation) ToString() string {
 	printColor := "yellow"
 	var templated string
@@ -179,6 +234,7 @@ func (ao *accountOperation) ToString() string {
 +	Email: {{ .Account.Email }}
 +	Parent ID: {{ if .NewParent.ID }}{{ .NewParent.ID }}{{else}}<computed>{{end}}
 +	Parent Name: {{ .NewParent.Name }}
++	Role Name: {{ .Account.RoleName }}
 `
 
 		if len(ao.Account.AllTags()) > 0 {
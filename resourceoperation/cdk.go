@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
@@ -15,6 +16,7 @@ import (
 	"github.com/santiago-labs/telophasecli/lib/awssts"
 	"github.com/santiago-labs/telophasecli/lib/cdk"
 	"github.com/santiago-labs/telophasecli/lib/localstack"
+	"github.com/santiago-labs/telophasecli/lib/outputs"
 	"github.com/santiago-labs/telophasecli/resource"
 )
 
@@ -24,6 +26,12 @@ type cdkOperation struct {
 	Stack               resource.Stack
 	OutputUI            runner.ConsoleUI
 	DependentOperations []ResourceOperation
+
+	PlanDir   string
+	Artifacts *PlanArtifacts
+
+	// Outputs resolves output references in the stack's CDKContext.
+	Outputs *outputs.Store
 }
 
 func NewCDKOperation(consoleUI runner.ConsoleUI, acct *resource.Account, stack resource.Stack, op int) ResourceOperation {
@@ -46,7 +54,7 @@ func (co *cdkOperation) ListDependents() []ResourceOperation {
 func (co *cdkOperation) Call(ctx context.Context) error {
 	co.OutputUI.Print(fmt.Sprintf("Executing CDK stack in %s", co.Stack.Path), *co.Account)
 
-	creds, region, err := authAWS(*co.Account, *co.Stack.RoleARN(*co.Account), co.OutputUI)
+	creds, region, err := AuthAWS(*co.Account, *co.Stack.RoleARN(*co.Account), co.OutputUI)
 	if err != nil {
 		return err
 	}
@@ -55,15 +63,38 @@ func (co *cdkOperation) Call(ctx context.Context) error {
 		region = co.Stack.Region
 	}
 
-	// We must bootstrap cdk with the account role.
-	bootstrapCDK := bootstrapCDK(creds, region, *co.Account, co.Stack)
-	if err := co.OutputUI.RunCmd(bootstrapCDK, *co.Account); err != nil {
-		return err
+	stack := co.Stack
+	stack.CDKContext, err = co.Outputs.ResolveAll(co.Stack.CDKContext)
+	if err != nil {
+		return oops.Wrapf(err, "CDKContext")
 	}
 
-	synthCDK := synthCDK(creds, *co.Account, co.Stack)
-	if err := co.OutputUI.RunCmd(synthCDK, *co.Account); err != nil {
-		return err
+	outputDir := cdk.TmpPath(*co.Account, co.Stack.Path)
+	if co.Operation == Diff && co.PlanDir != "" {
+		outputDir = filepath.Join(co.PlanDir, planArtifactName("cdk", co.Account, co.Stack))
+		co.Artifacts = &PlanArtifacts{CloudAssemblyDir: outputDir}
+	}
+	replayPlan := co.Operation == Deploy && co.Artifacts != nil
+	if replayPlan && co.Artifacts.CloudAssemblyDir == "" {
+		return oops.Errorf("plan does not include a cloud assembly for stack %s", co.Stack.Path)
+	}
+
+	// We must bootstrap cdk with the account role. Bootstrap uses the scratch
+	// output directory so it never overwrites a saved cloud assembly. Stacks
+	// being destroyed were already bootstrapped when they were deployed.
+	if co.Operation != DiffDestroy && co.Operation != Destroy {
+		bootstrapCDK := bootstrapCDK(ctx, creds, region, *co.Account, stack, cdk.TmpPath(*co.Account, co.Stack.Path))
+		if err := co.OutputUI.RunCmd(bootstrapCDK, *co.Account); err != nil {
+			return err
+		}
+	}
+
+	// A saved plan already has the synthesized cloud assembly.
+	if !replayPlan {
+		synthCDK := synthCDK(ctx, creds, *co.Account, stack, outputDir)
+		if err := co.OutputUI.RunCmd(synthCDK, *co.Account); err != nil {
+			return err
+		}
 	}
 
 	var cdkArgs []string
@@ -73,13 +104,26 @@ func (co *cdkOperation) Call(ctx context.Context) error {
 		}
 	} else if co.Operation == Deploy {
 		cdkArgs = []string{"deploy", "--require-approval", "never"}
+	} else if co.Operation == DiffDestroy {
+		// cdk has no destroy preview so list the stacks that will be
+		// destroyed.
+		co.OutputUI.Print(fmt.Sprintf("CDK stacks in %s that will be destroyed:", co.Stack.Path), *co.Account)
+		cdkArgs = []string{"list"}
+	} else if co.Operation == Destroy {
+		cdkArgs = []string{"destroy", "--force"}
 	}
 
-	cdkArgs = append(cdkArgs, cdkDefaultArgs(*co.Account, co.Stack)...)
-	// Deploy all CDK stacks every time.
-	cdkArgs = append(cdkArgs, "--all")
+	if replayPlan {
+		cdkArgs = append(cdkArgs, "--app", co.Artifacts.CloudAssemblyDir)
+	} else {
+		cdkArgs = append(cdkArgs, cdkDefaultArgs(*co.Account, stack, outputDir)...)
+	}
+	// Deploy all CDK stacks every time. list always includes every stack.
+	if co.Operation != DiffDestroy {
+		cdkArgs = append(cdkArgs, "--all")
+	}
 
-	cmd := exec.Command(localstack.CdkCmd(), cdkArgs...)
+	cmd := runner.Command(ctx, localstack.CdkCmd(), cdkArgs...)
 	cmd.Dir = co.Stack.Path
 	cmd.Env = awssts.SetEnvironCreds(os.Environ(),
 		creds,
@@ -98,19 +142,39 @@ func (co *cdkOperation) Call(ctx context.Context) error {
 	return nil
 }
 
+func (co *cdkOperation) setOutputs(store *outputs.Store) {
+	co.Outputs = store
+}
+
+func (co *cdkOperation) setPlanDir(dir string) {
+	co.PlanDir = dir
+}
+
+func (co *cdkOperation) planArtifacts() *PlanArtifacts {
+	return co.Artifacts
+}
+
+func (co *cdkOperation) setPlanArtifacts(artifacts *PlanArtifacts) {
+	co.Artifacts = artifacts
+}
+
+func (co *cdkOperation) Describe() Description {
+	return stackDescription(co.Operation, *co.Account, co.Stack)
+}
+
 func (co *cdkOperation) ToString() string {
 	return ""
 }
 
-func bootstrapCDK(creds *sts.Credentials, region string, acct resource.Account, stack resource.Stack) *exec.Cmd {
+func bootstrapCDK(ctx context.Context, creds *sts.Credentials, region string, acct resource.Account, stack resource.Stack, outputDir string) *exec.Cmd {
 	cdkArgs := append([]string{
 		"bootstrap",
 		fmt.Sprintf("aws://%s/%s", acct.AccountID, region),
 	},
-		cdkDefaultArgs(acct, stack)...,
+		cdkDefaultArgs(acct, stack, outputDir)...,
 	)
 
-	cmd := exec.Command(localstack.CdkCmd(), cdkArgs...)
+	cmd := runner.Command(ctx, localstack.CdkCmd(), cdkArgs...)
 	cmd.Dir = stack.Path
 	cmd.Env = awssts.SetEnvironCreds(os.Environ(),
 		creds,
@@ -120,13 +184,13 @@ func bootstrapCDK(creds *sts.Credentials, region string, acct resource.Account,
 	return cmd
 }
 
-func synthCDK(creds *sts.Credentials, acct resource.Account, stack resource.Stack) *exec.Cmd {
+func synthCDK(ctx context.Context, creds *sts.Credentials, acct resource.Account, stack resource.Stack, outputDir string) *exec.Cmd {
 	cdkArgs := append(
 		[]string{"synth"},
-		cdkDefaultArgs(acct, stack)...,
+		cdkDefaultArgs(acct, stack, outputDir)...,
 	)
 
-	cmd := exec.Command(localstack.CdkCmd(), cdkArgs...)
+	cmd := runner.Command(ctx, localstack.CdkCmd(), cdkArgs...)
 	cmd.Dir = stack.Path
 	cmd.Env = awssts.SetEnvironCreds(os.Environ(),
 		creds,
@@ -136,7 +200,9 @@ func synthCDK(creds *sts.Credentials, acct resource.Account, stack resource.Stac
 	return cmd
 }
 
-func authAWS(acct resource.Account, arn string, consoleUI runner.ConsoleUI) (*sts.Credentials, string, error) {
+// AuthAWS assumes arn in acct. The credentials are nil when the current
+// credentials should be used, for example in the management account.
+func AuthAWS(acct resource.Account, arn string, consoleUI runner.ConsoleUI) (*sts.Credentials, string, error) {
 	if os.Getenv("TELOPHASE_BYPASS_ASSUME_ROLE") != "" {
 		return nil, "us-east-1", nil
 	}
@@ -169,10 +235,13 @@ func authAWS(acct resource.Account, arn string, consoleUI runner.ConsoleUI) (*st
 	return role.Credentials, *sess.Config.Region, nil
 }
 
-func cdkDefaultArgs(acct resource.Account, stack resource.Stack) []string {
-	return []string{
+func cdkDefaultArgs(acct resource.Account, stack resource.Stack, outputDir string) []string {
+	args := []string{
 		"--context", fmt.Sprintf("telophaseAccountName=%s", acct.AccountName),
 		"--context", fmt.Sprintf("telophaseAccountId=%s", acct.AccountID),
-		"--output", cdk.TmpPath(acct, stack.Path),
 	}
+	for _, context := range stack.CDKContext {
+		args = append(args, "--context", context)
+	}
+	return append(args, "--output", outputDir)
 }
@@ -2,6 +2,7 @@ package resourceoperation
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"strings"
@@ -15,6 +16,7 @@ import (
 	"github.com/samsarahq/go/oops"
 	"github.com/santiago-labs/telophasecli/cmd/runner"
 	"github.com/santiago-labs/telophasecli/lib/awssess"
+	"github.com/santiago-labs/telophasecli/lib/outputs"
 	"github.com/santiago-labs/telophasecli/resource"
 )
 
@@ -25,12 +27,19 @@ type cloudformationOp struct {
 	OutputUI             runner.ConsoleUI
 	DependentOperations  []ResourceOperation
 	CloudformationClient cloudformationiface.CloudFormationAPI
+
+	PlanDir   string
+	Artifacts *PlanArtifacts
+
+	// Outputs resolves output references in the stack's parameters and
+	// records the stack's outputs after it runs.
+	Outputs *outputs.Store
 }
 
-func NewCloudformationOperation(consoleUI runner.ConsoleUI, acct *resource.Account, stack resource.Stack, op int) ResourceOperation {
-	creds, _, err := authAWS(*acct, *stack.RoleARN(*acct), consoleUI)
+func NewCloudformationOperation(consoleUI runner.ConsoleUI, acct *resource.Account, stack resource.Stack, op int) (ResourceOperation, error) {
+	creds, _, err := AuthAWS(*acct, *stack.RoleARN(*acct), consoleUI)
 	if err != nil {
-		panic(oops.Wrapf(err, "authAWS"))
+		return nil, oops.Wrapf(err, "AuthAWS")
 	}
 
 	var newCreds *credentials.Credentials
@@ -49,7 +58,7 @@ func NewCloudformationOperation(consoleUI runner.ConsoleUI, acct *resource.Accou
 		Stack:                stack,
 		OutputUI:             consoleUI,
 		CloudformationClient: cloudformationClient,
-	}
+	}, nil
 }
 
 func (co *cloudformationOp) AddDependent(op ResourceOperation) {
@@ -63,6 +72,22 @@ func (co *cloudformationOp) ListDependents() []ResourceOperation {
 func (co *cloudformationOp) Call(ctx context.Context) error {
 	co.OutputUI.Print(fmt.Sprintf("Executing Cloudformation stack in %s", co.Stack.Path), *co.Account)
 
+	if co.Operation == DiffDestroy || co.Operation == Destroy {
+		return co.destroy(ctx)
+	}
+
+	if err := co.deployOrDiff(ctx); err != nil {
+		return err
+	}
+
+	return co.storeOutputs(ctx)
+}
+
+func (co *cloudformationOp) deployOrDiff(ctx context.Context) error {
+	if co.Operation == Deploy && co.Artifacts != nil {
+		return co.executePlannedChangeSet(ctx)
+	}
+
 	cs, err := co.createChangeSet(ctx)
 	if err != nil {
 		return err
@@ -70,6 +95,9 @@ func (co *cloudformationOp) Call(ctx context.Context) error {
 	if aws.StringValue(cs.Status) == cloudformation.ChangeSetStatusFailed {
 		if strings.Contains(aws.StringValue(cs.StatusReason), "The submitted information didn't contain changes") {
 			co.OutputUI.Print(fmt.Sprintf("change set (%s) resulted in no diff, skipping", *co.Stack.ChangeSetName()), *co.Account)
+			if co.PlanDir != "" {
+				co.Artifacts = &PlanArtifacts{}
+			}
 			return nil
 		} else {
 			return oops.Errorf("change set failed, reason (%s)", aws.StringValue(cs.StatusReason))
@@ -78,11 +106,21 @@ func (co *cloudformationOp) Call(ctx context.Context) error {
 		co.OutputUI.Print("Created change set with changes:"+cs.String(), *co.Account)
 	}
 
+	if co.PlanDir != "" {
+		co.Artifacts = &PlanArtifacts{ChangeSetID: aws.StringValue(cs.ChangeSetId)}
+	}
+
 	// End call if we aren't deploying
 	if co.Operation != Deploy {
 		return nil
 	}
 
+	// The change set was created by this run so it isn't left behind.
+	if ctx.Err() != nil {
+		co.deleteChangeSet(cs.ChangeSetId)
+		return ctx.Err()
+	}
+
 	_, err = co.executeChangeSet(ctx, cs.ChangeSetId)
 	if err != nil {
 		return oops.Wrapf(err, "executing change set")
@@ -92,11 +130,113 @@ func (co *cloudformationOp) Call(ctx context.Context) error {
 	return nil
 }
 
+// executePlannedChangeSet executes the change set that was created when the
+// plan was saved instead of creating a new one.
+func (co *cloudformationOp) executePlannedChangeSet(ctx context.Context) error {
+	if co.Artifacts.ChangeSetID == "" {
+		co.OutputUI.Print(fmt.Sprintf("plan has no changes for stack: %s, skipping", *co.Stack.CloudformationStackName()), *co.Account)
+		return nil
+	}
+
+	_, err := co.executeChangeSet(ctx, aws.String(co.Artifacts.ChangeSetID))
+	if err != nil {
+		return oops.Wrapf(err, "executing planned change set")
+	}
+	co.OutputUI.Print("Executed planned change set", *co.Account)
+
+	return nil
+}
+
+// destroy deletes the stack and waits for the deletion to finish. In diff
+// mode it prints the resources that would be deleted.
+func (co *cloudformationOp) destroy(ctx context.Context) error {
+	stackName := co.Stack.CloudformationStackName()
+	stacks, err := co.CloudformationClient.DescribeStacksWithContext(ctx,
+		&cloudformation.DescribeStacksInput{
+			StackName: stackName,
+		})
+	if err != nil {
+		if strings.Contains(err.Error(), "does not exist") {
+			co.OutputUI.Print(fmt.Sprintf("stack (%s) does not exist, skipping", *stackName), *co.Account)
+			return nil
+		}
+		return oops.Wrapf(err, "describe stack with name: (%s)", *stackName)
+	}
+	if len(stacks.Stacks) != 1 {
+		return oops.Errorf("expected 1 stack with name: (%s) found %d", *stackName, len(stacks.Stacks))
+	}
+	stackID := stacks.Stacks[0].StackId
+
+	if co.Operation == DiffDestroy {
+		resources, err := co.CloudformationClient.DescribeStackResourcesWithContext(ctx,
+			&cloudformation.DescribeStackResourcesInput{
+				StackName: stackID,
+			})
+		if err != nil {
+			return oops.Wrapf(err, "DescribeStackResources")
+		}
+
+		co.OutputUI.Print(fmt.Sprintf("stack (%s) will be destroyed with %d resource(s)", *stackName, len(resources.StackResources)), *co.Account)
+		for _, r := range resources.StackResources {
+			co.OutputUI.Print(fmt.Sprintf("-	%s (%s) %s", aws.StringValue(r.LogicalResourceId), aws.StringValue(r.ResourceType), aws.StringValue(r.PhysicalResourceId)), *co.Account)
+		}
+		return nil
+	}
+
+	_, err = co.CloudformationClient.DeleteStackWithContext(ctx,
+		&cloudformation.DeleteStackInput{
+			StackName: stackID,
+		})
+	if err != nil {
+		return oops.Wrapf(err, "DeleteStack")
+	}
+
+	for {
+		// Deleted stacks can only be described by their ID.
+		stacks, err := co.CloudformationClient.DescribeStacksWithContext(ctx,
+			&cloudformation.DescribeStacksInput{
+				StackName: stackID,
+			})
+		if err != nil {
+			return oops.Wrapf(err, "DescribeStacks")
+		}
+		if len(stacks.Stacks) != 1 {
+			return oops.Errorf("expected 1 stack with id: (%s) found %d", *stackID, len(stacks.Stacks))
+		}
+
+		state := aws.StringValue(stacks.Stacks[0].StackStatus)
+		switch state {
+		case cloudformation.StackStatusDeleteInProgress:
+			co.OutputUI.Print(fmt.Sprintf("Still deleting stack: (%s) for path: %s", *stackName, co.Stack.Path), *co.Account)
+
+		case cloudformation.StackStatusDeleteComplete:
+			co.OutputUI.Print(fmt.Sprintf("Successfully deleted stack: (%s) for path: %s", *stackName, co.Stack.Path), *co.Account)
+			return nil
+
+		case cloudformation.StackStatusDeleteFailed:
+			co.OutputUI.Print(fmt.Sprintf("Failed to delete stack: (%s) for path: %s Reason: %s", *stackName, co.Stack.Path, aws.StringValue(stacks.Stacks[0].StackStatusReason)), *co.Account)
+			return oops.Errorf("DeleteStack failed")
+		}
+
+		if err := sleepContext(ctx, pollInterval); err != nil {
+			co.OutputUI.Print(fmt.Sprintf("%s, Cloudformation is still deleting stack: (%s)", stoppedReason(ctx), *stackName), *co.Account)
+			return err
+		}
+	}
+}
+
 func (co *cloudformationOp) createChangeSet(ctx context.Context) (*cloudformation.DescribeChangeSetOutput, error) {
 	params, err := co.Stack.CloudformationParametersType()
 	if err != nil {
 		return nil, oops.Wrapf(err, "CloudformationParameters")
 	}
+	for _, param := range params {
+		value, err := co.Outputs.Resolve(aws.StringValue(param.ParameterValue))
+		if err != nil {
+			return nil, oops.Wrapf(err, "CloudformationParameters")
+		}
+		param.ParameterValue = aws.String(value)
+	}
 
 	// If we can find the stack then we just update. If not then we continue on
 	changeSetType := cloudformation.ChangeSetTypeUpdate
@@ -146,6 +286,9 @@ func (co *cloudformationOp) createChangeSet(ctx context.Context) (*cloudformatio
 				ChangeSetName: changeSet.Id,
 			})
 		if err != nil {
+			if ctx.Err() != nil {
+				co.deleteChangeSet(changeSet.Id)
+			}
 			return nil, oops.Wrapf(err, "DescribeChangeSet")
 		}
 
@@ -162,7 +305,10 @@ func (co *cloudformationOp) createChangeSet(ctx context.Context) (*cloudformatio
 			return cs, nil
 		}
 
-		time.Sleep(5 * time.Second)
+		if err := sleepContext(ctx, pollInterval); err != nil {
+			co.deleteChangeSet(changeSet.Id)
+			return nil, err
+		}
 	}
 }
 
@@ -198,10 +344,102 @@ func (co *cloudformationOp) executeChangeSet(ctx context.Context, changeSetID *s
 			return cs, oops.Errorf("ExecuteChangeSet failed")
 		}
 
-		time.Sleep(5 * time.Second)
+		if err := sleepContext(ctx, pollInterval); err != nil {
+			co.OutputUI.Print(fmt.Sprintf("%s, Cloudformation is still executing the change set for stack: (%s)", stoppedReason(ctx), *co.Stack.CloudformationStackName()), *co.Account)
+			return nil, err
+		}
+	}
+}
+
+// deleteChangeSet deletes a change set that won't be executed because the
+// operation was interrupted. It doesn't use the operation's context because
+// that is canceled.
+func (co *cloudformationOp) deleteChangeSet(changeSetID *string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := co.CloudformationClient.DeleteChangeSetWithContext(ctx,
+		&cloudformation.DeleteChangeSetInput{
+			ChangeSetName: changeSetID,
+			StackName:     co.Stack.CloudformationStackName(),
+		})
+	if err != nil {
+		co.OutputUI.Print(fmt.Sprintf("Error deleting interrupted change set (%s): %s", aws.StringValue(changeSetID), err), *co.Account)
+		return
 	}
+	co.OutputUI.Print(fmt.Sprintf("Deleted interrupted change set (%s)", aws.StringValue(changeSetID)), *co.Account)
+}
+
+// storeOutputs records the outputs of the stack. Stacks that don't exist yet,
+// as in a diff of a new stack, have no outputs.
+func (co *cloudformationOp) storeOutputs(ctx context.Context) error {
+	if co.Outputs == nil || co.Stack.Name == "" {
+		return nil
+	}
+
+	stacks, err := co.CloudformationClient.DescribeStacksWithContext(ctx,
+		&cloudformation.DescribeStacksInput{
+			StackName: co.Stack.CloudformationStackName(),
+		})
+	if err != nil {
+		if strings.Contains(err.Error(), "does not exist") {
+			return nil
+		}
+		return oops.Wrapf(err, "describe stack with name: (%s)", *co.Stack.CloudformationStackName())
+	}
+
+	values := map[string]string{}
+	for _, stack := range stacks.Stacks {
+		for _, output := range stack.Outputs {
+			values[aws.StringValue(output.OutputKey)] = aws.StringValue(output.OutputValue)
+		}
+	}
+	storeOutputs(co.Outputs, *co.Account, co.Stack, values)
+	return nil
+}
+
+func (co *cloudformationOp) setOutputs(store *outputs.Store) {
+	co.Outputs = store
+}
+
+func (co *cloudformationOp) setPlanDir(dir string) {
+	co.PlanDir = dir
+}
+
+func (co *cloudformationOp) planArtifacts() *PlanArtifacts {
+	return co.Artifacts
+}
+
+func (co *cloudformationOp) setPlanArtifacts(artifacts *PlanArtifacts) {
+	co.Artifacts = artifacts
+}
+
+func (co *cloudformationOp) Describe() Description {
+	return stackDescription(co.Operation, *co.Account, co.Stack)
 }
 
 func (co *cloudformationOp) ToString() string {
 	return ""
 }
+
+// pollInterval is how long to wait between checks of Cloudformation
+// operations.
+const pollInterval = 5 * time.Second
+
+// sleepContext waits for d or until ctx is canceled.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
+
+// stoppedReason describes why ctx was canceled.
+func stoppedReason(ctx context.Context) string {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return "Timed out"
+	}
+	return "Interrupted"
+}
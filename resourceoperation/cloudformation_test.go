@@ -0,0 +1,110 @@
+package resourceoperation
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/aws/aws-sdk-go/service/cloudformation/cloudformationiface"
+	"github.com/santiago-labs/telophasecli/cmd/runner"
+	"github.com/santiago-labs/telophasecli/lib/outputs"
+	"github.com/santiago-labs/telophasecli/resource"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeCloudformation returns statuses in order from DescribeStacks.
+type fakeCloudformation struct {
+	cloudformationiface.CloudFormationAPI
+
+	statuses []string
+	outputs  []*cloudformation.Output
+	deleted  bool
+}
+
+func (f *fakeCloudformation) DescribeStacksWithContext(_ context.Context, _ *cloudformation.DescribeStacksInput, _ ...request.Option) (*cloudformation.DescribeStacksOutput, error) {
+	if len(f.statuses) == 0 {
+		return nil, fmt.Errorf("Stack with id test does not exist")
+	}
+	status := f.statuses[0]
+	f.statuses = f.statuses[1:]
+	return &cloudformation.DescribeStacksOutput{
+		Stacks: []*cloudformation.Stack{
+			{StackId: aws.String("arn:stack"), StackStatus: aws.String(status), Outputs: f.outputs},
+		},
+	}, nil
+}
+
+func (f *fakeCloudformation) DeleteStackWithContext(_ context.Context, _ *cloudformation.DeleteStackInput, _ ...request.Option) (*cloudformation.DeleteStackOutput, error) {
+	f.deleted = true
+	return &cloudformation.DeleteStackOutput{}, nil
+}
+
+func TestCloudformationDestroy(t *testing.T) {
+	tests := []struct {
+		description string
+		statuses    []string
+		wantDeleted bool
+		wantErr     bool
+	}{
+		{
+			description: "stack does not exist",
+		},
+		{
+			description: "stack deleted",
+			statuses:    []string{cloudformation.StackStatusCreateComplete, cloudformation.StackStatusDeleteComplete},
+			wantDeleted: true,
+		},
+		{
+			description: "stack delete failed",
+			statuses:    []string{cloudformation.StackStatusCreateComplete, cloudformation.StackStatusDeleteFailed},
+			wantDeleted: true,
+			wantErr:     true,
+		},
+	}
+
+	for _, tc := range tests {
+		client := &fakeCloudformation{statuses: tc.statuses}
+		op := &cloudformationOp{
+			Account:              &resource.Account{AccountID: "111111111111"},
+			Operation:            Destroy,
+			Stack:                resource.Stack{Type: "Cloudformation", Path: "./cf/table.yml"},
+			OutputUI:             runner.NewSTDErr(),
+			CloudformationClient: client,
+		}
+
+		err := op.Call(context.Background())
+		assert.Equal(t, tc.wantErr, err != nil, tc.description)
+		assert.Equal(t, tc.wantDeleted, client.deleted, tc.description)
+	}
+}
+
+func TestCloudformationStoreOutputs(t *testing.T) {
+	store := outputs.NewStore()
+	op := &cloudformationOp{
+		Account:   &resource.Account{AccountID: "111111111111", AccountName: "log-archive"},
+		Operation: Deploy,
+		Stack:     resource.Stack{Type: "Cloudformation", Path: "./cf/bucket.yml", Name: "bucket, logs"},
+		OutputUI:  runner.NewSTDErr(),
+		CloudformationClient: &fakeCloudformation{
+			statuses: []string{cloudformation.StackStatusCreateComplete},
+			outputs: []*cloudformation.Output{
+				{OutputKey: aws.String("BucketArn"), OutputValue: aws.String("arn:aws:s3:::logs")},
+			},
+		},
+		Outputs: store,
+	}
+
+	assert.NoError(t, op.storeOutputs(context.Background()))
+	for _, name := range []string{"bucket", "logs"} {
+		value, ok := store.Get(outputs.Reference{Account: "log-archive", Stack: name, Key: "BucketArn"})
+		assert.True(t, ok, name)
+		assert.Equal(t, "arn:aws:s3:::logs", value, name)
+	}
+
+	// A stack that doesn't exist yet has no outputs.
+	op.CloudformationClient = &fakeCloudformation{}
+	assert.NoError(t, op.storeOutputs(context.Background()))
+}
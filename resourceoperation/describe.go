@@ -0,0 +1,88 @@
+package resourceoperation
+
+import (
+	"github.com/santiago-labs/telophasecli/resource"
+)
+
+// Description is the structured form of an operation. It is what `--output
+// json` prints and what plans are built from.
+type Description struct {
+	Operation    string `json:"operation,omitempty"`
+	ResourceType string `json:"resource_type"`
+	ResourceID   string `json:"resource_id,omitempty"`
+	ResourceName string `json:"resource_name,omitempty"`
+	Email        string `json:"email,omitempty"`
+
+	CurrentParent *ParentDescription `json:"current_parent,omitempty"`
+	NewParent     *ParentDescription `json:"new_parent,omitempty"`
+	NewName       string             `json:"new_name,omitempty"`
+	// Recreate is set when the resource is replaced by a new one with a new
+	// ID, like an Organization Unit that is moved.
+	Recreate bool `json:"recreate,omitempty"`
+
+	TagsAdded              []string        `json:"tags_added,omitempty"`
+	TagsRemoved            []string        `json:"tags_removed,omitempty"`
+	DelegateAdminPrincipal string          `json:"delegate_admin_principal,omitempty"`
+	AllowDelete            bool            `json:"allow_delete,omitempty"`
+	Stack                  *resource.Stack `json:"stack,omitempty"`
+
+	// PolicyDocument is the new document of a Service Control Policy that is
+	// created or updated.
+	PolicyDocument  string   `json:"policy_document,omitempty"`
+	TargetsAttached []string `json:"targets_attached,omitempty"`
+	TargetsDetached []string `json:"targets_detached,omitempty"`
+}
+
+// ParentDescription is an OU that a resource is moved from or to. The ID is
+// empty when the OU has not been created yet.
+type ParentDescription struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name"`
+}
+
+// OperationName returns the name used for an operation in structured output.
+func OperationName(operation int) string {
+	switch operation {
+	case UpdateParent:
+		return "UpdateParent"
+	case Create:
+		return "Create"
+	case Update:
+		return "Update"
+	case Diff:
+		return "Diff"
+	case Deploy:
+		return "Deploy"
+	case UpdateTags:
+		return "UpdateTags"
+	case Delete:
+		return "Delete"
+	case DelegateAdmin:
+		return "DelegateAdmin"
+	case DiffDestroy:
+		return "DiffDestroy"
+	case Destroy:
+		return "Destroy"
+	}
+	return ""
+}
+
+func parentDescription(ou *resource.OrganizationUnit) *ParentDescription {
+	if ou == nil {
+		return nil
+	}
+	return &ParentDescription{
+		ID:   ou.ID(),
+		Name: ou.Name(),
+	}
+}
+
+func stackDescription(operation int, acct resource.Account, stack resource.Stack) Description {
+	return Description{
+		Operation:    OperationName(operation),
+		ResourceType: stack.Type,
+		ResourceID:   acct.AccountID,
+		ResourceName: acct.AccountName,
+		Stack:        &stack,
+	}
+}
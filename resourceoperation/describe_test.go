@@ -0,0 +1,64 @@
+package resourceoperation
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/santiago-labs/telophasecli/lib/awsorgs"
+	"github.com/santiago-labs/telophasecli/resource"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDescribe(t *testing.T) {
+	currentOUID := "ou-current"
+	currentOU := &resource.OrganizationUnit{OUName: "Current", OUID: &currentOUID}
+	newOU := &resource.OrganizationUnit{OUName: "New"}
+	acct := &resource.Account{
+		AccountID:   "111111111111",
+		AccountName: "dev",
+		Email:       "dev@example.com",
+		Tags:        []string{"env=dev"},
+		Parent:      newOU,
+	}
+
+	tests := []struct {
+		description string
+		op          ResourceOperation
+		want        string
+	}{
+		{
+			description: "create account",
+			op:          NewAccountOperation(awsorgs.Client{}, nil, acct, nil, Create, newOU, nil, nil),
+			want:        `{"operation":"Create","resource_type":"Account","resource_id":"111111111111","resource_name":"dev","email":"dev@example.com","new_parent":{"name":"New"},"tags_added":["AccountName=dev","env=dev"]}`,
+		},
+		{
+			description: "move account",
+			op:          NewAccountOperation(awsorgs.Client{}, nil, acct, nil, UpdateParent, newOU, currentOU, nil),
+			want:        `{"operation":"UpdateParent","resource_type":"Account","resource_id":"111111111111","resource_name":"dev","email":"dev@example.com","current_parent":{"id":"ou-current","name":"Current"},"new_parent":{"name":"New"}}`,
+		},
+		{
+			description: "update organization unit tags",
+			op: NewOrganizationUnitOperation(awsorgs.Client{}, nil, currentOU, nil, UpdateTags, nil, nil, nil, &TagsDiff{
+				Added:   []string{"env=prod"},
+				Removed: []string{"env=dev"},
+			}),
+			want: `{"operation":"UpdateTags","resource_type":"Organization Unit","resource_id":"ou-current","resource_name":"Current","tags_added":["env=prod"],"tags_removed":["env=dev"]}`,
+		},
+		{
+			description: "move organization unit",
+			op:          NewOrganizationUnitOperation(awsorgs.Client{}, nil, currentOU, nil, UpdateParent, newOU, currentOU, nil, nil),
+			want:        `{"operation":"UpdateParent","resource_type":"Organization Unit","resource_id":"ou-current","resource_name":"Current","current_parent":{"id":"ou-current","name":"Current"},"new_parent":{"name":"New"},"recreate":true}`,
+		},
+		{
+			description: "rename organization unit",
+			op:          NewOrganizationUnitOperation(awsorgs.Client{}, nil, currentOU, nil, Update, nil, nil, &newOU.OUName, nil),
+			want:        `{"operation":"Update","resource_type":"Organization Unit","resource_id":"ou-current","resource_name":"Current","new_name":"New"}`,
+		},
+	}
+
+	for _, tc := range tests {
+		got, err := json.Marshal(tc.op.Describe())
+		assert.NoError(t, err, tc.description)
+		assert.JSONEq(t, tc.want, string(got), tc.description)
+	}
+}
@@ -0,0 +1,216 @@
+package resourceoperation
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/santiago-labs/telophasecli/resource"
+)
+
+const (
+	// DriftUnmanaged is a resource that exists in AWS but not in
+	// organization.yml.
+	DriftUnmanaged = "unmanaged"
+	// DriftMissing is a resource in organization.yml that does not exist in
+	// AWS.
+	DriftMissing = "missing"
+	// DriftParent is an account in a different Organization Unit than in
+	// organization.yml.
+	DriftParent = "parent"
+	// DriftTags is a resource whose tags differ from organization.yml.
+	DriftTags = "tags"
+	// DriftDelegatedAdmin is an account whose delegated administrator
+	// services differ from organization.yml.
+	DriftDelegatedAdmin = "delegated_admin"
+)
+
+// Drift is a difference between organization.yml and the live AWS
+// Organization.
+type Drift struct {
+	Kind         string   `json:"kind"`
+	ResourceType string   `json:"resource_type"`
+	ResourceID   string   `json:"resource_id,omitempty"`
+	ResourceName string   `json:"resource_name"`
+	Expected     string   `json:"expected,omitempty"`
+	Actual       string   `json:"actual,omitempty"`
+	Added        []string `json:"added,omitempty"`
+	Removed      []string `json:"removed,omitempty"`
+}
+
+func (d Drift) String() string {
+	name := d.ResourceName
+	if d.ResourceID != "" {
+		name = fmt.Sprintf("%s (%s)", d.ResourceName, d.ResourceID)
+	}
+
+	switch d.Kind {
+	case DriftUnmanaged:
+		return fmt.Sprintf("%s %s exists in AWS but not in organization.yml", d.ResourceType, name)
+	case DriftMissing:
+		return fmt.Sprintf("%s %s is in organization.yml but does not exist in AWS", d.ResourceType, name)
+	case DriftParent:
+		return fmt.Sprintf("%s %s is in Organization Unit %s, expected %s", d.ResourceType, name, d.Actual, d.Expected)
+	case DriftTags:
+		return fmt.Sprintf("%s %s tags differ:%s", d.ResourceType, name, listDiff(d.Added, d.Removed))
+	case DriftDelegatedAdmin:
+		return fmt.Sprintf("%s %s delegated administrator services differ:%s", d.ResourceType, name, listDiff(d.Added, d.Removed))
+	}
+	return fmt.Sprintf("%s %s has drifted", d.ResourceType, name)
+}
+
+// listDiff formats what organization.yml has (+) and AWS has (-).
+func listDiff(added, removed []string) string {
+	var b strings.Builder
+	for _, a := range added {
+		b.WriteString("\n+	" + a)
+	}
+	for _, r := range removed {
+		b.WriteString("\n-	" + r)
+	}
+	return b.String()
+}
+
+// DetectDrift compares the parsed organization with the live organization
+// fetched with FetchOUAndDescendents. Added and Removed are what applying
+// organization.yml would add and remove.
+func DetectDrift(
+	parsedRoot *resource.OrganizationUnit,
+	liveRoot *resource.OrganizationUnit,
+	delegatedAdmins map[string][]string,
+) []Drift {
+	var drifts []Drift
+
+	parsedOUs := append([]*resource.OrganizationUnit{parsedRoot}, parsedRoot.AllDescendentOUs()...)
+	parsedOUIDs := map[string]bool{}
+	for _, ou := range parsedOUs {
+		if ou.OUID != nil {
+			parsedOUIDs[*ou.OUID] = true
+		}
+	}
+
+	for _, ou := range liveRoot.AllDescendentOUs() {
+		if !parsedOUIDs[ou.ID()] {
+			drifts = append(drifts, Drift{
+				Kind:         DriftUnmanaged,
+				ResourceType: ou.Type(),
+				ResourceID:   ou.ID(),
+				ResourceName: ou.Name(),
+			})
+		}
+	}
+
+	for _, ou := range parsedOUs {
+		if ou.OUID == nil {
+			drifts = append(drifts, Drift{
+				Kind:         DriftMissing,
+				ResourceType: ou.Type(),
+				ResourceName: ou.Name(),
+			})
+			continue
+		}
+		if added, removed := diffTags(ou); len(added) > 0 || len(removed) > 0 {
+			drifts = append(drifts, Drift{
+				Kind:         DriftTags,
+				ResourceType: ou.Type(),
+				ResourceID:   ou.ID(),
+				ResourceName: ou.Name(),
+				Added:        added,
+				Removed:      removed,
+			})
+		}
+	}
+
+	liveAccounts := map[string]*resource.Account{}
+	for _, acct := range liveRoot.AllDescendentAccounts() {
+		liveAccounts[acct.Email] = acct
+	}
+
+	parsedEmails := map[string]bool{}
+	for _, acct := range parsedRoot.AllDescendentAccounts() {
+		parsedEmails[acct.Email] = true
+
+		// Accounts being deleted are expected to differ.
+		if acct.Delete {
+			continue
+		}
+
+		liveAcct, ok := liveAccounts[acct.Email]
+		if !ok {
+			drifts = append(drifts, Drift{
+				Kind:         DriftMissing,
+				ResourceType: acct.Type(),
+				ResourceName: acct.AccountName,
+			})
+			continue
+		}
+
+		if acct.Parent != nil && acct.Parent.ID() != liveAcct.Parent.ID() {
+			drifts = append(drifts, Drift{
+				Kind:         DriftParent,
+				ResourceType: acct.Type(),
+				ResourceID:   liveAcct.AccountID,
+				ResourceName: acct.AccountName,
+				Expected:     ouLabel(acct.Parent),
+				Actual:       ouLabel(liveAcct.Parent),
+			})
+		}
+
+		if added, removed := diffTags(acct); len(added) > 0 || len(removed) > 0 {
+			drifts = append(drifts, Drift{
+				Kind:         DriftTags,
+				ResourceType: acct.Type(),
+				ResourceID:   liveAcct.AccountID,
+				ResourceName: acct.AccountName,
+				Added:        added,
+				Removed:      removed,
+			})
+		}
+
+		if delegatedAdmins != nil {
+			liveServices := delegatedAdmins[liveAcct.AccountID]
+			var added, removed []string
+			for _, service := range acct.DelegatedAdministratorServices {
+				if !oneOf(service, liveServices) {
+					added = append(added, service)
+				}
+			}
+			for _, service := range liveServices {
+				if !oneOf(service, acct.DelegatedAdministratorServices) {
+					removed = append(removed, service)
+				}
+			}
+			if len(added) > 0 || len(removed) > 0 {
+				drifts = append(drifts, Drift{
+					Kind:         DriftDelegatedAdmin,
+					ResourceType: acct.Type(),
+					ResourceID:   liveAcct.AccountID,
+					ResourceName: acct.AccountName,
+					Added:        added,
+					Removed:      removed,
+				})
+			}
+		}
+	}
+
+	for _, acct := range liveRoot.AllDescendentAccounts() {
+		// The management account does not need to be managed by Telophase.
+		if parsedEmails[acct.Email] || acct.ManagementAccount {
+			continue
+		}
+		drifts = append(drifts, Drift{
+			Kind:         DriftUnmanaged,
+			ResourceType: acct.Type(),
+			ResourceID:   acct.AccountID,
+			ResourceName: acct.AccountName,
+		})
+	}
+
+	return drifts
+}
+
+func ouLabel(ou *resource.OrganizationUnit) string {
+	if ou.ID() == "" {
+		return ou.Name()
+	}
+	return fmt.Sprintf("%s (%s)", ou.Name(), ou.ID())
+}
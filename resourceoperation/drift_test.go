@@ -0,0 +1,62 @@
+package resourceoperation
+
+import (
+	"testing"
+
+	"github.com/santiago-labs/telophasecli/resource"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectDrift(t *testing.T) {
+	rootID := "r-root"
+	devID := "ou-dev"
+	prodID := "ou-prod"
+	clickOpsID := "ou-clickops"
+
+	parsedRoot := &resource.OrganizationUnit{OUName: "root", OUID: &rootID}
+	parsedDev := &resource.OrganizationUnit{OUName: "Dev", OUID: &devID, Parent: parsedRoot, Tags: []string{"env=dev"}, AWSTags: []string{"env=dev"}}
+	parsedProd := &resource.OrganizationUnit{OUName: "Prod", OUID: &prodID, Parent: parsedRoot, Tags: []string{"env=prod"}, AWSTags: []string{"env=production"}}
+	parsedNew := &resource.OrganizationUnit{OUName: "New", Parent: parsedRoot}
+	parsedRoot.ChildOUs = []*resource.OrganizationUnit{parsedDev, parsedProd, parsedNew}
+
+	parsedDev.Accounts = []*resource.Account{
+		{AccountID: "111111111111", AccountName: "alice", Email: "alice@example.com", Parent: parsedDev},
+		{AccountID: "222222222222", AccountName: "bob", Email: "bob@example.com", Parent: parsedDev, DelegatedAdministratorServices: []string{"guardduty.amazonaws.com"}},
+		{AccountName: "carol", Email: "carol@example.com", Parent: parsedDev},
+		{AccountName: "deleted", Email: "deleted@example.com", Parent: parsedDev, Delete: true},
+	}
+
+	liveRoot := &resource.OrganizationUnit{OUName: "root", OUID: &rootID}
+	liveDev := &resource.OrganizationUnit{OUName: "Dev", OUID: &devID, Parent: liveRoot}
+	liveProd := &resource.OrganizationUnit{OUName: "Prod", OUID: &prodID, Parent: liveRoot}
+	liveClickOps := &resource.OrganizationUnit{OUName: "ClickOps", OUID: &clickOpsID, Parent: liveRoot}
+	liveRoot.ChildOUs = []*resource.OrganizationUnit{liveDev, liveProd, liveClickOps}
+	liveRoot.Accounts = []*resource.Account{
+		{AccountID: "000000000000", AccountName: "mgmt", Email: "mgmt@example.com", Parent: liveRoot, ManagementAccount: true},
+	}
+	liveDev.Accounts = []*resource.Account{
+		{AccountID: "222222222222", AccountName: "bob", Email: "bob@example.com", Parent: liveDev},
+	}
+	liveProd.Accounts = []*resource.Account{
+		{AccountID: "111111111111", AccountName: "alice", Email: "alice@example.com", Parent: liveProd},
+		{AccountID: "333333333333", AccountName: "eve", Email: "eve@example.com", Parent: liveProd},
+	}
+
+	delegatedAdmins := map[string][]string{
+		"222222222222": {"securityhub.amazonaws.com"},
+	}
+
+	assert.Equal(t, []Drift{
+		{Kind: DriftUnmanaged, ResourceType: "Organization Unit", ResourceID: "ou-clickops", ResourceName: "ClickOps"},
+		{Kind: DriftTags, ResourceType: "Organization Unit", ResourceID: "ou-prod", ResourceName: "Prod", Added: []string{"env=prod"}, Removed: []string{"env=production"}},
+		{Kind: DriftMissing, ResourceType: "Organization Unit", ResourceName: "New"},
+		{Kind: DriftParent, ResourceType: "Account", ResourceID: "111111111111", ResourceName: "alice", Expected: "Dev (ou-dev)", Actual: "Prod (ou-prod)"},
+		{Kind: DriftDelegatedAdmin, ResourceType: "Account", ResourceID: "222222222222", ResourceName: "bob", Added: []string{"guardduty.amazonaws.com"}, Removed: []string{"securityhub.amazonaws.com"}},
+		{Kind: DriftMissing, ResourceType: "Account", ResourceName: "carol"},
+		{Kind: DriftUnmanaged, ResourceType: "Account", ResourceID: "333333333333", ResourceName: "eve"},
+	}, DetectDrift(parsedRoot, liveRoot, delegatedAdmins))
+
+	assert.Equal(t, "Account alice (111111111111) is in Organization Unit Prod (ou-prod), expected Dev (ou-dev)", Drift{
+		Kind: DriftParent, ResourceType: "Account", ResourceID: "111111111111", ResourceName: "alice", Expected: "Dev (ou-dev)", Actual: "Prod (ou-prod)",
+	}.String())
+}
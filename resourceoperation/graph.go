@@ -0,0 +1,292 @@
+package resourceoperation
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/santiago-labs/telophasecli/resource"
+)
+
+const (
+	// EdgeDependency is an operation that is called by the operation before
+	// it, e.g. creating an account in an OU that is created first.
+	EdgeDependency = "dependency"
+	// EdgeOrder is an operation that runs after the operation before it
+	// finishes.
+	EdgeOrder = "order"
+	// EdgeDependsOn is a stack that runs after a stack in its DependsOn.
+	EdgeDependsOn = "dependsOn"
+	// EdgeRollout is a stack that runs after the stacks in the previous wave
+	// of its Rollout.
+	EdgeRollout = "rollout"
+)
+
+// Graph is the order a deploy runs its operations in. Organization operations
+// run one at a time, then the stacks of every account run in parallel with
+// each account's stacks in order and after the stacks they depend on and the
+// earlier waves of their Rollout, then Service Control Policies run in
+// parallel.
+type Graph struct {
+	Nodes []GraphNode
+	Edges []GraphEdge
+}
+
+type GraphNode struct {
+	ID    string
+	Label []string
+	// Group is the phase or account the operation runs in.
+	Group string
+}
+
+type GraphEdge struct {
+	From string
+	To   string
+	Kind string
+}
+
+// AccountStacks are the stacks deployed to an account. Stacks are used instead
+// of operations so that accounts the deploy creates can be included.
+type AccountStacks struct {
+	Account resource.Account
+	Stacks  []resource.Stack
+}
+
+// NewGraph builds the graph of a deploy. It returns an error if the stacks
+// depend on each other.
+func NewGraph(orgOps []ResourceOperation, accountStacks []AccountStacks, scpOps []ResourceOperation) (*Graph, error) {
+	g := &Graph{}
+
+	const orgGroup = "AWS Organization"
+	var orgEnds []string
+	for _, op := range orgOps {
+		id := g.addOperation(op, orgGroup)
+		g.addOrderEdges(orgEnds, id)
+		orgEnds = []string{id}
+	}
+
+	var nodes []resource.AccountStack
+	var ids []string
+	for i := range accountStacks {
+		acct := &accountStacks[i].Account
+		for _, stack := range accountStacks[i].Stacks {
+			desc := stackDescription(Deploy, *acct, stack)
+			ids = append(ids, g.addNode(desc, fmt.Sprintf("Stacks: %s", accountLabel(desc))))
+			nodes = append(nodes, resource.AccountStack{Account: acct, Stack: stack})
+		}
+	}
+
+	deps, err := resource.StackDependencies(nodes)
+	if err != nil {
+		return nil, err
+	}
+
+	steps := resource.RolloutSteps(nodes)
+
+	hasDependents := make([]bool, len(nodes))
+	for i, id := range ids {
+		if len(deps[i]) == 0 && len(steps[i].Waits) == 0 {
+			g.addOrderEdges(orgEnds, id)
+		}
+		for _, dep := range deps[i] {
+			hasDependents[dep] = true
+			kind := EdgeDependsOn
+			if dep == i-1 && nodes[dep].Account == nodes[i].Account {
+				kind = EdgeOrder
+			}
+			g.Edges = append(g.Edges, GraphEdge{From: ids[dep], To: id, Kind: kind})
+		}
+		for _, wait := range steps[i].Waits {
+			hasDependents[wait] = true
+			g.Edges = append(g.Edges, GraphEdge{From: ids[wait], To: id, Kind: EdgeRollout})
+		}
+	}
+
+	var stackEnds []string
+	for i, id := range ids {
+		if !hasDependents[i] {
+			stackEnds = append(stackEnds, id)
+		}
+	}
+	if len(stackEnds) == 0 {
+		stackEnds = orgEnds
+	}
+
+	const scpGroup = "Service Control Policies"
+	for _, op := range scpOps {
+		id := g.addOperation(op, scpGroup)
+		g.addOrderEdges(stackEnds, id)
+	}
+
+	return g, nil
+}
+
+// addOperation adds op and its dependents and returns the ID of op.
+func (g *Graph) addOperation(op ResourceOperation, group string) string {
+	id := g.addNode(op.Describe(), group)
+
+	for _, dependent := range op.ListDependents() {
+		dependentID := g.addOperation(dependent, group)
+		g.Edges = append(g.Edges, GraphEdge{From: id, To: dependentID, Kind: EdgeDependency})
+	}
+
+	return id
+}
+
+func (g *Graph) addNode(desc Description, group string) string {
+	id := fmt.Sprintf("op%d", len(g.Nodes))
+	g.Nodes = append(g.Nodes, GraphNode{
+		ID:    id,
+		Label: operationLabel(desc),
+		Group: group,
+	})
+	return id
+}
+
+func (g *Graph) addOrderEdges(from []string, to string) {
+	for _, id := range from {
+		g.Edges = append(g.Edges, GraphEdge{From: id, To: to, Kind: EdgeOrder})
+	}
+}
+
+func (g *Graph) groups() []string {
+	var groups []string
+	seen := map[string]bool{}
+	for _, node := range g.Nodes {
+		if !seen[node.Group] {
+			seen[node.Group] = true
+			groups = append(groups, node.Group)
+		}
+	}
+	return groups
+}
+
+// DOT renders the graph for Graphviz.
+func (g *Graph) DOT() string {
+	var b strings.Builder
+	b.WriteString("digraph telophase {\n")
+	b.WriteString("  rankdir=TB;\n")
+	b.WriteString("  node [shape=box];\n")
+
+	for i, group := range g.groups() {
+		fmt.Fprintf(&b, "  subgraph cluster_%d {\n", i)
+		fmt.Fprintf(&b, "    label=%s;\n", dotQuote(group))
+		for _, node := range g.Nodes {
+			if node.Group == group {
+				fmt.Fprintf(&b, "    %s [label=%s];\n", node.ID, dotQuote(strings.Join(node.Label, "\n")))
+			}
+		}
+		b.WriteString("  }\n")
+	}
+
+	for _, edge := range g.Edges {
+		switch edge.Kind {
+		case EdgeOrder:
+			fmt.Fprintf(&b, "  %s -> %s [style=dashed];\n", edge.From, edge.To)
+		case EdgeDependsOn:
+			fmt.Fprintf(&b, "  %s -> %s [style=bold];\n", edge.From, edge.To)
+		case EdgeRollout:
+			fmt.Fprintf(&b, "  %s -> %s [style=dotted];\n", edge.From, edge.To)
+		default:
+			fmt.Fprintf(&b, "  %s -> %s;\n", edge.From, edge.To)
+		}
+	}
+
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// Mermaid renders the graph as a Mermaid flowchart.
+func (g *Graph) Mermaid() string {
+	var b strings.Builder
+	b.WriteString("flowchart TD\n")
+
+	for i, group := range g.groups() {
+		fmt.Fprintf(&b, "  subgraph group%d[%s]\n", i, mermaidQuote(group))
+		for _, node := range g.Nodes {
+			if node.Group == group {
+				fmt.Fprintf(&b, "    %s[%s]\n", node.ID, mermaidQuote(strings.Join(node.Label, "<br/>")))
+			}
+		}
+		b.WriteString("  end\n")
+	}
+
+	for _, edge := range g.Edges {
+		switch edge.Kind {
+		case EdgeOrder:
+			fmt.Fprintf(&b, "  %s -.-> %s\n", edge.From, edge.To)
+		case EdgeDependsOn:
+			fmt.Fprintf(&b, "  %s ==> %s\n", edge.From, edge.To)
+		case EdgeRollout:
+			fmt.Fprintf(&b, "  %s -- rollout --> %s\n", edge.From, edge.To)
+		default:
+			fmt.Fprintf(&b, "  %s --> %s\n", edge.From, edge.To)
+		}
+	}
+
+	return b.String()
+}
+
+func operationLabel(desc Description) []string {
+	label := []string{strings.TrimSpace(fmt.Sprintf("%s %s", desc.Operation, desc.ResourceType))}
+
+	if desc.Stack != nil {
+		stack := desc.Stack.Path
+		if desc.Stack.Name != "" {
+			stack = desc.Stack.Name
+		}
+		if desc.Stack.Region == "all" {
+			// Stacks of accounts that don't exist yet aren't expanded to
+			// the enabled regions.
+			stack = fmt.Sprintf("%s (all regions)", stack)
+		} else if desc.Stack.Region != "" {
+			stack = fmt.Sprintf("%s (%s)", stack, desc.Stack.Region)
+		}
+		label = append(label, stack)
+	}
+
+	label = append(label, accountLabel(desc))
+
+	if desc.NewName != "" {
+		label = append(label, fmt.Sprintf("Name: %s -> %s", desc.ResourceName, desc.NewName))
+	}
+	if desc.CurrentParent != nil && desc.NewParent != nil {
+		label = append(label, fmt.Sprintf("Parent: %s -> %s", desc.CurrentParent.Name, desc.NewParent.Name))
+	} else if desc.NewParent != nil {
+		label = append(label, fmt.Sprintf("Parent: %s", desc.NewParent.Name))
+	}
+	for _, tag := range desc.TagsAdded {
+		label = append(label, "+ "+tag)
+	}
+	for _, tag := range desc.TagsRemoved {
+		label = append(label, "- "+tag)
+	}
+	if desc.DelegateAdminPrincipal != "" {
+		label = append(label, "+ "+desc.DelegateAdminPrincipal)
+	}
+	for _, target := range desc.TargetsAttached {
+		label = append(label, "+ "+target)
+	}
+	for _, target := range desc.TargetsDetached {
+		label = append(label, "- "+target)
+	}
+
+	return label
+}
+
+func accountLabel(desc Description) string {
+	if desc.ResourceID == "" {
+		return desc.ResourceName
+	}
+	return fmt.Sprintf("%s (%s)", desc.ResourceName, desc.ResourceID)
+}
+
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
+
+func mermaidQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "#quot;") + `"`
+}
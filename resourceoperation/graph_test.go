@@ -0,0 +1,156 @@
+package resourceoperation
+
+import (
+	"testing"
+
+	"github.com/santiago-labs/telophasecli/lib/awsorgs"
+	"github.com/santiago-labs/telophasecli/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGraph(t *testing.T) {
+	rootID := "r-root"
+	root := &resource.OrganizationUnit{OUName: "root", OUID: &rootID}
+	newOU := &resource.OrganizationUnit{OUName: "Dev", Parent: root}
+	newAcct := &resource.Account{AccountName: "dev", Email: "dev@example.com", Parent: newOU}
+	existingAcct := &resource.Account{AccountID: "111111111111", AccountName: "prod", Email: "prod@example.com", Parent: root}
+
+	createOU := NewOrganizationUnitOperation(awsorgs.Client{}, nil, newOU, nil, Create, root, nil, nil, nil)
+	createOU.AddDependent(NewAccountOperation(awsorgs.Client{}, nil, newAcct, nil, Create, newOU, nil, nil))
+	tagAcct := NewAccountOperation(awsorgs.Client{}, nil, existingAcct, nil, UpdateTags, root, root, &TagsDiff{Added: []string{"env=prod"}})
+
+	graph, err := NewGraph(
+		[]ResourceOperation{createOU, tagAcct},
+		[]AccountStacks{
+			{
+				Account: *newAcct,
+				Stacks: []resource.Stack{
+					{Type: "Terraform", Path: "./tf/baseline"},
+					{Type: "CDK", Path: "./cdk/app", Name: "app"},
+				},
+			},
+			{
+				Account: *existingAcct,
+				Stacks:  []resource.Stack{{Type: "Terraform", Path: "./tf/baseline", Region: "us-west-2"}},
+			},
+		},
+		nil,
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, []GraphEdge{
+		{From: "op0", To: "op1", Kind: EdgeDependency},
+		{From: "op0", To: "op2", Kind: EdgeOrder},
+		{From: "op2", To: "op3", Kind: EdgeOrder},
+		{From: "op3", To: "op4", Kind: EdgeOrder},
+		{From: "op2", To: "op5", Kind: EdgeOrder},
+	}, graph.Edges)
+
+	assert.Equal(t, `digraph telophase {
+  rankdir=TB;
+  node [shape=box];
+  subgraph cluster_0 {
+    label="AWS Organization";
+    op0 [label="Create Organization Unit\nDev\nParent: root"];
+    op1 [label="Create Account\ndev\nParent: Dev\n+ AccountName=dev"];
+    op2 [label="UpdateTags Account\nprod (111111111111)\n+ env=prod"];
+  }
+  subgraph cluster_1 {
+    label="Stacks: dev";
+    op3 [label="Deploy Terraform\n./tf/baseline\ndev"];
+    op4 [label="Deploy CDK\napp\ndev"];
+  }
+  subgraph cluster_2 {
+    label="Stacks: prod (111111111111)";
+    op5 [label="Deploy Terraform\n./tf/baseline (us-west-2)\nprod (111111111111)"];
+  }
+  op0 -> op1;
+  op0 -> op2 [style=dashed];
+  op2 -> op3 [style=dashed];
+  op3 -> op4 [style=dashed];
+  op2 -> op5 [style=dashed];
+}
+`, graph.DOT())
+
+	assert.Contains(t, graph.Mermaid(), `    op0["Create Organization Unit<br/>Dev<br/>Parent: root"]`)
+	assert.Contains(t, graph.Mermaid(), "  op2 -.-> op3\n")
+}
+
+func TestGraphDependsOn(t *testing.T) {
+	logArchive := resource.Account{AccountID: "111111111111", AccountName: "log-archive", Email: "logs@example.com"}
+	prod := resource.Account{AccountID: "222222222222", AccountName: "prod", Email: "prod@example.com"}
+	scpAdmin := &resource.Account{AccountID: "333333333333", AccountName: "mgmt", Email: "mgmt@example.com"}
+
+	cloudtrail := resource.Stack{
+		Type:      "Terraform",
+		Path:      "./tf/cloudtrail",
+		DependsOn: []resource.StackDependency{{Stack: "log-bucket", Account: "log-archive"}},
+	}
+	scpOps := []ResourceOperation{
+		NewTFOperation(nil, scpAdmin, resource.Stack{Type: "Terraform", Path: "./tf/scp1"}, Deploy),
+		NewTFOperation(nil, scpAdmin, resource.Stack{Type: "Terraform", Path: "./tf/scp2"}, Deploy),
+	}
+
+	graph, err := NewGraph(
+		nil,
+		[]AccountStacks{
+			{
+				Account: prod,
+				Stacks:  []resource.Stack{cloudtrail, {Type: "Terraform", Path: "./tf/alarms"}},
+			},
+			{
+				Account: logArchive,
+				Stacks:  []resource.Stack{{Type: "Terraform", Path: "./tf/bucket", Name: "log-bucket"}},
+			},
+		},
+		scpOps,
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, []GraphEdge{
+		{From: "op2", To: "op0", Kind: EdgeDependsOn},
+		{From: "op0", To: "op1", Kind: EdgeOrder},
+		{From: "op1", To: "op3", Kind: EdgeOrder},
+		{From: "op1", To: "op4", Kind: EdgeOrder},
+	}, graph.Edges)
+	assert.Contains(t, graph.DOT(), "  op2 -> op0 [style=bold];\n")
+	assert.Contains(t, graph.Mermaid(), "  op2 ==> op0\n")
+
+	_, err = NewGraph(nil, []AccountStacks{{
+		Account: prod,
+		Stacks: []resource.Stack{
+			{Type: "Terraform", Path: "./tf/first", Name: "first", DependsOn: []resource.StackDependency{{Stack: "second"}}},
+			{Type: "Terraform", Path: "./tf/second", Name: "second"},
+		},
+	}}, nil)
+	assert.Error(t, err)
+}
+
+func TestGraphRollout(t *testing.T) {
+	canary := resource.Account{AccountID: "111111111111", AccountName: "canary", Email: "canary@example.com", Tags: []string{"canary"}}
+	prod := resource.Account{AccountID: "222222222222", AccountName: "prod", Email: "prod@example.com"}
+	newAcct := resource.Account{AccountName: "dev", Email: "dev@example.com"}
+
+	rollout := &resource.Rollout{Waves: []string{"canary"}}
+	stack := resource.Stack{Type: "Terraform", Path: "./tf/baseline", Rollout: rollout}
+	allRegions := resource.Stack{Type: "Terraform", Path: "./tf/config", Region: "all"}
+
+	graph, err := NewGraph(
+		nil,
+		[]AccountStacks{
+			{Account: canary, Stacks: []resource.Stack{stack}},
+			{Account: prod, Stacks: []resource.Stack{stack}},
+			{Account: newAcct, Stacks: []resource.Stack{allRegions}},
+		},
+		nil,
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, []GraphEdge{
+		{From: "op0", To: "op1", Kind: EdgeRollout},
+	}, graph.Edges)
+	assert.Contains(t, graph.DOT(), "  op0 -> op1 [style=dotted];\n")
+	assert.Contains(t, graph.DOT(), `op2 [label="Deploy Terraform\n./tf/config (all regions)\ndev"];`)
+	assert.Contains(t, graph.Mermaid(), "  op0 -- rollout --> op1\n")
+}
@@ -16,11 +16,16 @@ const (
 	// IaC
 	Diff   = 4
 	Deploy = 5
+
+	// IaC teardown. DiffDestroy previews what Destroy removes.
+	DiffDestroy = 9
+	Destroy     = 10
 )
 
 type ResourceOperation interface {
 	Call(context.Context) error
 	ToString() string
+	Describe() Description
 	AddDependent(ResourceOperation)
 	ListDependents() []ResourceOperation
 }
@@ -0,0 +1,279 @@
+package resourceoperation
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/samsarahq/go/oops"
+	"github.com/santiago-labs/telophasecli/lib/outputs"
+	"github.com/santiago-labs/telophasecli/resource"
+)
+
+// JournalDir is where the journals of deploys are written. Each run has a
+// directory named after its run ID.
+var JournalDir = filepath.Join("telophasedirs", "runs")
+
+const (
+	journalSucceeded = "succeeded"
+	journalFailed    = "failed"
+)
+
+// Journal records the result of every stack operation of a deploy so that
+// `telophasecli deploy --resume` can skip the operations that already
+// succeeded. Entries are appended as JSON lines as operations finish so the
+// journal survives a crash. It is safe to use concurrently.
+type Journal struct {
+	RunID string
+
+	mu      sync.Mutex
+	file    *os.File
+	entries map[string]JournalEntry
+	hashes  map[ResourceOperation]string
+}
+
+// JournalEntry is the result of a stack operation.
+type JournalEntry struct {
+	AccountID    string `json:"account_id"`
+	AccountName  string `json:"account_name"`
+	ResourceType string `json:"resource_type"`
+	Stack        string `json:"stack"`
+	Region       string `json:"region,omitempty"`
+	// InputHash is the hash of the stack's configuration and files. An
+	// operation is only skipped when it is unchanged.
+	InputHash string `json:"input_hash"`
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+	// Outputs of the stack, so that stacks that run after a skipped stack can
+	// still reference them.
+	Outputs    map[string]string `json:"outputs,omitempty"`
+	FinishedAt time.Time         `json:"finished_at"`
+}
+
+// NewJournal starts the journal of a new run.
+func NewJournal() (*Journal, error) {
+	id := make([]byte, 3)
+	if _, err := rand.Read(id); err != nil {
+		return nil, oops.Wrapf(err, "generating run ID")
+	}
+	runID := fmt.Sprintf("%s-%s", time.Now().UTC().Format("20060102-150405"), hex.EncodeToString(id))
+
+	if err := os.MkdirAll(filepath.Join(JournalDir, runID), 0755); err != nil {
+		return nil, oops.Wrapf(err, "creating journal directory")
+	}
+	return openJournal(runID)
+}
+
+// ResumeJournal reads the journal of runID. Entries of the resumed run are
+// appended to the same journal.
+func ResumeJournal(runID string) (*Journal, error) {
+	if runID == "" || strings.ContainsAny(runID, `/\`) {
+		return nil, oops.Errorf("invalid run ID: %s", runID)
+	}
+	if _, err := os.Stat(filepath.Join(JournalDir, runID)); err != nil {
+		return nil, oops.Errorf("no run with ID %s in %s", runID, JournalDir)
+	}
+	return openJournal(runID)
+}
+
+func openJournal(runID string) (*Journal, error) {
+	path := filepath.Join(JournalDir, runID, "journal.jsonl")
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, oops.Wrapf(err, "opening journal %s", path)
+	}
+
+	j := &Journal{
+		RunID:   runID,
+		file:    file,
+		entries: map[string]JournalEntry{},
+		hashes:  map[ResourceOperation]string{},
+	}
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		var entry JournalEntry
+		// A line cut short by a crash is ignored.
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		j.entries[entry.key()] = entry
+	}
+	if err := scanner.Err(); err != nil {
+		file.Close()
+		return nil, oops.Wrapf(err, "reading journal %s", path)
+	}
+
+	return j, nil
+}
+
+func (j *Journal) Close() error {
+	if j == nil {
+		return nil
+	}
+	return j.file.Close()
+}
+
+// Succeeded returns whether op succeeded in the run with the same input. If it
+// did, the outputs recorded for the stack are added to store. A nil Journal
+// never skips operations.
+func (j *Journal) Succeeded(op ResourceOperation, store *outputs.Store) (bool, error) {
+	if j == nil {
+		return false, nil
+	}
+
+	entry, err := j.newEntry(op)
+	if err != nil {
+		return false, err
+	}
+
+	j.mu.Lock()
+	previous, ok := j.entries[entry.key()]
+	j.mu.Unlock()
+	if !ok || previous.Status != journalSucceeded || previous.InputHash != entry.InputHash {
+		return false, nil
+	}
+
+	if previous.Outputs != nil && store != nil {
+		desc := op.Describe()
+		storeOutputs(store, resource.Account{AccountID: desc.ResourceID, AccountName: desc.ResourceName}, *desc.Stack, previous.Outputs)
+	}
+	return true, nil
+}
+
+// Record appends the result of op to the journal. The outputs of the stack are
+// read from store.
+func (j *Journal) Record(op ResourceOperation, opErr error, store *outputs.Store) error {
+	if j == nil {
+		return nil
+	}
+
+	entry, err := j.newEntry(op)
+	if err != nil {
+		return err
+	}
+	entry.Status = journalSucceeded
+	if opErr != nil {
+		entry.Status = journalFailed
+		entry.Error = opErr.Error()
+	}
+	if store != nil {
+		name := strings.TrimSpace(strings.Split(op.Describe().Stack.Name, ",")[0])
+		if values, ok := store.Outputs(entry.AccountName, name); ok {
+			entry.Outputs = values
+		}
+	}
+	entry.FinishedAt = time.Now().UTC()
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return oops.Wrapf(err, "marshalling journal entry")
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if _, err := j.file.Write(append(data, '\n')); err != nil {
+		return oops.Wrapf(err, "writing journal")
+	}
+	j.entries[entry.key()] = entry
+	return nil
+}
+
+func (j *Journal) newEntry(op ResourceOperation) (JournalEntry, error) {
+	desc := op.Describe()
+	if desc.Stack == nil {
+		return JournalEntry{}, oops.Errorf("only stack operations are journaled")
+	}
+
+	j.mu.Lock()
+	hash, ok := j.hashes[op]
+	j.mu.Unlock()
+	if !ok {
+		var err error
+		hash, err = inputHash(desc)
+		if err != nil {
+			return JournalEntry{}, err
+		}
+		// The input is hashed once so that files changed while the stack
+		// runs, e.g. by terraform init, don't change it.
+		j.mu.Lock()
+		j.hashes[op] = hash
+		j.mu.Unlock()
+	}
+
+	stack := desc.Stack.Name
+	if stack == "" {
+		stack = desc.Stack.Path
+	}
+	return JournalEntry{
+		AccountID:    desc.ResourceID,
+		AccountName:  desc.ResourceName,
+		ResourceType: desc.ResourceType,
+		Stack:        stack,
+		Region:       desc.Stack.Region,
+		InputHash:    hash,
+	}, nil
+}
+
+func (e JournalEntry) key() string {
+	return strings.Join([]string{e.AccountID, e.ResourceType, e.Stack, e.Region}, "|")
+}
+
+// inputHash hashes the account, the stack's configuration and the files in the
+// stack's Path. Generated and downloaded directories are left out.
+func inputHash(desc Description) (string, error) {
+	h := sha256.New()
+
+	config, err := json.Marshal(struct {
+		AccountID string
+		Stack     interface{}
+	}{desc.ResourceID, desc.Stack})
+	if err != nil {
+		return "", oops.Wrapf(err, "marshalling stack")
+	}
+	h.Write(config)
+
+	err = filepath.WalkDir(desc.Stack.Path, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			switch d.Name() {
+			case "telophasedirs", ".terraform", "cdk.out", "node_modules", ".git":
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		fmt.Fprintf(h, "\n%s\n", filepath.ToSlash(path))
+		_, err = io.Copy(h, f)
+		return err
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return "", oops.Wrapf(err, "hashing stack %s", desc.Stack.Path)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,61 @@
+package resourceoperation
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/santiago-labs/telophasecli/lib/outputs"
+	"github.com/santiago-labs/telophasecli/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJournalResume(t *testing.T) {
+	JournalDir = t.TempDir()
+	stackDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(stackDir, "main.tf"), []byte(`resource "aws_s3_bucket" "b" {}`), 0644))
+
+	acct := &resource.Account{AccountID: "111111111111", AccountName: "dev"}
+	newOps := func() []ResourceOperation {
+		return []ResourceOperation{
+			NewTFOperation(nil, acct, resource.Stack{Name: "network", Type: "Terraform", Path: stackDir}, Deploy),
+			NewTFOperation(nil, acct, resource.Stack{Name: "app", Type: "Terraform", Path: stackDir, Region: "us-west-2"}, Deploy),
+		}
+	}
+
+	journal, err := NewJournal()
+	require.NoError(t, err)
+	ops := newOps()
+	store := outputs.NewStore()
+	store.Set("dev", "network", "", map[string]string{"vpc_id": "vpc-1234"})
+	require.NoError(t, journal.Record(ops[0], nil, store))
+	require.NoError(t, journal.Record(ops[1], errors.New("apply failed"), store))
+	require.NoError(t, journal.Close())
+
+	resumed, err := ResumeJournal(journal.RunID)
+	require.NoError(t, err)
+	defer resumed.Close()
+
+	ops = newOps()
+	store = outputs.NewStore()
+	ok, err := resumed.Succeeded(ops[0], store)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	value, _ := store.Get(outputs.Reference{Account: "dev", Stack: "network", Key: "vpc_id"})
+	assert.Equal(t, "vpc-1234", value)
+
+	ok, err = resumed.Succeeded(ops[1], store)
+	require.NoError(t, err)
+	assert.False(t, ok, "failed operations run again")
+
+	// Changing the stack's files changes its input.
+	require.NoError(t, os.WriteFile(filepath.Join(stackDir, "main.tf"), []byte(`resource "aws_s3_bucket" "c" {}`), 0644))
+	ok, err = resumed.Succeeded(newOps()[0], store)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = ResumeJournal("does-not-exist")
+	assert.Error(t, err)
+}
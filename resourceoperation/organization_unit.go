@@ -8,6 +8,7 @@ import (
 	"strings"
 	"text/template"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/fatih/color"
 	"github.com/santiago-labs/telophasecli/cmd/runner"
 	"github.com/santiago-labs/telophasecli/lib/awsorgs"
@@ -66,6 +67,7 @@ func CollectOrganizationUnitOps(
 	rootOU *resource.OrganizationUnit,
 	op int,
 	allowDelete bool,
+	pruneOUs bool,
 ) []ResourceOperation {
 
 	// Order of operations matters. Groups must be Created first, followed by account creation,
@@ -83,6 +85,8 @@ func CollectOrganizationUnitOps(
 		consoleUI.Print(fmt.Sprintf("Failed to fetch delegated admins, continuing anyway, error: %v", err), *mgmtAcct)
 	}
 
+	// Renames run first so that a new OU can take the old name of an OU.
+	var renames []ResourceOperation
 	providerOUs := providerRootOU.AllDescendentOUs()
 	for _, parsedOU := range rootOU.AllDescendentOUs() {
 		var found bool
@@ -125,6 +129,20 @@ func CollectOrganizationUnitOps(
 							nil,
 						),
 					)
+				} else if parsedOU.OUName != providerOU.OUName {
+					// Moving an OU recreates it with the new name, so OUs
+					// are only renamed in place when they stay put.
+					renames = append(renames, NewOrganizationUnitOperation(
+						orgClient,
+						consoleUI,
+						providerOU,
+						mgmtAcct,
+						Update,
+						nil,
+						nil,
+						&parsedOU.OUName,
+						nil,
+					))
 				}
 
 				added, removed := diffTags(parsedOU)
@@ -187,6 +205,8 @@ func CollectOrganizationUnitOps(
 		}
 	}
 
+	operations = append(renames, operations...)
+
 	providerAccounts := providerRootOU.AllDescendentAccounts()
 	for _, parsedAcct := range rootOU.AllDescendentAccounts() {
 		var found bool
@@ -317,9 +337,106 @@ func CollectOrganizationUnitOps(
 		}
 	}
 
+	// Deleting OUs comes last so that accounts and OUs that are still in
+	// organization.yml are moved out of them first.
+	if pruneOUs {
+		operations = append(operations, collectPruneOps(consoleUI, orgClient, mgmtAcct, rootOU, &providerRootOU)...)
+	}
+
 	return operations
 }
 
+// collectPruneOps returns operations deleting the OUs in AWS that are not in
+// organization.yml, child OUs before their parents. An OU is only deleted if it
+// will be empty: accounts that are not in organization.yml or that are closed
+// keep it, and so do child OUs that can't be deleted. OUs below an OU that is
+// moved are not pruned because moving recreates them under the new parent.
+func collectPruneOps(
+	consoleUI runner.ConsoleUI,
+	orgClient awsorgs.Client,
+	mgmtAcct *resource.Account,
+	rootOU *resource.OrganizationUnit,
+	providerRootOU *resource.OrganizationUnit,
+) []ResourceOperation {
+	// parsedParentIDs are the IDs of the parents of the OUs in
+	// organization.yml, by OU ID.
+	parsedParentIDs := map[string]string{}
+	for _, parsedOU := range rootOU.AllDescendentOUs() {
+		if parsedOU.OUID != nil {
+			parsedParentIDs[*parsedOU.OUID] = parsedOU.Parent.ID()
+		}
+	}
+	// Closed accounts stay in their OU, so they are not moved out like the
+	// other accounts in organization.yml.
+	parsedEmails := map[string]bool{}
+	for _, parsedAcct := range rootOU.AllDescendentAccounts() {
+		if !parsedAcct.Delete {
+			parsedEmails[parsedAcct.Email] = true
+		}
+	}
+
+	var ops []ResourceOperation
+
+	// prune adds the operations deleting providerOU and its child OUs and
+	// returns whether providerOU is deleted.
+	var prune func(providerOU *resource.OrganizationUnit) bool
+	prune = func(providerOU *resource.OrganizationUnit) bool {
+		var keptOUs []string
+		for _, child := range providerOU.ChildOUs {
+			// Child OUs in organization.yml are moved out.
+			if _, ok := parsedParentIDs[child.ID()]; !ok && !prune(child) {
+				keptOUs = append(keptOUs, child.Name())
+			}
+		}
+
+		var keptAccts []string
+		for _, acct := range providerOU.Accounts {
+			if !parsedEmails[acct.Email] {
+				keptAccts = append(keptAccts, acct.AccountName)
+			}
+		}
+
+		if len(keptAccts) > 0 {
+			consoleUI.Print(fmt.Sprintf("Not deleting Organization Unit %s (%s) because it contains accounts that are closed or not in organization.yml: %s", providerOU.Name(), providerOU.ID(), strings.Join(keptAccts, ", ")), *mgmtAcct)
+			return false
+		}
+		if len(keptOUs) > 0 {
+			consoleUI.Print(fmt.Sprintf("Not deleting Organization Unit %s (%s) because its Organization Units can't be deleted: %s", providerOU.Name(), providerOU.ID(), strings.Join(keptOUs, ", ")), *mgmtAcct)
+			return false
+		}
+
+		ops = append(ops, NewOrganizationUnitOperation(
+			orgClient,
+			consoleUI,
+			providerOU,
+			mgmtAcct,
+			Delete,
+			nil,
+			providerOU.Parent,
+			nil,
+			nil,
+		))
+		return true
+	}
+
+	var walk func(providerOU *resource.OrganizationUnit)
+	walk = func(providerOU *resource.OrganizationUnit) {
+		for _, child := range providerOU.ChildOUs {
+			parentID, ok := parsedParentIDs[child.ID()]
+			if !ok {
+				prune(child)
+			} else if parentID != providerOU.ID() {
+				consoleUI.Print(fmt.Sprintf("Not pruning the Organization Units in %s (%s) because it is moved. Deploy again to prune them.", child.Name(), child.ID()), *mgmtAcct)
+			} else {
+				walk(child)
+			}
+		}
+	}
+	walk(providerRootOU)
+
+	return ops
+}
+
 func (ou *organizationUnitOperation) AddDependent(op ResourceOperation) {
 	ou.DependentOperations = append(ou.DependentOperations, op)
 }
@@ -336,15 +453,28 @@ func (ou *organizationUnitOperation) Call(ctx context.Context) error {
 		}
 		ou.OrganizationUnit.OUID = newOrg.Id
 	} else if ou.Operation == UpdateParent {
-		err := ou.OrgClient.RecreateOU(ctx, ou.ConsoleUI, *ou.MgmtAccount, *ou.OrganizationUnit.OUID, ou.OrganizationUnit.OUName, *ou.OrganizationUnit.Parent.OUID, ou.OrganizationUnit.AllTags())
+		newIDs, err := ou.OrgClient.RecreateOU(ctx, ou.ConsoleUI, *ou.MgmtAccount, *ou.OrganizationUnit.OUID, ou.OrganizationUnit.OUName, *ou.OrganizationUnit.Parent.OUID)
+		if err != nil {
+			return err
+		}
+		// Later operations in the run, like Service Control Policies and
+		// moves of accounts out of the recreated OUs, use the new IDs.
+		replaceOUIDs(ou.OrganizationUnit, newIDs)
+		replaceOUIDs(ou.CurrentParent, newIDs)
+		for _, pinned := range pinnedOUs(ou.OrganizationUnit) {
+			runner.ConsoleUI.Print(ou.ConsoleUI, fmt.Sprintf("Organization Unit %s was recreated with ID %s, set it as its OUID in organization.yml", pinned.OUName, pinned.ID()), *ou.MgmtAccount)
+		}
+	} else if ou.Operation == Delete {
+		err := ou.OrgClient.DeleteOrganizationUnit(ctx, ou.ConsoleUI, *ou.MgmtAccount, *ou.OrganizationUnit.OUID)
 		if err != nil {
 			return err
 		}
 	} else if ou.Operation == Update {
-		err := ou.OrgClient.UpdateOrganizationUnit(ctx, *ou.OrganizationUnit.OUID, ou.OrganizationUnit.OUName)
+		err := ou.OrgClient.UpdateOrganizationUnit(ctx, *ou.OrganizationUnit.OUID, *ou.NewName)
 		if err != nil {
 			return err
 		}
+		runner.ConsoleUI.Print(ou.ConsoleUI, fmt.Sprintf("renamed Organization Unit %s to %s", ou.OrganizationUnit.OUName, *ou.NewName), *ou.MgmtAccount)
 	} else if ou.Operation == UpdateTags {
 		err := ou.OrgClient.TagResource(ctx, *ou.OrganizationUnit.OUID, ou.OrganizationUnit.AllTags())
 		if err != nil {
@@ -367,6 +497,64 @@ func (ou *organizationUnitOperation) Call(ctx context.Context) error {
 	return nil
 }
 
+// pinnedOUs returns ou and its descendents that have an OUID in
+// organization.yml.
+func pinnedOUs(ou *resource.OrganizationUnit) []*resource.OrganizationUnit {
+	var pinned []*resource.OrganizationUnit
+	for _, descendent := range append([]*resource.OrganizationUnit{ou}, ou.AllDescendentOUs()...) {
+		if descendent.PinnedOUID != "" {
+			pinned = append(pinned, descendent)
+		}
+	}
+	return pinned
+}
+
+// replaceOUIDs replaces the IDs of the OUs in the tree of ou that are keys of
+// newIDs.
+func replaceOUIDs(ou *resource.OrganizationUnit, newIDs map[string]string) {
+	if ou == nil {
+		return
+	}
+	for ou.Parent != nil {
+		ou = ou.Parent
+	}
+
+	for _, descendent := range append([]*resource.OrganizationUnit{ou}, ou.AllDescendentOUs()...) {
+		if newID, ok := newIDs[descendent.ID()]; ok {
+			descendent.OUID = aws.String(newID)
+		}
+	}
+}
+
+func (ou *organizationUnitOperation) Describe() Description {
+	desc := Description{
+		Operation:    OperationName(ou.Operation),
+		ResourceType: ou.OrganizationUnit.Type(),
+		ResourceID:   ou.OrganizationUnit.ID(),
+		ResourceName: ou.OrganizationUnit.Name(),
+	}
+	if ou.NewName != nil {
+		desc.NewName = *ou.NewName
+	}
+	// Parents are only set on the operations that change them.
+	if ou.Operation == Create || ou.Operation == UpdateParent {
+		desc.CurrentParent = parentDescription(ou.CurrentParent)
+		desc.NewParent = parentDescription(ou.NewParent)
+	}
+	if ou.Operation == Delete {
+		desc.CurrentParent = parentDescription(ou.CurrentParent)
+	}
+	desc.Recreate = ou.Operation == UpdateParent
+	if ou.Operation == Create {
+		desc.TagsAdded = ou.OrganizationUnit.AllTags()
+	}
+	if ou.TagsDiff != nil {
+		desc.TagsAdded = ou.TagsDiff.Added
+		desc.TagsRemoved = ou.TagsDiff.Removed
+	}
+	return desc
+}
+
 func (ou *organizationUnitOperation) ToString() string {
 	printColor := "yellow"
 	var templated string
@@ -386,10 +574,28 @@ func (ou *organizationUnitOperation) ToString() string {
 
 	} else if ou.Operation == UpdateParent {
 		templated = "\n" + `(Update Organizational Unit Parent)
-ID: {{ .OrganizationUnit.ID }}
+~	ID: {{ .OrganizationUnit.ID }} -> <computed>
 Name: {{ .OrganizationUnit.Name }}
 ~	Parent ID: {{ .CurrentParent.ID }} -> {{ if .NewParent.ID }}{{ .NewParent.ID }}{{else}}<computed>{{end}}
 ~	Parent Name: {{ .CurrentParent.Name }} -> {{ .NewParent.Name }}
+AWS can't move Organizational Units, so it is recreated under the new parent with its tags and policies.
+Its accounts are moved to it, its child Organizational Units are recreated in it with new IDs, and the old one is deleted.
+`
+		var pinnedNames []string
+		for _, pinned := range pinnedOUs(ou.OrganizationUnit) {
+			pinnedNames = append(pinnedNames, pinned.OUName)
+		}
+		if len(pinnedNames) > 0 {
+			templated += fmt.Sprintf("The OUID of %s in organization.yml must be set to the new ID after the deploy.\n", strings.Join(pinnedNames, ", "))
+		}
+		templated += "\n"
+	} else if ou.Operation == Delete {
+		printColor = "red"
+		templated = "\n" + `(Delete Organizational Unit)
+-	ID: {{ .OrganizationUnit.ID }}
+-	Name: {{ .OrganizationUnit.Name }}
+-	Parent ID: {{ .CurrentParent.ID }}
+-	Parent Name: {{ .CurrentParent.Name }}
 
 `
 	} else if ou.Operation == Update {
@@ -461,7 +667,7 @@ type Taggable interface {
 func diffTags(taggable Taggable) (added, removed []string) {
 	oldMap := make(map[string]struct{})
 	for _, tag := range taggable.AllAWSTags() {
-		if ignorableTag(tag) {
+		if resource.IgnorableTag(tag) {
 			continue
 		}
 		oldMap[tag] = struct{}{}
@@ -474,7 +680,7 @@ func diffTags(taggable Taggable) (added, removed []string) {
 
 	for _, tag := range taggable.AllTags() {
 		if _, ok := oldMap[tag]; !ok {
-			if ignorableTag(tag) {
+			if resource.IgnorableTag(tag) {
 				continue
 			}
 
@@ -489,7 +695,7 @@ func diffTags(taggable Taggable) (added, removed []string) {
 	}
 
 	for _, tag := range taggable.AllAWSTags() {
-		if ignorableTag(tag) {
+		if resource.IgnorableTag(tag) {
 			continue
 		}
 		if _, ok := taggableMap[tag]; !ok {
@@ -515,24 +721,6 @@ func contains(slc []string, check string) bool {
 	return false
 }
 
-func ignorableTag(tag string) bool {
-	ignorableTags := map[string]struct{}{
-		"TelophaseManaged=true": {},
-	}
-	ignorableKeys := map[string]struct{}{
-		"AccountName": {},
-	}
-
-	_, ok := ignorableTags[tag]
-	if ok {
-		return true
-	}
-	if _, ok := ignorableKeys[strings.Split(tag, "=")[0]]; ok {
-		return true
-	}
-	return false
-}
-
 func oneOf(check string, slc []string) bool {
 	for _, s := range slc {
 		if s == check {
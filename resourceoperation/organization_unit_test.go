@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/santiago-labs/telophasecli/cmd/runner"
+	"github.com/santiago-labs/telophasecli/lib/awsorgs"
 	"github.com/santiago-labs/telophasecli/resource"
 	"github.com/stretchr/testify/assert"
 )
@@ -70,3 +73,93 @@ func TestDiffTags(t *testing.T) {
 		assert.Equal(t, tc.wantRemoved, removed, "removed: "+tc.description)
 	}
 }
+
+func TestReplaceOUIDs(t *testing.T) {
+	root := &resource.OrganizationUnit{OUName: "root", OUID: aws.String("r-0000")}
+	moved := &resource.OrganizationUnit{OUName: "Moved", OUID: aws.String("ou-old"), Parent: root}
+	child := &resource.OrganizationUnit{OUName: "Child", OUID: aws.String("ou-oldchild"), Parent: moved}
+	other := &resource.OrganizationUnit{OUName: "Other", OUID: aws.String("ou-other"), Parent: root}
+	created := &resource.OrganizationUnit{OUName: "Created", Parent: root}
+	root.ChildOUs = []*resource.OrganizationUnit{moved, other, created}
+	moved.ChildOUs = []*resource.OrganizationUnit{child}
+
+	replaceOUIDs(child, map[string]string{"ou-old": "ou-new", "ou-oldchild": "ou-newchild"})
+
+	assert.Equal(t, "r-0000", root.ID())
+	assert.Equal(t, "ou-new", moved.ID())
+	assert.Equal(t, "ou-newchild", child.ID())
+	assert.Equal(t, "ou-other", other.ID())
+	assert.Nil(t, created.OUID)
+}
+
+func TestMovePinnedOUToString(t *testing.T) {
+	currentParent := &resource.OrganizationUnit{OUName: "Current", OUID: aws.String("ou-current")}
+	newParent := &resource.OrganizationUnit{OUName: "New", OUID: aws.String("ou-new")}
+	moved := &resource.OrganizationUnit{OUName: "Moved", OUID: aws.String("ou-moved"), Parent: newParent}
+	pinned := &resource.OrganizationUnit{OUName: "Pinned", OUID: aws.String("ou-pinned"), PinnedOUID: "ou-pinned", Parent: moved}
+	moved.ChildOUs = []*resource.OrganizationUnit{pinned}
+
+	op := NewOrganizationUnitOperation(awsorgs.Client{}, nil, moved, nil, UpdateParent, newParent, currentParent, nil, nil)
+	assert.Contains(t, op.ToString(), "The OUID of Pinned in organization.yml must be set to the new ID after the deploy.")
+
+	pinned.PinnedOUID = ""
+	assert.NotContains(t, op.ToString(), "must be set to the new ID")
+}
+
+func TestCollectPruneOps(t *testing.T) {
+	// In AWS: root has Kept, which is in organization.yml, Empty with the
+	// child EmptyChild, Moved with an account that is moved to Kept, and
+	// Orphans with an account that is not in organization.yml.
+	providerRoot := &resource.OrganizationUnit{OUName: "root", OUID: aws.String("r-0000")}
+	kept := &resource.OrganizationUnit{OUName: "Kept", OUID: aws.String("ou-kept"), Parent: providerRoot}
+	empty := &resource.OrganizationUnit{OUName: "Empty", OUID: aws.String("ou-empty"), Parent: providerRoot}
+	emptyChild := &resource.OrganizationUnit{OUName: "EmptyChild", OUID: aws.String("ou-emptychild"), Parent: empty}
+	moved := &resource.OrganizationUnit{OUName: "Moved", OUID: aws.String("ou-moved"), Parent: providerRoot}
+	orphans := &resource.OrganizationUnit{OUName: "Orphans", OUID: aws.String("ou-orphans"), Parent: providerRoot}
+	orphansChild := &resource.OrganizationUnit{OUName: "OrphansChild", OUID: aws.String("ou-orphanschild"), Parent: orphans}
+	providerRoot.ChildOUs = []*resource.OrganizationUnit{kept, empty, moved, orphans}
+	empty.ChildOUs = []*resource.OrganizationUnit{emptyChild}
+	orphans.ChildOUs = []*resource.OrganizationUnit{orphansChild}
+	moved.Accounts = []*resource.Account{{AccountName: "dev", Email: "dev@example.com", Parent: moved}}
+	orphans.Accounts = []*resource.Account{{AccountName: "legacy", Email: "legacy@example.com", Parent: orphans}}
+
+	parsedRoot := &resource.OrganizationUnit{OUName: "root", OUID: aws.String("r-0000")}
+	parsedKept := &resource.OrganizationUnit{OUName: "Kept", OUID: aws.String("ou-kept"), Parent: parsedRoot}
+	parsedKept.Accounts = []*resource.Account{{AccountName: "dev", Email: "dev@example.com", Parent: parsedKept}}
+	parsedRoot.ChildOUs = []*resource.OrganizationUnit{parsedKept}
+
+	ops := collectPruneOps(runner.NewSTDErr(), awsorgs.Client{}, &resource.Account{}, parsedRoot, providerRoot)
+
+	var deleted []string
+	for _, op := range ops {
+		desc := op.Describe()
+		assert.Equal(t, "Delete", desc.Operation)
+		deleted = append(deleted, desc.ResourceName)
+	}
+	assert.Equal(t, []string{"EmptyChild", "Empty", "Moved", "OrphansChild"}, deleted)
+}
+
+func TestCollectPruneOpsMovedOU(t *testing.T) {
+	// In AWS: root has Source with the child Stale that is not in
+	// organization.yml, Target, and Closed with a closed account.
+	providerRoot := &resource.OrganizationUnit{OUName: "root", OUID: aws.String("r-0000")}
+	source := &resource.OrganizationUnit{OUName: "Source", OUID: aws.String("ou-source"), Parent: providerRoot}
+	stale := &resource.OrganizationUnit{OUName: "Stale", OUID: aws.String("ou-stale"), Parent: source}
+	target := &resource.OrganizationUnit{OUName: "Target", OUID: aws.String("ou-target"), Parent: providerRoot}
+	closed := &resource.OrganizationUnit{OUName: "Closed", OUID: aws.String("ou-closed"), Parent: providerRoot}
+	providerRoot.ChildOUs = []*resource.OrganizationUnit{source, target, closed}
+	source.ChildOUs = []*resource.OrganizationUnit{stale}
+	closed.Accounts = []*resource.Account{{AccountName: "old", Email: "old@example.com", Parent: closed}}
+
+	// In organization.yml Source is moved into Target and the account in
+	// Closed is closed.
+	parsedRoot := &resource.OrganizationUnit{OUName: "root", OUID: aws.String("r-0000")}
+	parsedTarget := &resource.OrganizationUnit{OUName: "Target", OUID: aws.String("ou-target"), Parent: parsedRoot}
+	parsedSource := &resource.OrganizationUnit{OUName: "Source", OUID: aws.String("ou-source"), Parent: parsedTarget}
+	parsedTarget.ChildOUs = []*resource.OrganizationUnit{parsedSource}
+	parsedTarget.Accounts = []*resource.Account{{AccountName: "old", Email: "old@example.com", Delete: true, Parent: parsedTarget}}
+	parsedRoot.ChildOUs = []*resource.OrganizationUnit{parsedTarget}
+
+	ops := collectPruneOps(runner.NewSTDErr(), awsorgs.Client{}, &resource.Account{}, parsedRoot, providerRoot)
+	assert.Empty(t, ops)
+}
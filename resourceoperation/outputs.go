@@ -0,0 +1,33 @@
+package resourceoperation
+
+import (
+	"strings"
+
+	"github.com/santiago-labs/telophasecli/lib/outputs"
+	"github.com/santiago-labs/telophasecli/resource"
+)
+
+// outputOperation is an operation that resolves output references and records
+// the outputs of its stack.
+type outputOperation interface {
+	setOutputs(store *outputs.Store)
+}
+
+// SetOutputs tells every stack operation to resolve output references and
+// record its outputs in store.
+func SetOutputs(ops []ResourceOperation, store *outputs.Store) {
+	for _, op := range FlattenOperations(ops) {
+		if o, ok := op.(outputOperation); ok {
+			o.setOutputs(store)
+		}
+	}
+}
+
+// storeOutputs records values under every name of the stack.
+func storeOutputs(store *outputs.Store, acct resource.Account, stack resource.Stack, values map[string]string) {
+	for _, name := range strings.Split(stack.Name, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			store.Set(acct.AccountName, name, stack.Region, values)
+		}
+	}
+}
@@ -0,0 +1,324 @@
+package resourceoperation
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/samsarahq/go/oops"
+	"github.com/santiago-labs/telophasecli/lib/awsorgs"
+	"github.com/santiago-labs/telophasecli/resource"
+)
+
+const planVersion = 1
+
+// Plan is the artifact written by `telophasecli diff --out`. It records every
+// operation a deploy would perform so that `telophasecli deploy --plan` applies
+// exactly what was reviewed.
+type Plan struct {
+	Version            int       `json:"version"`
+	CreatedAt          time.Time `json:"created_at"`
+	OrgFingerprint     string    `json:"org_fingerprint"`
+	Targets            []string  `json:"targets,omitempty"`
+	Tag                string    `json:"tag,omitempty"`
+	Stacks             string    `json:"stacks,omitempty"`
+	Accounts           string    `json:"accounts,omitempty"`
+	AllowAccountDelete bool      `json:"allow_account_delete,omitempty"`
+	PruneOUs           bool      `json:"prune_ous,omitempty"`
+
+	Organization           []PlanOperation `json:"organization"`
+	StackOperations        []PlanOperation `json:"stack_operations"`
+	ServiceControlPolicies []PlanOperation `json:"service_control_policies"`
+
+	path string
+}
+
+// PlanOperation is the serialized form of a ResourceOperation.
+type PlanOperation struct {
+	Description
+
+	Artifacts  *PlanArtifacts  `json:"artifacts,omitempty"`
+	Dependents []PlanOperation `json:"dependents,omitempty"`
+}
+
+// PlanArtifacts are the outputs of a diff that deploy replays. Paths are
+// relative to the plan's artifact directory when written to disk.
+type PlanArtifacts struct {
+	TerraformPlanFile string `json:"terraform_plan_file,omitempty"`
+	CloudAssemblyDir  string `json:"cloud_assembly_dir,omitempty"`
+	ChangeSetID       string `json:"change_set_id,omitempty"`
+}
+
+// plannable is implemented by operations that save artifacts during a diff so
+// that a deploy can replay them.
+type plannable interface {
+	setPlanDir(string)
+	planArtifacts() *PlanArtifacts
+	setPlanArtifacts(*PlanArtifacts)
+}
+
+func NewPlan(path string, targets []string, tag, stacks, accounts string, allowAccountDelete, pruneOUs bool) *Plan {
+	return &Plan{
+		Version:            planVersion,
+		CreatedAt:          time.Now().UTC(),
+		Targets:            targets,
+		Tag:                tag,
+		Stacks:             stacks,
+		Accounts:           accounts,
+		AllowAccountDelete: allowAccountDelete,
+		PruneOUs:           pruneOUs,
+		path:               path,
+	}
+}
+
+func ReadPlan(path string) (*Plan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, oops.Wrapf(err, "reading plan %s", path)
+	}
+
+	var plan Plan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, oops.Wrapf(err, "parsing plan %s", path)
+	}
+	if plan.Version != planVersion {
+		return nil, oops.Errorf("plan %s has version %d, this version of telophasecli reads version %d", path, plan.Version, planVersion)
+	}
+	plan.path = path
+
+	return &plan, nil
+}
+
+// ArtifactDir is where Terraform plan files and CDK cloud assemblies are saved
+// alongside the plan file.
+func (p *Plan) ArtifactDir() string {
+	abs, err := filepath.Abs(p.path)
+	if err != nil {
+		abs = p.path
+	}
+	return strings.TrimSuffix(abs, filepath.Ext(abs)) + "-artifacts"
+}
+
+// SetPlanDir tells every operation that supports plans to save its artifacts
+// in dir.
+func SetPlanDir(ops []ResourceOperation, dir string) {
+	for _, op := range FlattenOperations(ops) {
+		if p, ok := op.(plannable); ok {
+			p.setPlanDir(dir)
+		}
+	}
+}
+
+func (p *Plan) Write(orgOps, stackOps, scpOps []ResourceOperation) error {
+	dir := p.ArtifactDir()
+	p.Organization = planOperations(orgOps, dir)
+	p.StackOperations = planOperations(stackOps, dir)
+	p.ServiceControlPolicies = planOperations(scpOps, dir)
+
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return oops.Wrapf(err, "marshalling plan")
+	}
+
+	if err := os.WriteFile(p.path, data, 0644); err != nil {
+		return oops.Wrapf(err, "writing plan %s", p.path)
+	}
+
+	return nil
+}
+
+// Match checks that the operations collected for a deploy are exactly the
+// operations in the plan and attaches the saved artifacts so that the
+// operations replay the plan instead of recomputing it.
+func (p *Plan) Match(orgOps, stackOps, scpOps []ResourceOperation) error {
+	dir := p.ArtifactDir()
+	if err := matchOperations("organization", p.Organization, orgOps, dir); err != nil {
+		return err
+	}
+	if err := matchOperations("stack", p.StackOperations, stackOps, dir); err != nil {
+		return err
+	}
+	if err := matchOperations("service control policy", p.ServiceControlPolicies, scpOps, dir); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func matchOperations(kind string, planned []PlanOperation, ops []ResourceOperation, dir string) error {
+	current := planOperations(ops, dir)
+	if len(planned) != len(current) {
+		return oops.Errorf("plan has %d %s operations but %d are needed now", len(planned), kind, len(current))
+	}
+
+	for i := range planned {
+		want, err := comparableJSON(planned[i])
+		if err != nil {
+			return err
+		}
+		got, err := comparableJSON(current[i])
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(want, got) {
+			return oops.Errorf("planned %s operation does not match the current state.\nplanned: %s\ncurrent: %s", kind, want, got)
+		}
+	}
+
+	for i, op := range ops {
+		attachArtifacts(op, planned[i], dir)
+	}
+
+	return nil
+}
+
+func attachArtifacts(op ResourceOperation, planned PlanOperation, dir string) {
+	if p, ok := op.(plannable); ok {
+		artifacts := PlanArtifacts{}
+		if planned.Artifacts != nil {
+			artifacts = *planned.Artifacts
+		}
+		if artifacts.TerraformPlanFile != "" {
+			artifacts.TerraformPlanFile = filepath.Join(dir, artifacts.TerraformPlanFile)
+		}
+		if artifacts.CloudAssemblyDir != "" {
+			artifacts.CloudAssemblyDir = filepath.Join(dir, artifacts.CloudAssemblyDir)
+		}
+		p.setPlanArtifacts(&artifacts)
+	}
+
+	for i, dependent := range op.ListDependents() {
+		attachArtifacts(dependent, planned.Dependents[i], dir)
+	}
+}
+
+// comparableJSON encodes an operation without the artifacts, which are only
+// known after the diff has run.
+func comparableJSON(op PlanOperation) ([]byte, error) {
+	stripped := stripArtifacts(op)
+	data, err := json.Marshal(stripped)
+	if err != nil {
+		return nil, oops.Wrapf(err, "marshalling plan operation")
+	}
+	return data, nil
+}
+
+func stripArtifacts(op PlanOperation) PlanOperation {
+	op.Artifacts = nil
+	var dependents []PlanOperation
+	for _, dependent := range op.Dependents {
+		dependents = append(dependents, stripArtifacts(dependent))
+	}
+	op.Dependents = dependents
+	return op
+}
+
+func planOperations(ops []ResourceOperation, dir string) []PlanOperation {
+	var result []PlanOperation
+	for _, op := range ops {
+		result = append(result, planOperation(op, dir))
+	}
+	return result
+}
+
+func planOperation(op ResourceOperation, dir string) PlanOperation {
+	result := PlanOperation{
+		Description: op.Describe(),
+	}
+	// The operation is omitted for stacks because a plan always describes
+	// what a deploy will do.
+	if result.Stack != nil {
+		result.Operation = ""
+	}
+
+	if p, ok := op.(plannable); ok {
+		if artifacts := p.planArtifacts(); artifacts != nil {
+			result.Artifacts = relativeArtifacts(*artifacts, dir)
+		}
+	}
+
+	result.Dependents = planOperations(op.ListDependents(), dir)
+	return result
+}
+
+func relativeArtifacts(artifacts PlanArtifacts, dir string) *PlanArtifacts {
+	if rel, err := filepath.Rel(dir, artifacts.TerraformPlanFile); err == nil && artifacts.TerraformPlanFile != "" {
+		artifacts.TerraformPlanFile = rel
+	}
+	if rel, err := filepath.Rel(dir, artifacts.CloudAssemblyDir); err == nil && artifacts.CloudAssemblyDir != "" {
+		artifacts.CloudAssemblyDir = rel
+	}
+	return &artifacts
+}
+
+// planArtifactName returns a stable file name for an operation's artifact
+// within the plan directory.
+func planArtifactName(prefix string, acct resource.Resource, stack resource.Stack) string {
+	hasher := sha256.New()
+	hasher.Write([]byte(strings.Join([]string{acct.ID(), stack.Name, stack.Path, stack.Region, stack.Workspace}, "\x00")))
+	return fmt.Sprintf("%s-%s-%s", prefix, acct.ID(), hex.EncodeToString(hasher.Sum(nil))[:16])
+}
+
+// OrgFingerprint hashes the live state of the AWS Organization: every OU and
+// account with its parent, the tags on managed resources and the delegated
+// administrators. A deploy of a plan is refused if the fingerprint changed.
+func OrgFingerprint(
+	ctx context.Context,
+	orgClient awsorgs.Client,
+	rootOU *resource.OrganizationUnit,
+	mgmtAcct *resource.Account,
+) (string, error) {
+	providerRootOU, err := orgClient.FetchOUAndDescendents(ctx, *rootOU.OUID, mgmtAcct.AccountID)
+	if err != nil {
+		return "", oops.Wrapf(err, "FetchOUAndDescendents")
+	}
+
+	delegatedAdmins, err := orgClient.FetchDelegatedAdminPrincipals(ctx)
+	if err != nil {
+		return "", oops.Wrapf(err, "FetchDelegatedAdminPrincipals")
+	}
+
+	var lines []string
+	for _, ou := range providerRootOU.AllDescendentOUs() {
+		lines = append(lines, fmt.Sprintf("ou %s %s parent=%s", ou.ID(), ou.Name(), ou.Parent.ID()))
+	}
+	for _, acct := range providerRootOU.AllDescendentAccounts() {
+		lines = append(lines, fmt.Sprintf("account %s %s %s %s parent=%s", acct.ID(), acct.Email, acct.AccountName, acct.Status, acct.Parent.ID()))
+	}
+
+	taggables := []*resource.OrganizationUnit{rootOU}
+	taggables = append(taggables, rootOU.AllDescendentOUs()...)
+	for _, ou := range taggables {
+		if ou.ID() != "" {
+			lines = append(lines, fmt.Sprintf("tags %s %s", ou.ID(), sortedJoin(ou.AWSTags)))
+		}
+	}
+	for _, acct := range rootOU.AllDescendentAccounts() {
+		if acct.ID() != "" {
+			lines = append(lines, fmt.Sprintf("tags %s %s", acct.ID(), sortedJoin(acct.AWSTags)))
+		}
+	}
+
+	for acctID, services := range delegatedAdmins {
+		lines = append(lines, fmt.Sprintf("delegated %s %s", acctID, sortedJoin(services)))
+	}
+
+	sort.Strings(lines)
+	hasher := sha256.New()
+	hasher.Write([]byte(strings.Join(lines, "\n")))
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+func sortedJoin(slc []string) string {
+	sorted := append([]string{}, slc...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ",")
+}
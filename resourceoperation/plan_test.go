@@ -0,0 +1,91 @@
+package resourceoperation
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/santiago-labs/telophasecli/lib/awsorgs"
+	"github.com/santiago-labs/telophasecli/resource"
+	"github.com/stretchr/testify/assert"
+)
+
+func planTestOps(acct *resource.Account, ou *resource.OrganizationUnit, stackPath string) ([]ResourceOperation, []ResourceOperation) {
+	orgOps := []ResourceOperation{
+		NewOrganizationUnitOperation(awsorgs.Client{}, nil, ou, acct, Create, nil, nil, nil, nil),
+	}
+	stackOps := []ResourceOperation{
+		NewTFOperation(nil, acct, resource.Stack{Type: "Terraform", Path: stackPath}, Deploy),
+	}
+	return orgOps, stackOps
+}
+
+func TestPlanRoundTrip(t *testing.T) {
+	planPath := filepath.Join(t.TempDir(), "plan.json")
+	acct := &resource.Account{AccountID: "111111111111", AccountName: "dev", Email: "dev@example.com"}
+	ou := &resource.OrganizationUnit{OUName: "Dev"}
+
+	plan := NewPlan(planPath, []string{"stacks"}, "dev", "", "dev,111111111111", false, true)
+	plan.OrgFingerprint = "fingerprint"
+
+	orgOps, stackOps := planTestOps(acct, ou, "./tf/dev")
+	SetPlanDir(stackOps, plan.ArtifactDir())
+	planFile, err := stackOps[0].(*tfOperation).planFile()
+	assert.NoError(t, err)
+	stackOps[0].(*tfOperation).setPlanArtifacts(&PlanArtifacts{TerraformPlanFile: planFile})
+	assert.NoError(t, plan.Write(orgOps, stackOps, nil))
+
+	read, err := ReadPlan(planPath)
+	assert.NoError(t, err)
+	assert.Equal(t, "fingerprint", read.OrgFingerprint)
+	assert.Equal(t, []string{"stacks"}, read.Targets)
+	assert.Equal(t, "dev,111111111111", read.Accounts)
+	assert.Equal(t, "dev", read.Tag)
+	assert.True(t, read.PruneOUs)
+	assert.Equal(t, filepath.Base(planFile), read.StackOperations[0].Artifacts.TerraformPlanFile)
+
+	orgOps, stackOps = planTestOps(acct, ou, "./tf/dev")
+	assert.NoError(t, read.Match(orgOps, stackOps, nil))
+	assert.Equal(t, planFile, stackOps[0].(*tfOperation).Artifacts.TerraformPlanFile)
+}
+
+func TestPlanMatchRefusesChanges(t *testing.T) {
+	planPath := filepath.Join(t.TempDir(), "plan.json")
+	acct := &resource.Account{AccountID: "111111111111", AccountName: "dev", Email: "dev@example.com"}
+	ou := &resource.OrganizationUnit{OUName: "Dev"}
+
+	plan := NewPlan(planPath, nil, "", "", "", false, false)
+	orgOps, stackOps := planTestOps(acct, ou, "./tf/dev")
+	assert.NoError(t, plan.Write(orgOps, stackOps, nil))
+
+	read, err := ReadPlan(planPath)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		description string
+		orgOps      []ResourceOperation
+		stackOps    []ResourceOperation
+	}{
+		{
+			description: "stack path changed",
+			orgOps:      orgOps,
+			stackOps: []ResourceOperation{
+				NewTFOperation(nil, acct, resource.Stack{Type: "Terraform", Path: "./tf/prod"}, Deploy),
+			},
+		},
+		{
+			description: "organization operation missing",
+			stackOps:    stackOps,
+		},
+		{
+			description: "organization unit renamed",
+			orgOps: []ResourceOperation{
+				NewOrganizationUnitOperation(awsorgs.Client{}, nil, &resource.OrganizationUnit{OUName: "Development"}, acct, Create, nil, nil, nil, nil),
+			},
+			stackOps: stackOps,
+		},
+	}
+
+	for _, tc := range tests {
+		assert.Error(t, read.Match(tc.orgOps, tc.stackOps, nil), tc.description)
+	}
+}
@@ -0,0 +1,465 @@
+package resourceoperation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/service/organizations"
+	"github.com/fatih/color"
+	"github.com/samsarahq/go/oops"
+	"github.com/santiago-labs/telophasecli/cmd/runner"
+	"github.com/santiago-labs/telophasecli/lib/awsorgs"
+	"github.com/santiago-labs/telophasecli/lib/awssess"
+	"github.com/santiago-labs/telophasecli/resource"
+)
+
+// managedPolicyTag marks the Service Control Policies telophase manages so that
+// they are detached once they are removed from organization.yml.
+const managedPolicyTag = "TelophaseManaged=true"
+
+// policyTarget is an OU or account a policy is attached to.
+type policyTarget struct {
+	ID   string
+	Name string
+	// OU is set if the target is an OU of organization.yml. Moving an OU
+	// recreates it with a new ID, so the policy is attached to the ID the OU
+	// has when the operation is called.
+	OU *resource.OrganizationUnit
+}
+
+// currentID returns the ID of the target when the operation is called.
+func (t policyTarget) currentID() string {
+	if t.OU != nil && t.OU.OUID != nil {
+		return *t.OU.OUID
+	}
+	return t.ID
+}
+
+func (t policyTarget) String() string {
+	return fmt.Sprintf("%s (%s)", t.Name, t.ID)
+}
+
+// desiredPolicy is the document of a Policy stack of organization.yml and the
+// OUs and accounts that list it.
+type desiredPolicy struct {
+	Document string
+	Targets  []policyTarget
+}
+
+// policyOperation creates or updates a Service Control Policy and attaches it
+// to exactly the OUs and accounts that list it in organization.yml.
+type policyOperation struct {
+	OrgClient           awsorgs.Client
+	ConsoleUI           runner.ConsoleUI
+	MgmtAcct            *resource.Account
+	Operation           int
+	DependentOperations []ResourceOperation
+
+	Name string
+	// PolicyID is empty if the policy is created.
+	PolicyID string
+	// Document is the document in organization.yml. It is empty if the
+	// policy was removed from organization.yml and is only detached.
+	Document string
+	// CurrentDocument is the document in AWS. It is empty if the policy is
+	// created.
+	CurrentDocument string
+	// Managed is whether the policy has the managedPolicyTag.
+	Managed bool
+	Attach  []policyTarget
+	Detach  []policyTarget
+}
+
+// addDesiredPolicy adds the Policy stack of target to policies. names keeps the
+// order in which the policies are first listed.
+func addDesiredPolicy(policies map[string]*desiredPolicy, names *[]string, stack resource.Stack, target policyTarget) error {
+	document, err := stack.PolicyDocument()
+	if err != nil {
+		return err
+	}
+
+	policy, ok := policies[stack.Name]
+	if !ok {
+		policy = &desiredPolicy{Document: document}
+		policies[stack.Name] = policy
+		*names = append(*names, stack.Name)
+	} else if policy.Document != document {
+		return oops.Errorf("Service Control Policy %s has different documents, a policy with the same Name must have the same document everywhere it is listed", stack.Name)
+	}
+	policy.Targets = append(policy.Targets, target)
+	return nil
+}
+
+// collectPolicyOps compares the Policy stacks of organization.yml with the
+// Service Control Policies in AWS. Policies without changes have no
+// operation. known are the OUs and accounts of organization.yml by ID, so that
+// policies attached to them in AWS follow them when they move.
+func collectPolicyOps(
+	ctx context.Context,
+	orgClient awsorgs.Client,
+	consoleUI runner.ConsoleUI,
+	operation int,
+	mgmtAcct *resource.Account,
+	policies map[string]*desiredPolicy,
+	names []string,
+	known map[string]policyTarget,
+) ([]ResourceOperation, error) {
+	existing, err := orgClient.ListAllServiceControlPolicies(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var ops []ResourceOperation
+	found := map[string]bool{}
+	for _, name := range names {
+		desired := policies[name]
+		op := &policyOperation{
+			OrgClient: orgClient,
+			ConsoleUI: consoleUI,
+			MgmtAcct:  mgmtAcct,
+			Operation: operation,
+			Name:      name,
+			Document:  desired.Document,
+			Attach:    desired.Targets,
+		}
+
+		for _, summary := range existing {
+			if aws.StringValue(summary.Name) != name || aws.BoolValue(summary.AwsManaged) {
+				continue
+			}
+			found[name] = true
+			current, err := op.loadCurrent(ctx, summary, known)
+			if err != nil {
+				return nil, err
+			}
+			op.Attach, op.Detach = diffTargets(current, desired.Targets)
+			// A policy telophase didn't create may be attached on purpose
+			// outside of organization.yml, so adopting it only attaches it.
+			if !op.Managed {
+				op.Detach = nil
+			}
+			break
+		}
+
+		if op.hasChanges() {
+			ops = append(ops, op)
+		}
+	}
+
+	// Policies that telophase created and that were removed from
+	// organization.yml are detached. They are not deleted so that they can be
+	// restored.
+	for _, summary := range existing {
+		if found[aws.StringValue(summary.Name)] || aws.BoolValue(summary.AwsManaged) {
+			continue
+		}
+		tags, err := orgClient.GetTags(ctx, aws.StringValue(summary.Id))
+		if err != nil {
+			return nil, err
+		}
+		if !contains(tags, managedPolicyTag) {
+			continue
+		}
+
+		op := &policyOperation{
+			OrgClient: orgClient,
+			ConsoleUI: consoleUI,
+			MgmtAcct:  mgmtAcct,
+			Operation: operation,
+			Name:      aws.StringValue(summary.Name),
+		}
+		current, err := op.loadCurrent(ctx, summary, known)
+		if err != nil {
+			return nil, err
+		}
+		op.Detach = current
+		if op.hasChanges() {
+			ops = append(ops, op)
+		}
+	}
+
+	return ops, nil
+}
+
+// loadCurrent sets the current document and tags of the policy in AWS and
+// returns the targets it is attached to.
+func (po *policyOperation) loadCurrent(ctx context.Context, summary *organizations.PolicySummary, known map[string]policyTarget) ([]policyTarget, error) {
+	po.PolicyID = aws.StringValue(summary.Id)
+
+	policy, err := po.OrgClient.DescribePolicy(ctx, po.PolicyID)
+	if err != nil {
+		return nil, err
+	}
+	po.CurrentDocument = aws.StringValue(policy.Content)
+
+	tags, err := po.OrgClient.GetTags(ctx, po.PolicyID)
+	if err != nil {
+		return nil, err
+	}
+	po.Managed = contains(tags, managedPolicyTag)
+
+	targets, err := po.OrgClient.ListTargetsForPolicy(ctx, po.PolicyID)
+	if err != nil {
+		return nil, err
+	}
+	var current []policyTarget
+	for _, target := range targets {
+		if knownTarget, ok := known[aws.StringValue(target.TargetId)]; ok {
+			current = append(current, knownTarget)
+			continue
+		}
+		current = append(current, policyTarget{
+			ID:   aws.StringValue(target.TargetId),
+			Name: aws.StringValue(target.Name),
+		})
+	}
+	return current, nil
+}
+
+// diffTargets returns the targets in desired that are not in current and the
+// targets in current that are not in desired.
+func diffTargets(current, desired []policyTarget) (attach, detach []policyTarget) {
+	for _, target := range desired {
+		if !hasTarget(current, target.ID) {
+			attach = append(attach, target)
+		}
+	}
+	for _, target := range current {
+		if !hasTarget(desired, target.ID) {
+			detach = append(detach, target)
+		}
+	}
+	return attach, detach
+}
+
+func hasTarget(targets []policyTarget, id string) bool {
+	for _, target := range targets {
+		if target.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (po *policyOperation) created() bool {
+	return po.PolicyID == ""
+}
+
+func (po *policyOperation) documentChanged() bool {
+	if po.Document == "" {
+		return false
+	}
+	return compactJSON(po.CurrentDocument) != po.Document
+}
+
+func (po *policyOperation) hasChanges() bool {
+	return po.created() || po.documentChanged() || len(po.Attach) > 0 || len(po.Detach) > 0
+}
+
+func (po *policyOperation) AddDependent(op ResourceOperation) {
+	po.DependentOperations = append(po.DependentOperations, op)
+}
+
+func (po *policyOperation) ListDependents() []ResourceOperation {
+	return po.DependentOperations
+}
+
+func (po *policyOperation) Call(ctx context.Context) error {
+	po.ConsoleUI.Print(po.ToString(), *po.MgmtAcct)
+	if po.Operation != Deploy {
+		return nil
+	}
+
+	if po.created() {
+		id, err := po.OrgClient.CreateServiceControlPolicy(ctx, po.Name, po.Document, []string{managedPolicyTag})
+		if err != nil {
+			return err
+		}
+		po.PolicyID = id
+		po.ConsoleUI.Print(fmt.Sprintf("Created Service Control Policy %s (%s)", po.Name, po.PolicyID), *po.MgmtAcct)
+	} else {
+		if po.documentChanged() {
+			if err := po.OrgClient.UpdatePolicyContent(ctx, po.PolicyID, po.Document); err != nil {
+				return err
+			}
+			po.ConsoleUI.Print(fmt.Sprintf("Updated Service Control Policy %s", po.Name), *po.MgmtAcct)
+		}
+		if !po.Managed {
+			if err := po.OrgClient.TagResource(ctx, po.PolicyID, []string{managedPolicyTag}); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Attaching first keeps at least one Service Control Policy attached to
+	// every target as AWS requires.
+	for _, target := range po.Attach {
+		if err := po.OrgClient.AttachPolicy(ctx, po.PolicyID, target.currentID()); err != nil {
+			return err
+		}
+		po.ConsoleUI.Print(fmt.Sprintf("Attached Service Control Policy %s to %s", po.Name, target), *po.MgmtAcct)
+	}
+	for _, target := range po.Detach {
+		if err := po.OrgClient.DetachPolicy(ctx, po.PolicyID, target.currentID()); err != nil {
+			return err
+		}
+		po.ConsoleUI.Print(fmt.Sprintf("Detached Service Control Policy %s from %s", po.Name, target), *po.MgmtAcct)
+	}
+
+	for _, op := range po.DependentOperations {
+		if err := op.Call(ctx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (po *policyOperation) Describe() Description {
+	desc := Description{
+		Operation:    OperationName(Update),
+		ResourceType: "Service Control Policy",
+		ResourceID:   po.PolicyID,
+		ResourceName: po.Name,
+	}
+	if po.created() {
+		desc.Operation = OperationName(Create)
+	}
+	if po.created() || po.documentChanged() {
+		desc.PolicyDocument = po.Document
+	}
+	for _, target := range po.Attach {
+		desc.TargetsAttached = append(desc.TargetsAttached, target.String())
+	}
+	for _, target := range po.Detach {
+		desc.TargetsDetached = append(desc.TargetsDetached, target.String())
+	}
+	return desc
+}
+
+func (po *policyOperation) ToString() string {
+	var b strings.Builder
+	printColor := color.YellowString
+	if po.created() {
+		printColor = color.GreenString
+		fmt.Fprintf(&b, "\n(Create Service Control Policy)\n+\tName: %s\n", po.Name)
+	} else {
+		fmt.Fprintf(&b, "\n(Update Service Control Policy)\nID: %s\nName: %s\n", po.PolicyID, po.Name)
+	}
+
+	if po.created() || po.documentChanged() {
+		b.WriteString("Document:\n")
+		for _, line := range documentDiff(po.CurrentDocument, po.Document) {
+			fmt.Fprintf(&b, "%s\n", line)
+		}
+	}
+	if po.Document == "" {
+		b.WriteString("Removed from organization.yml, the policy is detached but not deleted.\n")
+	}
+	if !po.created() && !po.Managed {
+		fmt.Fprintf(&b, "Not created by telophase, the policy is tagged with %s and only attached. Later deploys detach it from targets that don't list it.\n", managedPolicyTag)
+	}
+	for _, target := range po.Attach {
+		fmt.Fprintf(&b, "+\tAttach: %s\n", target)
+	}
+	for _, target := range po.Detach {
+		fmt.Fprintf(&b, "-\tDetach: %s\n", target)
+	}
+	return printColor(b.String())
+}
+
+// scpAdminClient returns an Organizations client with the credentials that
+// Service Control Policies are managed with. Like the Terraform stacks of
+// ServiceControlPolicies, the role of scpAdmin is only assumed if it has an
+// AssumeRoleName.
+func scpAdminClient(orgClient awsorgs.Client, consoleUI runner.ConsoleUI, scpAdmin *resource.Account) (awsorgs.Client, error) {
+	if scpAdmin.AssumeRoleName == "" {
+		return orgClient, nil
+	}
+
+	creds, _, err := AuthAWS(*scpAdmin, scpAdmin.AssumeRoleARN(), consoleUI)
+	if err != nil {
+		return awsorgs.Client{}, err
+	}
+	if creds == nil {
+		return orgClient, nil
+	}
+
+	sess, err := awssess.DefaultSession(aws.NewConfig().WithCredentials(credentials.NewStaticCredentials(
+		aws.StringValue(creds.AccessKeyId),
+		aws.StringValue(creds.SecretAccessKey),
+		aws.StringValue(creds.SessionToken),
+	)))
+	if err != nil {
+		return awsorgs.Client{}, err
+	}
+	return awsorgs.New(&awsorgs.Config{OrganizationClient: organizations.New(sess)}), nil
+}
+
+// compactJSON returns document without whitespace, or document itself if it
+// isn't valid JSON.
+func compactJSON(document string) string {
+	var b bytes.Buffer
+	if err := json.Compact(&b, []byte(document)); err != nil {
+		return document
+	}
+	return b.String()
+}
+
+// documentLines returns the lines of document indented for reading.
+func documentLines(document string) []string {
+	if document == "" {
+		return nil
+	}
+	var b bytes.Buffer
+	if err := json.Indent(&b, []byte(document), "", "  "); err != nil {
+		return strings.Split(document, "\n")
+	}
+	return strings.Split(b.String(), "\n")
+}
+
+// documentDiff returns the lines of the JSON document to prefixed with "+"
+// if they are not in from, and the lines of from that are not in to prefixed
+// with "-". Unchanged lines are prefixed with a space.
+func documentDiff(from, to string) []string {
+	a, b := documentLines(from), documentLines(to)
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and
+	// b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var lines []string
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, " \t"+a[i])
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, "-\t"+a[i])
+			i++
+		default:
+			lines = append(lines, "+\t"+b[j])
+			j++
+		}
+	}
+	return lines
+}
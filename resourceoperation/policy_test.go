@@ -0,0 +1,270 @@
+package resourceoperation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/organizations"
+	"github.com/aws/aws-sdk-go/service/organizations/organizationsiface"
+	"github.com/santiago-labs/telophasecli/cmd/runner"
+	"github.com/santiago-labs/telophasecli/lib/awsorgs"
+	"github.com/santiago-labs/telophasecli/lib/awsorgs/awsorgsmock"
+	"github.com/santiago-labs/telophasecli/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakePolicy struct {
+	summary organizations.PolicySummary
+	content string
+	tags    []string
+	targets []string
+}
+
+// policyOrganizations serves Service Control Policies from policies.
+type policyOrganizations struct {
+	organizationsiface.OrganizationsAPI
+
+	policies []fakePolicy
+	// calls records the attachments and detachments.
+	calls []string
+}
+
+func (m *policyOrganizations) find(id string) fakePolicy {
+	for _, policy := range m.policies {
+		if aws.StringValue(policy.summary.Id) == id {
+			return policy
+		}
+	}
+	return fakePolicy{}
+}
+
+func (m *policyOrganizations) ListPoliciesPagesWithContext(ctx aws.Context, input *organizations.ListPoliciesInput, fn func(*organizations.ListPoliciesOutput, bool) bool, opts ...request.Option) error {
+	var summaries []*organizations.PolicySummary
+	for _, policy := range m.policies {
+		summary := policy.summary
+		summaries = append(summaries, &summary)
+	}
+	fn(&organizations.ListPoliciesOutput{Policies: summaries}, true)
+	return nil
+}
+
+func (m *policyOrganizations) DescribePolicyWithContext(ctx aws.Context, input *organizations.DescribePolicyInput, opts ...request.Option) (*organizations.DescribePolicyOutput, error) {
+	policy := m.find(aws.StringValue(input.PolicyId))
+	return &organizations.DescribePolicyOutput{
+		Policy: &organizations.Policy{PolicySummary: &policy.summary, Content: aws.String(policy.content)},
+	}, nil
+}
+
+func (m *policyOrganizations) ListTagsForResourcePagesWithContext(ctx aws.Context, input *organizations.ListTagsForResourceInput, fn func(*organizations.ListTagsForResourceOutput, bool) bool, opts ...request.Option) error {
+	var tags []*organizations.Tag
+	for _, tag := range m.find(aws.StringValue(input.ResourceId)).tags {
+		tags = append(tags, &organizations.Tag{Key: aws.String("TelophaseManaged"), Value: aws.String(tag)})
+	}
+	fn(&organizations.ListTagsForResourceOutput{Tags: tags}, true)
+	return nil
+}
+
+func (m *policyOrganizations) ListTargetsForPolicyPagesWithContext(ctx aws.Context, input *organizations.ListTargetsForPolicyInput, fn func(*organizations.ListTargetsForPolicyOutput, bool) bool, opts ...request.Option) error {
+	var targets []*organizations.PolicyTargetSummary
+	for _, id := range m.find(aws.StringValue(input.PolicyId)).targets {
+		targets = append(targets, &organizations.PolicyTargetSummary{TargetId: aws.String(id), Name: aws.String(id)})
+	}
+	fn(&organizations.ListTargetsForPolicyOutput{Targets: targets}, true)
+	return nil
+}
+
+func (m *policyOrganizations) AttachPolicyWithContext(ctx aws.Context, input *organizations.AttachPolicyInput, opts ...request.Option) (*organizations.AttachPolicyOutput, error) {
+	m.calls = append(m.calls, "attach "+aws.StringValue(input.PolicyId)+" "+aws.StringValue(input.TargetId))
+	return &organizations.AttachPolicyOutput{}, nil
+}
+
+func (m *policyOrganizations) DetachPolicyWithContext(ctx aws.Context, input *organizations.DetachPolicyInput, opts ...request.Option) (*organizations.DetachPolicyOutput, error) {
+	m.calls = append(m.calls, "detach "+aws.StringValue(input.PolicyId)+" "+aws.StringValue(input.TargetId))
+	return &organizations.DetachPolicyOutput{}, nil
+}
+
+func TestCollectPolicyOps(t *testing.T) {
+	orgs := &policyOrganizations{
+		OrganizationsAPI: awsorgsmock.New(),
+		policies: []fakePolicy{
+			{
+				summary: organizations.PolicySummary{Id: aws.String("p-FullAWSAccess"), Name: aws.String("FullAWSAccess"), AwsManaged: aws.Bool(true)},
+				content: `{"Version":"2012-10-17"}`,
+				targets: []string{"r-0000"},
+			},
+			{
+				summary: organizations.PolicySummary{Id: aws.String("p-regions"), Name: aws.String("deny-regions")},
+				content: `{"Version":"2012-10-17","Statement":[]}`,
+				tags:    []string{"true"},
+				targets: []string{"ou-a", "ou-b"},
+			},
+			{
+				summary: organizations.PolicySummary{Id: aws.String("p-unchanged"), Name: aws.String("unchanged")},
+				content: "{\n  \"Version\": \"2012-10-17\"\n}",
+				tags:    []string{"true"},
+				targets: []string{"ou-a"},
+			},
+			{
+				summary: organizations.PolicySummary{Id: aws.String("p-removed"), Name: aws.String("removed")},
+				content: `{"Version":"2012-10-17"}`,
+				tags:    []string{"true"},
+				targets: []string{"111111111111"},
+			},
+			{
+				summary: organizations.PolicySummary{Id: aws.String("p-unmanaged"), Name: aws.String("unmanaged")},
+				content: `{"Version":"2012-10-17"}`,
+				targets: []string{"111111111111"},
+			},
+		},
+	}
+	orgClient := awsorgs.New(&awsorgs.Config{OrganizationClient: orgs})
+
+	policies := map[string]*desiredPolicy{}
+	var names []string
+	for _, policy := range []struct {
+		stack  resource.Stack
+		target policyTarget
+	}{
+		{resource.Stack{Type: "Policy", Name: "deny-regions", Document: `{"Version": "2012-10-17", "Statement": [{"Effect": "Deny"}]}`}, policyTarget{ID: "ou-a", Name: "ou-a"}},
+		{resource.Stack{Type: "Policy", Name: "deny-regions", Document: `{"Version":"2012-10-17","Statement":[{"Effect":"Deny"}]}`}, policyTarget{ID: "ou-c", Name: "ou-c"}},
+		{resource.Stack{Type: "Policy", Name: "unchanged", Document: `{"Version":"2012-10-17"}`}, policyTarget{ID: "ou-a", Name: "ou-a"}},
+		{resource.Stack{Type: "Policy", Name: "new", Document: `{"Version":"2012-10-17"}`}, policyTarget{ID: "ou-b", Name: "ou-b"}},
+		{resource.Stack{Type: "Policy", Name: "unmanaged", Document: `{"Version":"2012-10-17"}`}, policyTarget{ID: "ou-a", Name: "ou-a"}},
+	} {
+		require.NoError(t, addDesiredPolicy(policies, &names, policy.stack, policy.target))
+	}
+
+	ops, err := collectPolicyOps(context.Background(), orgClient, runner.NewSTDErr(), Diff, &resource.Account{}, policies, names, nil)
+	require.NoError(t, err)
+
+	var descs []Description
+	for _, op := range ops {
+		descs = append(descs, op.Describe())
+	}
+	assert.Equal(t, []Description{
+		{
+			Operation:       "Update",
+			ResourceType:    "Service Control Policy",
+			ResourceID:      "p-regions",
+			ResourceName:    "deny-regions",
+			PolicyDocument:  `{"Version":"2012-10-17","Statement":[{"Effect":"Deny"}]}`,
+			TargetsAttached: []string{"ou-c (ou-c)"},
+			TargetsDetached: []string{"ou-b (ou-b)"},
+		},
+		{
+			Operation:       "Create",
+			ResourceType:    "Service Control Policy",
+			ResourceName:    "new",
+			PolicyDocument:  `{"Version":"2012-10-17"}`,
+			TargetsAttached: []string{"ou-b (ou-b)"},
+		},
+		{
+			// Adopted policies are not detached from targets outside of
+			// organization.yml.
+			Operation:       "Update",
+			ResourceType:    "Service Control Policy",
+			ResourceID:      "p-unmanaged",
+			ResourceName:    "unmanaged",
+			TargetsAttached: []string{"ou-a (ou-a)"},
+		},
+		{
+			Operation:       "Update",
+			ResourceType:    "Service Control Policy",
+			ResourceID:      "p-removed",
+			ResourceName:    "removed",
+			TargetsDetached: []string{"111111111111 (111111111111)"},
+		},
+	}, descs)
+}
+
+func TestPolicyOperationMovedOU(t *testing.T) {
+	orgs := &policyOrganizations{
+		OrganizationsAPI: awsorgsmock.New(),
+		policies: []fakePolicy{
+			{
+				summary: organizations.PolicySummary{Id: aws.String("p-regions"), Name: aws.String("deny-regions")},
+				content: `{"Version":"2012-10-17"}`,
+				tags:    []string{"true"},
+				targets: []string{"ou-a", "ou-b"},
+			},
+		},
+	}
+	orgClient := awsorgs.New(&awsorgs.Config{OrganizationClient: orgs})
+
+	ouA := &resource.OrganizationUnit{OUName: "a", OUID: aws.String("ou-a")}
+	ouB := &resource.OrganizationUnit{OUName: "b", OUID: aws.String("ou-b")}
+	ouC := &resource.OrganizationUnit{OUName: "c", OUID: aws.String("ou-c")}
+	known := map[string]policyTarget{}
+	for _, ou := range []*resource.OrganizationUnit{ouA, ouB, ouC} {
+		known[*ou.OUID] = policyTarget{ID: *ou.OUID, Name: ou.OUName, OU: ou}
+	}
+
+	policies := map[string]*desiredPolicy{}
+	var names []string
+	stack := resource.Stack{Type: "Policy", Name: "deny-regions", Document: `{"Version":"2012-10-17"}`}
+	require.NoError(t, addDesiredPolicy(policies, &names, stack, known["ou-a"]))
+	require.NoError(t, addDesiredPolicy(policies, &names, stack, known["ou-c"]))
+
+	// A saved plan collects the operations before the OUs are moved, which
+	// recreates them with new IDs.
+	ops, err := collectPolicyOps(context.Background(), orgClient, runner.NewSTDErr(), Deploy, &resource.Account{}, policies, names, known)
+	require.NoError(t, err)
+	require.Len(t, ops, 1)
+	ouB.OUID = aws.String("ou-b2")
+	ouC.OUID = aws.String("ou-c2")
+
+	require.NoError(t, ops[0].Call(context.Background()))
+	assert.Equal(t, []string{"attach p-regions ou-c2", "detach p-regions ou-b2"}, orgs.calls)
+}
+
+func TestAddDesiredPolicyDifferentDocuments(t *testing.T) {
+	policies := map[string]*desiredPolicy{}
+	var names []string
+	require.NoError(t, addDesiredPolicy(policies, &names, resource.Stack{Type: "Policy", Name: "deny", Document: `{"a":1}`}, policyTarget{ID: "ou-a"}))
+	assert.Error(t, addDesiredPolicy(policies, &names, resource.Stack{Type: "Policy", Name: "deny", Document: `{"a":2}`}, policyTarget{ID: "ou-b"}))
+}
+
+func TestDocumentDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		from string
+		to   string
+		want []string
+	}{
+		{
+			name: "new document",
+			to:   `{"Version":"2012-10-17"}`,
+			want: []string{
+				"+\t{",
+				"+\t  \"Version\": \"2012-10-17\"",
+				"+\t}",
+			},
+		},
+		{
+			name: "changed statement",
+			from: `{"Version":"2012-10-17","Statement":[{"Effect":"Deny","Action":"iam:*"}]}`,
+			to:   `{"Version":"2012-10-17","Statement":[{"Effect":"Deny","Action":"s3:*"}]}`,
+			want: []string{
+				" \t{",
+				" \t  \"Version\": \"2012-10-17\",",
+				" \t  \"Statement\": [",
+				" \t    {",
+				" \t      \"Effect\": \"Deny\",",
+				"-\t      \"Action\": \"iam:*\"",
+				"+\t      \"Action\": \"s3:*\"",
+				" \t    }",
+				" \t  ]",
+				" \t}",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, documentDiff(tc.from, tc.to))
+		})
+	}
+}
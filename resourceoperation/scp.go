@@ -12,6 +12,7 @@ import (
 	"strings"
 
 	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/samsarahq/go/oops"
 	"github.com/santiago-labs/telophasecli/cmd/runner"
 	"github.com/santiago-labs/telophasecli/lib/awsorgs"
 	"github.com/santiago-labs/telophasecli/lib/awssts"
@@ -20,6 +21,9 @@ import (
 	"github.com/santiago-labs/telophasecli/resource"
 )
 
+// CollectSCPOps returns the operations for the ServiceControlPolicies of
+// organization.yml. Terraform stacks have an operation per OU or account,
+// while Policy stacks have an operation per policy that changes.
 func CollectSCPOps(
 	ctx context.Context,
 	orgClient awsorgs.Client,
@@ -27,15 +31,26 @@ func CollectSCPOps(
 	operation int,
 	rootOU *resource.OrganizationUnit,
 	mgmtAcct *resource.Account,
-) []ResourceOperation {
+) ([]ResourceOperation, error) {
 
 	var ops []ResourceOperation
+	policies := map[string]*desiredPolicy{}
+	var policyNames []string
+	knownTargets := map[string]policyTarget{}
 	for _, ou := range rootOU.AllDescendentOUs() {
 		if ou.OUID == nil {
 			consoleUI.Print(fmt.Sprintf("Skipping OU because it is not yet created: %s", ou.OUName), *mgmtAcct)
 			continue
 		}
+		target := policyTarget{ID: *ou.OUID, Name: ou.OUName, OU: ou}
+		knownTargets[target.ID] = target
 		for _, scp := range ou.ServiceControlPolicies {
+			if scp.Type == "Policy" {
+				if err := addDesiredPolicy(policies, &policyNames, scp, target); err != nil {
+					return nil, err
+				}
+				continue
+			}
 			ops = append(ops, NewSCPOperation(
 				consoleUI,
 				nil,
@@ -52,7 +67,15 @@ func CollectSCPOps(
 			consoleUI.Print(fmt.Sprintf("Skipping Account because it is not yet created: %s", acct.AccountName), *mgmtAcct)
 			continue
 		}
+		target := policyTarget{ID: acct.AccountID, Name: acct.AccountName}
+		knownTargets[target.ID] = target
 		for _, scp := range acct.ServiceControlPolicies {
+			if scp.Type == "Policy" {
+				if err := addDesiredPolicy(policies, &policyNames, scp, target); err != nil {
+					return nil, err
+				}
+				continue
+			}
 			ops = append(ops, NewSCPOperation(
 				consoleUI,
 				acct,
@@ -64,7 +87,18 @@ func CollectSCPOps(
 		}
 	}
 
-	return ops
+	// Policies are collected even if organization.yml has none so that
+	// removed policies are detached.
+	policyClient, err := scpAdminClient(orgClient, consoleUI, mgmtAcct)
+	if err != nil {
+		return nil, err
+	}
+	policyOps, err := collectPolicyOps(ctx, policyClient, consoleUI, operation, mgmtAcct, policies, policyNames, knownTargets)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(ops, policyOps...), nil
 }
 
 type scpOperation struct {
@@ -75,6 +109,9 @@ type scpOperation struct {
 	Stack               resource.Stack
 	OutputUI            runner.ConsoleUI
 	DependentOperations []ResourceOperation
+
+	PlanDir   string
+	Artifacts *PlanArtifacts
 }
 
 func NewSCPOperation(
@@ -108,13 +145,13 @@ func (so *scpOperation) Call(ctx context.Context) error {
 	var creds *sts.Credentials
 	if so.MgmtAcct.AssumeRoleName != "" {
 		var err error
-		creds, _, err = authAWS(*so.MgmtAcct, so.MgmtAcct.AssumeRoleARN(), so.OutputUI)
+		creds, _, err = AuthAWS(*so.MgmtAcct, so.MgmtAcct.AssumeRoleARN(), so.OutputUI)
 		if err != nil {
 			return err
 		}
 	}
 
-	initTFCmd, err := so.initTf()
+	initTFCmd, err := so.initTf(ctx)
 	if err != nil {
 		so.OutputUI.Print(fmt.Sprintf("Error initializing terraform: %s", err), *so.MgmtAcct)
 		return err
@@ -136,14 +173,28 @@ func (so *scpOperation) Call(ctx context.Context) error {
 		args = []string{
 			"plan",
 		}
+		if so.PlanDir != "" {
+			if err := os.MkdirAll(so.PlanDir, 0755); err != nil {
+				return oops.Wrapf(err, "creating plan directory %s", so.PlanDir)
+			}
+			planFile := filepath.Join(so.PlanDir, planArtifactName("scp", so.targetResource(), so.Stack)+".tfplan")
+			args = append(args, "-out="+planFile)
+			so.Artifacts = &PlanArtifacts{TerraformPlanFile: planFile}
+		}
 	} else if so.Operation == Deploy {
 		args = []string{
 			"apply", "-auto-approve",
 		}
+		if so.Artifacts != nil {
+			if so.Artifacts.TerraformPlanFile == "" {
+				return oops.Errorf("plan does not include a Terraform plan file for stack %s", so.Stack.Path)
+			}
+			args = []string{"apply", so.Artifacts.TerraformPlanFile}
+		}
 	}
 
 	workingPath := so.tmpPath()
-	cmd := exec.Command(localstack.TfCmd(), args...)
+	cmd := runner.Command(ctx, localstack.TfCmd(), args...)
 	cmd.Dir = workingPath
 	cmd.Env = awssts.SetEnvironCreds(os.Environ(),
 		creds,
@@ -164,7 +215,7 @@ func (so *scpOperation) Call(ctx context.Context) error {
 	return nil
 }
 
-func (so *scpOperation) initTf() (*exec.Cmd, error) {
+func (so *scpOperation) initTf(ctx context.Context) (*exec.Cmd, error) {
 	workingPath := so.tmpPath()
 	terraformDir := filepath.Join(workingPath, ".terraform")
 	if terraformDir == "" || !strings.Contains(terraformDir, "telophasedirs") {
@@ -181,11 +232,11 @@ func (so *scpOperation) initTf() (*exec.Cmd, error) {
 			return nil, fmt.Errorf("failed to create directory %s: %v", terraformDir, err)
 		}
 
-		if err := terraform.CopyDir(so.Stack, workingPath, so.targetResource()); err != nil {
+		if err := terraform.CopyDir(so.Stack, workingPath, so.targetResource(), nil); err != nil {
 			return nil, fmt.Errorf("failed to copy files from %s to %s: %v", so.Stack.Path, workingPath, err)
 		}
 
-		cmd := exec.Command(localstack.TfCmd(), "init")
+		cmd := runner.Command(ctx, localstack.TfCmd(), "init")
 		cmd.Dir = workingPath
 
 		return cmd, nil
@@ -210,6 +261,29 @@ func (so *scpOperation) tmpPath() string {
 	return path.Join("telophasedirs", fmt.Sprintf("tf-tmp-%s-%s-%s", so.MgmtAcct.ID(), so.targetResource().ID(), hashString))
 }
 
+func (so *scpOperation) setPlanDir(dir string) {
+	so.PlanDir = dir
+}
+
+func (so *scpOperation) planArtifacts() *PlanArtifacts {
+	return so.Artifacts
+}
+
+func (so *scpOperation) setPlanArtifacts(artifacts *PlanArtifacts) {
+	so.Artifacts = artifacts
+}
+
+func (so *scpOperation) Describe() Description {
+	stack := so.Stack
+	return Description{
+		Operation:    OperationName(so.Operation),
+		ResourceType: "Service Control Policy",
+		ResourceID:   so.targetResource().ID(),
+		ResourceName: so.targetResource().Name(),
+		Stack:        &stack,
+	}
+}
+
 func (so *scpOperation) ToString() string {
 	return ""
 }
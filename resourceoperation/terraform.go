@@ -13,6 +13,7 @@ import (
 	"github.com/santiago-labs/telophasecli/cmd/runner"
 	"github.com/santiago-labs/telophasecli/lib/awssts"
 	"github.com/santiago-labs/telophasecli/lib/localstack"
+	"github.com/santiago-labs/telophasecli/lib/outputs"
 	"github.com/santiago-labs/telophasecli/lib/terraform"
 	"github.com/santiago-labs/telophasecli/resource"
 )
@@ -23,6 +24,15 @@ type tfOperation struct {
 	Stack               resource.Stack
 	OutputUI            runner.ConsoleUI
 	DependentOperations []ResourceOperation
+
+	// PlanDir is set when the diff is saved to a plan. Artifacts are set after
+	// the diff runs, or before a deploy that replays a plan.
+	PlanDir   string
+	Artifacts *PlanArtifacts
+
+	// Outputs resolves output references in the stack's files and records
+	// the stack's outputs after it runs.
+	Outputs *outputs.Store
 }
 
 func NewTFOperation(consoleUI runner.ConsoleUI, acct *resource.Account, stack resource.Stack, op int) ResourceOperation {
@@ -49,9 +59,9 @@ func (to *tfOperation) Call(ctx context.Context) error {
 	var assumeRoleErr error
 	if to.Account.AccountID != "" {
 		if roleArn := to.Stack.RoleARN(*to.Account); roleArn != nil {
-			creds, _, assumeRoleErr = authAWS(*to.Account, *roleArn, to.OutputUI)
+			creds, _, assumeRoleErr = AuthAWS(*to.Account, *roleArn, to.OutputUI)
 		} else {
-			creds, _, assumeRoleErr = authAWS(*to.Account, to.Account.AssumeRoleARN(), to.OutputUI)
+			creds, _, assumeRoleErr = AuthAWS(*to.Account, to.Account.AssumeRoleARN(), to.OutputUI)
 		}
 
 		if assumeRoleErr != nil {
@@ -59,7 +69,10 @@ func (to *tfOperation) Call(ctx context.Context) error {
 		}
 	}
 
-	initTFCmd := to.initTf(creds)
+	initTFCmd, err := to.initTf(ctx, creds)
+	if err != nil {
+		return err
+	}
 	if initTFCmd != nil {
 		if err := to.OutputUI.RunCmd(initTFCmd, *to.Account); err != nil {
 			return err
@@ -67,7 +80,7 @@ func (to *tfOperation) Call(ctx context.Context) error {
 	}
 
 	// Set workspace if we are using it.
-	setWorkspace, err := to.setWorkspace(creds)
+	setWorkspace, err := to.setWorkspace(ctx, creds)
 	if err != nil {
 		return err
 	}
@@ -82,14 +95,37 @@ func (to *tfOperation) Call(ctx context.Context) error {
 		args = []string{
 			"plan",
 		}
+		if to.PlanDir != "" {
+			planFile, err := to.planFile()
+			if err != nil {
+				return err
+			}
+			args = append(args, "-out="+planFile)
+			to.Artifacts = &PlanArtifacts{TerraformPlanFile: planFile}
+		}
 	} else if to.Operation == Deploy {
 		args = []string{
 			"apply", "-auto-approve",
 		}
+		if to.Artifacts != nil {
+			if to.Artifacts.TerraformPlanFile == "" {
+				return oops.Errorf("plan does not include a Terraform plan file for stack %s", to.Stack.Path)
+			}
+			// Saved plans are applied without approval.
+			args = []string{"apply", to.Artifacts.TerraformPlanFile}
+		}
+	} else if to.Operation == DiffDestroy {
+		args = []string{
+			"plan", "-destroy",
+		}
+	} else if to.Operation == Destroy {
+		args = []string{
+			"destroy", "-auto-approve",
+		}
 	}
 
 	workingPath := terraform.TmpPath(*to.Account, to.Stack.Path)
-	cmd := exec.Command(localstack.TfCmd(), args...)
+	cmd := runner.Command(ctx, localstack.TfCmd(), args...)
 	cmd.Dir = workingPath
 
 	cmd.Env = awssts.SetEnvironCreds(os.Environ(),
@@ -101,6 +137,12 @@ func (to *tfOperation) Call(ctx context.Context) error {
 		return err
 	}
 
+	if to.Operation == Diff || to.Operation == Deploy {
+		if err := to.storeOutputs(ctx, creds); err != nil {
+			return err
+		}
+	}
+
 	for _, op := range to.DependentOperations {
 		if err := op.Call(ctx); err != nil {
 			return err
@@ -110,31 +152,30 @@ func (to *tfOperation) Call(ctx context.Context) error {
 	return nil
 }
 
-func (to *tfOperation) initTf(creds *sts.Credentials) *exec.Cmd {
+func (to *tfOperation) initTf(ctx context.Context, creds *sts.Credentials) (*exec.Cmd, error) {
 	workingPath := terraform.TmpPath(*to.Account, to.Stack.Path)
 	terraformDir := filepath.Join(workingPath, ".terraform")
 	if terraformDir == "" || !strings.Contains(terraformDir, "telophasedirs") {
 		to.OutputUI.Print("expected terraform dir to be set", *to.Account)
-		return nil
+		return nil, nil
 	}
 	// Clean the directory
 	if err := os.RemoveAll(terraformDir); err != nil {
 		to.OutputUI.Print(fmt.Sprintf("Error: failed to remove directory %s: %v", terraformDir, err), *to.Account)
-		return nil
+		return nil, nil
 	}
 
 	if _, err := os.Stat(terraformDir); os.IsNotExist(err) {
 		if err := os.MkdirAll(workingPath, 0755); err != nil {
 			to.OutputUI.Print(fmt.Sprintf("Error: failed to create directory %s: %v", terraformDir, err), *to.Account)
-			return nil
+			return nil, nil
 		}
 
-		if err := terraform.CopyDir(to.Stack, workingPath, *to.Account); err != nil {
-			to.OutputUI.Print(fmt.Sprintf("Error: failed to copy files from %s to %s: %v", to.Stack.Path, workingPath, err), *to.Account)
-			return nil
+		if err := terraform.CopyDir(to.Stack, workingPath, *to.Account, to.Outputs); err != nil {
+			return nil, oops.Wrapf(err, "failed to copy files from %s to %s", to.Stack.Path, workingPath)
 		}
 
-		cmd := exec.Command(localstack.TfCmd(), "init")
+		cmd := runner.Command(ctx, localstack.TfCmd(), "init")
 		cmd.Dir = workingPath
 
 		cmd.Env = awssts.SetEnvironCreds(os.Environ(),
@@ -142,10 +183,10 @@ func (to *tfOperation) initTf(creds *sts.Credentials) *exec.Cmd {
 			to.Stack.AWSRegionEnv(),
 		)
 
-		return cmd
+		return cmd, nil
 	}
 
-	return nil
+	return nil, nil
 }
 
 func replaceVals(workspace, AccountID, Region string) (string, error) {
@@ -164,7 +205,7 @@ func replaceVals(workspace, AccountID, Region string) (string, error) {
 	return currentContent, nil
 }
 
-func (to *tfOperation) setWorkspace(creds *sts.Credentials) (*exec.Cmd, error) {
+func (to *tfOperation) setWorkspace(ctx context.Context, creds *sts.Credentials) (*exec.Cmd, error) {
 	if !to.Stack.WorkspaceEnabled() {
 		return nil, nil
 	}
@@ -176,7 +217,7 @@ func (to *tfOperation) setWorkspace(creds *sts.Credentials) (*exec.Cmd, error) {
 		return nil, err
 	}
 
-	cmd := exec.Command(localstack.TfCmd(), "workspace", "select", "-or-create", rewrittenWorkspace)
+	cmd := runner.Command(ctx, localstack.TfCmd(), "workspace", "select", "-or-create", rewrittenWorkspace)
 	cmd.Dir = workingPath
 
 	cmd.Env = awssts.SetEnvironCreds(os.Environ(),
@@ -187,6 +228,58 @@ func (to *tfOperation) setWorkspace(creds *sts.Credentials) (*exec.Cmd, error) {
 	return cmd, nil
 }
 
+// storeOutputs records the outputs of the stack from terraform output.
+func (to *tfOperation) storeOutputs(ctx context.Context, creds *sts.Credentials) error {
+	if to.Outputs == nil || to.Stack.Name == "" {
+		return nil
+	}
+
+	cmd := runner.Command(ctx, localstack.TfCmd(), "output", "-json")
+	cmd.Dir = terraform.TmpPath(*to.Account, to.Stack.Path)
+	cmd.Env = awssts.SetEnvironCreds(os.Environ(),
+		creds,
+		to.Stack.AWSRegionEnv(),
+	)
+	out, err := cmd.Output()
+	if err != nil {
+		return oops.Wrapf(err, "terraform output for stack %s", to.Stack.Path)
+	}
+
+	values, err := terraform.ParseOutputs(out)
+	if err != nil {
+		return oops.Wrapf(err, "terraform output for stack %s", to.Stack.Path)
+	}
+	storeOutputs(to.Outputs, *to.Account, to.Stack, values)
+	return nil
+}
+
+func (to *tfOperation) setOutputs(store *outputs.Store) {
+	to.Outputs = store
+}
+
+func (to *tfOperation) setPlanDir(dir string) {
+	to.PlanDir = dir
+}
+
+func (to *tfOperation) planArtifacts() *PlanArtifacts {
+	return to.Artifacts
+}
+
+func (to *tfOperation) setPlanArtifacts(artifacts *PlanArtifacts) {
+	to.Artifacts = artifacts
+}
+
+func (to *tfOperation) planFile() (string, error) {
+	if err := os.MkdirAll(to.PlanDir, 0755); err != nil {
+		return "", oops.Wrapf(err, "creating plan directory %s", to.PlanDir)
+	}
+	return filepath.Join(to.PlanDir, planArtifactName("tf", to.Account, to.Stack)+".tfplan"), nil
+}
+
+func (to *tfOperation) Describe() Description {
+	return stackDescription(to.Operation, *to.Account, to.Stack)
+}
+
 func (to *tfOperation) ToString() string {
 	return ""
 }
@@ -1055,7 +1055,7 @@ func TestEndToEnd(t *testing.T) {
 
 			ymlparser.NewParser(orgClient).HydrateParsedOrg(ctx, test.OrgInitialState)
 			orgOps := resourceoperation.CollectOrganizationUnitOps(
-				ctx, consoleUI, orgClient, mgmtAcct, test.OrgInitialState, resourceoperation.Deploy, false,
+				ctx, consoleUI, orgClient, mgmtAcct, test.OrgInitialState, resourceoperation.Deploy, false, false,
 			)
 			for _, op := range orgOps {
 				err := op.Call(ctx)
@@ -1079,7 +1079,7 @@ func TestEndToEnd(t *testing.T) {
 
 		compareOrganizationUnits(t, test.ParseExpected, parsedOrg, false)
 
-		cmd.ProcessOrgEndToEnd(consoleUI, resourceoperation.Deploy, test.Targets)
+		cmd.ProcessOrgEndToEnd(context.Background(), consoleUI, resourceoperation.Deploy, test.Targets)
 
 		fetchedOrg, err := orgClient.FetchOUAndDescendents(ctx, rootId, mgmtAcct.AccountID)
 		assert.NoError(t, err, "Failed to fetch rootOU")